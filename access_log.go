@@ -0,0 +1,214 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/temporalio/tchannel-go/relay"
+
+	"go.uber.org/atomic"
+)
+
+// CallOutcome is the outcome of a completed call as recorded in the access log.
+type CallOutcome string
+
+const (
+	// CallSuccess is a call that completed with a successful response.
+	CallSuccess CallOutcome = "success"
+
+	// CallApplicationError is a call that completed with an application error.
+	CallApplicationError CallOutcome = "application-error"
+
+	// CallSystemError is a call that failed with a system error. The error
+	// code (or relay failure reason) is recorded in AccessLogEntry.ErrorCode.
+	CallSystemError CallOutcome = "system-error"
+)
+
+// AccessLogEntry describes a single completed call.
+type AccessLogEntry struct {
+	// Relayed is true if the call was forwarded by the relay, and false if
+	// it was handled by this channel.
+	Relayed bool
+
+	// Caller, Service, Method and RoutingKey are taken from the call request.
+	Caller     string
+	Service    string
+	Method     string
+	RoutingKey string
+
+	// Source is the host:port of the peer that sent the call.
+	Source string
+
+	// Destination is the host:port of the peer the call was relayed to. It is
+	// empty for calls handled by this channel, or if peer selection failed.
+	Destination string
+
+	// RequestBytes and ResponseBytes are the total size of the frames of the
+	// call request and the call response, including frame headers.
+	RequestBytes  uint64
+	ResponseBytes uint64
+
+	// TTL is the time to live of the call request as it was received.
+	TTL time.Duration
+
+	// Start is when the call was received, and Duration is how long it took
+	// until the call completed.
+	Start    time.Time
+	Duration time.Duration
+
+	// Outcome is the outcome of the call.
+	Outcome CallOutcome
+
+	// ErrorCode is the system error code (as returned by SystemErrCode.MetricsKey)
+	// or the relay failure reason for calls that failed with a system error.
+	ErrorCode string
+}
+
+// AccessLogger receives an AccessLogEntry for every call that completes on a
+// channel, both relayed and handled locally. Log is called synchronously on
+// the call path, so implementations must be safe for concurrent use and
+// should not block.
+type AccessLogger interface {
+	Log(entry AccessLogEntry)
+}
+
+// accessLogRelayCall wraps a RelayCall to record the data for an access log
+// entry, which is logged when the call ends.
+type accessLogRelayCall struct {
+	RelayCall
+
+	logger        AccessLogger
+	timeNow       func() time.Time
+	requestBytes  atomic.Uint64
+	responseBytes atomic.Uint64
+
+	mut   sync.Mutex
+	entry AccessLogEntry
+}
+
+func newAccessLogRelayCall(call RelayCall, logger AccessLogger, f relay.CallFrame, source string, timeNow func() time.Time) *accessLogRelayCall {
+	return &accessLogRelayCall{
+		RelayCall: call,
+		logger:    logger,
+		timeNow:   timeNow,
+		entry: AccessLogEntry{
+			Relayed:    true,
+			Caller:     string(f.Caller()),
+			Service:    string(f.Service()),
+			Method:     string(f.Method()),
+			RoutingKey: string(f.RoutingKey()),
+			Source:     source,
+			TTL:        f.TTL(),
+			Start:      timeNow(),
+		},
+	}
+}
+
+func (c *accessLogRelayCall) Destination() (*Peer, bool) {
+	peer, ok := c.RelayCall.Destination()
+	if ok && peer != nil {
+		c.mut.Lock()
+		c.entry.Destination = peer.HostPort()
+		c.mut.Unlock()
+	}
+	return peer, ok
+}
+
+func (c *accessLogRelayCall) SentBytes(size uint16) {
+	c.requestBytes.Add(uint64(size))
+	c.RelayCall.SentBytes(size)
+}
+
+func (c *accessLogRelayCall) ReceivedBytes(size uint16) {
+	c.responseBytes.Add(uint64(size))
+	c.RelayCall.ReceivedBytes(size)
+}
+
+func (c *accessLogRelayCall) Succeeded() {
+	c.setOutcome(CallSuccess, "")
+	c.RelayCall.Succeeded()
+}
+
+func (c *accessLogRelayCall) Failed(reason string) {
+	if reason == "application-error" {
+		c.setOutcome(CallApplicationError, "")
+	} else {
+		c.setOutcome(CallSystemError, reason)
+	}
+	c.RelayCall.Failed(reason)
+}
+
+// setOutcome records the outcome of the call. The first outcome wins, as a
+// call may fail after a successful response has started (e.g. if the client
+// is too slow to receive it).
+func (c *accessLogRelayCall) setOutcome(outcome CallOutcome, errCode string) {
+	c.mut.Lock()
+	if c.entry.Outcome == "" {
+		c.entry.Outcome = outcome
+		c.entry.ErrorCode = errCode
+	}
+	c.mut.Unlock()
+}
+
+func (c *accessLogRelayCall) End() {
+	c.RelayCall.End()
+
+	c.mut.Lock()
+	entry := c.entry
+	c.mut.Unlock()
+
+	entry.RequestBytes = c.requestBytes.Load()
+	entry.ResponseBytes = c.responseBytes.Load()
+	entry.Duration = c.timeNow().Sub(entry.Start)
+	c.logger.Log(entry)
+}
+
+// logAccess logs an access log entry for a call handled by this channel.
+func (response *InboundCallResponse) logAccess(now time.Time) {
+	logger := response.conn.accessLogger
+	if logger == nil {
+		return
+	}
+
+	call := response.call
+	entry := AccessLogEntry{
+		Caller:        call.CallerName(),
+		Service:       call.ServiceName(),
+		Method:        call.MethodString(),
+		RoutingKey:    call.RoutingKey(),
+		Source:        call.RemotePeer().HostPort,
+		RequestBytes:  call.bytesRead,
+		ResponseBytes: response.bytesWritten.Load(),
+		TTL:           call.timeToLive,
+		Start:         response.calledAt,
+		Duration:      now.Sub(response.calledAt),
+		Outcome:       CallSuccess,
+	}
+	if response.systemError {
+		entry.Outcome = CallSystemError
+		entry.ErrorCode = response.systemErrCode.MetricsKey()
+	} else if response.applicationError {
+		entry.Outcome = CallApplicationError
+	}
+	logger.Log(entry)
+}
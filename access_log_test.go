@@ -0,0 +1,141 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/temporalio/tchannel-go"
+
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+type recordingAccessLogger struct {
+	sync.Mutex
+
+	entries []AccessLogEntry
+}
+
+func (l *recordingAccessLogger) Log(e AccessLogEntry) {
+	l.Lock()
+	l.entries = append(l.entries, e)
+	l.Unlock()
+}
+
+func (l *recordingAccessLogger) Entries() []AccessLogEntry {
+	l.Lock()
+	defer l.Unlock()
+	return append([]AccessLogEntry(nil), l.entries...)
+}
+
+func (l *recordingAccessLogger) Reset() {
+	l.Lock()
+	l.entries = nil
+	l.Unlock()
+}
+
+// find returns the entry for the given method that is relayed (or not).
+func (l *recordingAccessLogger) find(method string, relayed bool) (AccessLogEntry, bool) {
+	for _, e := range l.Entries() {
+		if e.Method == method && e.Relayed == relayed {
+			return e, true
+		}
+	}
+	return AccessLogEntry{}, false
+}
+
+func TestAccessLogRelayedAndHandled(t *testing.T) {
+	accessLog := &recordingAccessLogger{}
+	opts := testutils.NewOpts().
+		SetRelayOnly().
+		SetAccessLogger(accessLog)
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		// The same logger is used across test runs.
+		accessLog.Reset()
+
+		testutils.RegisterFunc(ts.Server(), "ok", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{Arg2: args.Arg2, Arg3: args.Arg3}, nil
+		})
+		testutils.RegisterFunc(ts.Server(), "appErr", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{IsErr: true}, nil
+		})
+		testutils.RegisterFunc(ts.Server(), "busy", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return nil, ErrServerBusy
+		})
+
+		client := ts.NewClient(nil)
+		ctx, cancel := NewContextBuilder(time.Second).SetRoutingKey("rk").Build()
+		defer cancel()
+
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "ok", []byte("arg2"), []byte("arg3"))
+		require.NoError(t, err, "ok call failed")
+		_, _, resp, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "appErr", nil, nil)
+		require.NoError(t, err, "appErr call failed")
+		require.True(t, resp.ApplicationError(), "expected application error")
+		_, _, _, err = raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "busy", nil, nil)
+		require.Equal(t, ErrCodeBusy, GetSystemErrorCode(err), "busy call should fail with busy")
+
+		// The relay logs calls once the response has been forwarded, so wait for all entries.
+		require.True(t, testutils.WaitFor(time.Second, func() bool {
+			return len(accessLog.Entries()) == 6
+		}), "expected 6 access log entries, got %v", accessLog.Entries())
+
+		tests := []struct {
+			method  string
+			outcome CallOutcome
+			errCode string
+		}{
+			{"ok", CallSuccess, ""},
+			{"appErr", CallApplicationError, ""},
+			{"busy", CallSystemError, "busy"},
+		}
+		for _, tt := range tests {
+			for _, relayed := range []bool{true, false} {
+				e, ok := accessLog.find(tt.method, relayed)
+				require.True(t, ok, "missing entry for %v (relayed: %v)", tt.method, relayed)
+
+				assert.Equal(t, client.ServiceName(), e.Caller, "caller mismatch")
+				assert.Equal(t, ts.ServiceName(), e.Service, "service mismatch")
+				assert.Equal(t, "rk", e.RoutingKey, "routing key mismatch")
+				assert.Equal(t, tt.outcome, e.Outcome, "outcome mismatch for %v", tt.method)
+				assert.Equal(t, tt.errCode, e.ErrorCode, "error code mismatch for %v", tt.method)
+				assert.NotZero(t, e.RequestBytes, "request bytes not recorded")
+				assert.NotEmpty(t, e.Source, "source not recorded")
+				assert.True(t, e.TTL > 0 && e.TTL <= time.Second, "unexpected TTL %v", e.TTL)
+				if relayed {
+					assert.Equal(t, ts.Server().PeerInfo().HostPort, e.Destination, "destination mismatch")
+				} else {
+					assert.Empty(t, e.Destination, "handled calls should not have a destination")
+				}
+				if tt.outcome != CallSystemError {
+					assert.NotZero(t, e.ResponseBytes, "response bytes not recorded")
+				}
+			}
+		}
+	})
+}
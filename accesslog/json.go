@@ -0,0 +1,116 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package accesslog provides tchannel.AccessLogger implementations.
+package accesslog
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+)
+
+// jsonEntry is the JSON representation of a tchannel.AccessLogEntry.
+type jsonEntry struct {
+	Time          string  `json:"ts"`
+	Relayed       bool    `json:"relayed"`
+	Caller        string  `json:"caller"`
+	Service       string  `json:"service"`
+	Method        string  `json:"method"`
+	RoutingKey    string  `json:"routingKey,omitempty"`
+	Source        string  `json:"source"`
+	Destination   string  `json:"destination,omitempty"`
+	RequestBytes  uint64  `json:"requestBytes"`
+	ResponseBytes uint64  `json:"responseBytes"`
+	TTLMillis     float64 `json:"ttlMs"`
+	DurationMs    float64 `json:"durationMs"`
+	Outcome       string  `json:"outcome"`
+	ErrorCode     string  `json:"errorCode,omitempty"`
+}
+
+func toJSONEntry(e tchannel.AccessLogEntry) jsonEntry {
+	return jsonEntry{
+		Time:          e.Start.UTC().Format(time.RFC3339Nano),
+		Relayed:       e.Relayed,
+		Caller:        e.Caller,
+		Service:       e.Service,
+		Method:        e.Method,
+		RoutingKey:    e.RoutingKey,
+		Source:        e.Source,
+		Destination:   e.Destination,
+		RequestBytes:  e.RequestBytes,
+		ResponseBytes: e.ResponseBytes,
+		TTLMillis:     toMillis(e.TTL),
+		DurationMs:    toMillis(e.Duration),
+		Outcome:       string(e.Outcome),
+		ErrorCode:     e.ErrorCode,
+	}
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// JSONWriter is an AccessLogger that writes each entry as a single line of
+// JSON (JSON lines) to an io.Writer.
+type JSONWriter struct {
+	mut    sync.Mutex
+	closer io.Closer
+	enc    *json.Encoder
+}
+
+// NewJSONWriter returns a JSONWriter that writes entries to w.
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{enc: json.NewEncoder(w)}
+}
+
+// NewJSONFile returns a JSONWriter that appends entries to the file at path,
+// creating it if it does not exist. The file should be closed using Close
+// once the channel has been closed.
+func NewJSONFile(path string) (*JSONWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	w := NewJSONWriter(f)
+	w.closer = f
+	return w, nil
+}
+
+// Log writes the entry as a line of JSON. Write errors are ignored, since
+// access logging should never fail a call.
+func (w *JSONWriter) Log(e tchannel.AccessLogEntry) {
+	w.mut.Lock()
+	w.enc.Encode(toJSONEntry(e))
+	w.mut.Unlock()
+}
+
+// Close closes the underlying file if the JSONWriter was created using NewJSONFile.
+func (w *JSONWriter) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
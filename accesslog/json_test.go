@@ -0,0 +1,109 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntry(method string) tchannel.AccessLogEntry {
+	return tchannel.AccessLogEntry{
+		Relayed:       true,
+		Caller:        "caller",
+		Service:       "svc",
+		Method:        method,
+		RoutingKey:    "rk",
+		Source:        "1.1.1.1:1",
+		Destination:   "2.2.2.2:2",
+		RequestBytes:  100,
+		ResponseBytes: 200,
+		TTL:           time.Second,
+		Start:         time.Unix(1500000000, 0),
+		Duration:      1500 * time.Microsecond,
+		Outcome:       tchannel.CallSystemError,
+		ErrorCode:     "busy",
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewJSONWriter(buf)
+	w.Log(testEntry("m1"))
+	w.Log(testEntry("m2"))
+	require.NoError(t, w.Close(), "Close failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2, "expected one line per entry")
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &got), "failed to unmarshal line")
+	assert.Equal(t, map[string]interface{}{
+		"ts":            "2017-07-14T02:40:00Z",
+		"relayed":       true,
+		"caller":        "caller",
+		"service":       "svc",
+		"method":        "m1",
+		"routingKey":    "rk",
+		"source":        "1.1.1.1:1",
+		"destination":   "2.2.2.2:2",
+		"requestBytes":  float64(100),
+		"responseBytes": float64(200),
+		"ttlMs":         float64(1000),
+		"durationMs":    1.5,
+		"outcome":       "system-error",
+		"errorCode":     "busy",
+	}, got, "unexpected JSON entry")
+}
+
+func TestJSONFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "accesslog")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "access.log")
+	for i := 0; i < 2; i++ {
+		w, err := NewJSONFile(path)
+		require.NoError(t, err, "NewJSONFile failed")
+		w.Log(testEntry("m"))
+		require.NoError(t, w.Close(), "Close failed")
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	require.NoError(t, err, "failed to read log file")
+	assert.Equal(t, 2, strings.Count(string(contents), "\n"), "file should be appended to")
+}
+
+func TestJSONFileError(t *testing.T) {
+	_, err := NewJSONFile(filepath.Join("non-existent-dir", "access.log"))
+	assert.Error(t, err, "expected error for missing directory")
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package accesslog
+
+import (
+	"math/rand"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/trand"
+)
+
+// SamplerOptions configures a sampling AccessLogger.
+type SamplerOptions struct {
+	// Rate is the fraction of calls that are logged, between 0 and 1.
+	Rate float64
+
+	// KeepFailures logs all calls that did not succeed, regardless of Rate.
+	KeepFailures bool
+
+	// rand is used to override the random number generator in tests.
+	rand *rand.Rand
+}
+
+type sampler struct {
+	logger tchannel.AccessLogger
+	opts   SamplerOptions
+}
+
+// NewSampler returns an AccessLogger that forwards a sample of entries to logger.
+func NewSampler(logger tchannel.AccessLogger, opts SamplerOptions) tchannel.AccessLogger {
+	if opts.rand == nil {
+		opts.rand = trand.NewSeeded()
+	}
+	return &sampler{
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+func (s *sampler) Log(e tchannel.AccessLogEntry) {
+	if s.opts.KeepFailures && e.Outcome != tchannel.CallSuccess {
+		s.logger.Log(e)
+		return
+	}
+	if s.opts.Rate >= 1 || s.opts.rand.Float64() < s.opts.Rate {
+		s.logger.Log(e)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/trand"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingLogger struct {
+	count map[tchannel.CallOutcome]int
+}
+
+func (l *countingLogger) Log(e tchannel.AccessLogEntry) {
+	l.count[e.Outcome]++
+}
+
+func TestSampler(t *testing.T) {
+	tests := []struct {
+		msg          string
+		opts         SamplerOptions
+		wantSuccess  int
+		wantFailures int
+	}{
+		{
+			msg:  "rate 0 drops everything",
+			opts: SamplerOptions{Rate: 0},
+		},
+		{
+			msg:          "rate 1 keeps everything",
+			opts:         SamplerOptions{Rate: 1},
+			wantSuccess:  1000,
+			wantFailures: 1000,
+		},
+		{
+			msg:          "keep failures with rate 0",
+			opts:         SamplerOptions{Rate: 0, KeepFailures: true},
+			wantFailures: 1000,
+		},
+		{
+			msg:          "rate 0.5",
+			opts:         SamplerOptions{Rate: 0.5},
+			wantSuccess:  500,
+			wantFailures: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			logger := &countingLogger{count: make(map[tchannel.CallOutcome]int)}
+			tt.opts.rand = trand.New(1)
+			s := NewSampler(logger, tt.opts)
+			for i := 0; i < 1000; i++ {
+				s.Log(tchannel.AccessLogEntry{Outcome: tchannel.CallSuccess})
+				s.Log(tchannel.AccessLogEntry{Outcome: tchannel.CallSystemError})
+			}
+
+			assert.InDelta(t, tt.wantSuccess, logger.count[tchannel.CallSuccess], 50, "unexpected number of successes")
+			assert.InDelta(t, tt.wantFailures, logger.count[tchannel.CallSystemError], 50, "unexpected number of failures")
+		})
+	}
+}
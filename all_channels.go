@@ -82,3 +82,19 @@ func findChannelByID(id uint32) (*Channel, bool) {
 
 	return nil, false
 }
+
+// AllChannels returns all the channels created in this process that have not
+// been closed, keyed by service name.
+// Note: like other introspection APIs, this is not a stable API.
+func AllChannels() map[string][]*Channel {
+	channelMap.Lock()
+	defer channelMap.Unlock()
+
+	all := make(map[string][]*Channel, len(channelMap.existing))
+	for svc, channels := range channelMap.existing {
+		if len(channels) > 0 {
+			all[svc] = append([]*Channel(nil), channels...)
+		}
+	}
+	return all
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bmizerany/perks/quantile"
+)
+
+const (
+	_defaultCallSummaryWindow  = time.Minute
+	_defaultCallSummaryBuckets = 6
+	_defaultCallSummaryMaxKeys = 1000
+
+	// callSummaryAppError is the error code used to summarize application errors.
+	callSummaryAppError = "application-error"
+)
+
+// DefaultCallSummaryQuantiles are the latency quantiles reported by default.
+var DefaultCallSummaryQuantiles = []float64{0.5, 0.9, 0.99}
+
+// CallSummaryOptions configure the in-memory summaries of recent calls.
+type CallSummaryOptions struct {
+	// Window is the period of time that is summarized. Defaults to 1 minute.
+	Window time.Duration
+
+	// Buckets is the number of buckets the window is split into. Calls expire
+	// from the summary one bucket at a time. Defaults to 6.
+	Buckets int
+
+	// Quantiles are the latency quantiles to report.
+	// Defaults to DefaultCallSummaryQuantiles.
+	Quantiles []float64
+
+	// MaxKeys is the maximum number of distinct direction, service, method and
+	// caller combinations that are summarized. Calls for any other combinations
+	// are not summarized. Defaults to 1000.
+	MaxKeys int
+}
+
+// CallSummary is a summary of recent calls for a single direction, service,
+// method and caller.
+type CallSummary struct {
+	// Direction is either "inbound" or "outbound".
+	Direction string `json:"direction"`
+	Service   string `json:"service"`
+	Method    string `json:"method"`
+	Caller    string `json:"caller"`
+
+	// Window is the period of time covered by this summary.
+	Window time.Duration `json:"window"`
+
+	// Calls is the number of calls completed in the window, including errors.
+	Calls uint64 `json:"calls"`
+
+	// Errors is the number of calls that failed, keyed by the system error
+	// code, or "application-error" for application errors.
+	Errors map[string]uint64 `json:"errors,omitempty"`
+
+	// Latency contains the latency quantiles, keyed by the quantile
+	// as a percentile, e.g. "p99".
+	Latency map[string]time.Duration `json:"latency"`
+}
+
+type callSummaryKey struct {
+	direction string
+	service   string
+	method    string
+	caller    string
+}
+
+type callSummaryBucket struct {
+	epoch   int64
+	calls   uint64
+	errors  map[string]uint64
+	latency *quantile.Stream
+}
+
+type callSummaryEntry struct {
+	sync.Mutex
+	buckets []callSummaryBucket
+}
+
+// callSummaries keeps rolling windows of call counts, errors and latencies.
+type callSummaries struct {
+	bucketSize time.Duration
+	numBuckets int
+	quantiles  []float64
+	maxKeys    int
+
+	sync.RWMutex
+	entries map[callSummaryKey]*callSummaryEntry
+}
+
+func newCallSummaries(opts *CallSummaryOptions) *callSummaries {
+	if opts == nil {
+		return nil
+	}
+
+	window := opts.Window
+	if window <= 0 {
+		window = _defaultCallSummaryWindow
+	}
+	numBuckets := opts.Buckets
+	if numBuckets <= 0 {
+		numBuckets = _defaultCallSummaryBuckets
+	}
+	quantiles := opts.Quantiles
+	if len(quantiles) == 0 {
+		quantiles = DefaultCallSummaryQuantiles
+	}
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = _defaultCallSummaryMaxKeys
+	}
+
+	// Buckets must be at least 1ns, as bucketSize is used as a divisor.
+	bucketSize := window / time.Duration(numBuckets)
+	if bucketSize < 1 {
+		bucketSize = 1
+	}
+
+	return &callSummaries{
+		bucketSize: bucketSize,
+		numBuckets: numBuckets,
+		quantiles:  quantiles,
+		maxKeys:    maxKeys,
+		entries:    make(map[callSummaryKey]*callSummaryEntry),
+	}
+}
+
+func (s *callSummaries) getEntry(key callSummaryKey) *callSummaryEntry {
+	s.RLock()
+	e, ok := s.entries[key]
+	s.RUnlock()
+	if ok {
+		return e
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.entries[key]; ok {
+		return e
+	}
+	if len(s.entries) >= s.maxKeys {
+		return nil
+	}
+	e = &callSummaryEntry{buckets: make([]callSummaryBucket, s.numBuckets)}
+	s.entries[key] = e
+	return e
+}
+
+// record adds a completed call to the summary. errCode is empty for
+// successful calls.
+func (s *callSummaries) record(key callSummaryKey, now time.Time, latency time.Duration, errCode string) {
+	e := s.getEntry(key)
+	if e == nil {
+		return
+	}
+
+	epoch := now.UnixNano() / int64(s.bucketSize)
+	e.Lock()
+	defer e.Unlock()
+
+	// Normalize the index, since epoch is negative for times before 1970.
+	n := int64(s.numBuckets)
+	b := &e.buckets[((epoch%n)+n)%n]
+	if b.epoch != epoch || b.latency == nil {
+		*b = callSummaryBucket{
+			epoch:   epoch,
+			latency: quantile.NewTargeted(s.quantiles...),
+		}
+	}
+	b.calls++
+	b.latency.Insert(float64(latency))
+	if errCode != "" {
+		if b.errors == nil {
+			b.errors = make(map[string]uint64)
+		}
+		b.errors[errCode]++
+	}
+}
+
+// summaries returns the summaries of calls within the window, sorted by
+// direction, service, method and caller. Entries without calls in the
+// window are omitted.
+func (s *callSummaries) summaries(now time.Time) []CallSummary {
+	s.RLock()
+	keys := make([]callSummaryKey, 0, len(s.entries))
+	entries := make([]*callSummaryEntry, 0, len(s.entries))
+	for k, e := range s.entries {
+		keys = append(keys, k)
+		entries = append(entries, e)
+	}
+	s.RUnlock()
+
+	epoch := now.UnixNano() / int64(s.bucketSize)
+	var result []CallSummary
+	for i, e := range entries {
+		if summary, ok := s.summarize(keys[i], e, epoch); ok {
+			result = append(result, summary)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Direction != b.Direction {
+			return a.Direction < b.Direction
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Caller < b.Caller
+	})
+	return result
+}
+
+func (s *callSummaries) summarize(key callSummaryKey, e *callSummaryEntry, epoch int64) (CallSummary, bool) {
+	summary := CallSummary{
+		Direction: key.direction,
+		Service:   key.service,
+		Method:    key.method,
+		Caller:    key.caller,
+		Window:    s.bucketSize * time.Duration(s.numBuckets),
+	}
+	latency := quantile.NewTargeted(s.quantiles...)
+
+	e.Lock()
+	for _, b := range e.buckets {
+		if b.latency == nil || b.epoch <= epoch-int64(s.numBuckets) || b.epoch > epoch {
+			continue
+		}
+		summary.Calls += b.calls
+		latency.Merge(b.latency.Samples())
+		for code, n := range b.errors {
+			if summary.Errors == nil {
+				summary.Errors = make(map[string]uint64)
+			}
+			summary.Errors[code] += n
+		}
+	}
+	e.Unlock()
+
+	if summary.Calls == 0 {
+		return summary, false
+	}
+	summary.Latency = make(map[string]time.Duration, len(s.quantiles))
+	for _, q := range s.quantiles {
+		summary.Latency[quantileName(q)] = time.Duration(latency.Query(q))
+	}
+	return summary, true
+}
+
+// quantileName returns the name of a quantile as a percentile, e.g. "p99.9".
+func quantileName(q float64) string {
+	return "p" + strconv.FormatFloat(q*100, 'f', -1, 64)
+}
+
+// CallSummaries returns summaries of recent calls, if the channel was created
+// with CallSummaries enabled.
+// Note: like other introspection APIs, this is intended for debugging.
+func (ch *Channel) CallSummaries() []CallSummary {
+	if ch.callSummaries == nil {
+		return nil
+	}
+	return ch.callSummaries.summaries(ch.timeNow())
+}
+
+func (ch *Channel) handleCallSummaries(arg3 []byte) interface{} {
+	return ch.CallSummaries()
+}
+
+func (response *InboundCallResponse) recordCallSummary(now time.Time) {
+	summaries := response.conn.callSummaries
+	if summaries == nil {
+		return
+	}
+
+	call := response.call
+	var errCode string
+	if response.systemError {
+		errCode = response.systemErrCode.MetricsKey()
+	} else if response.applicationError {
+		errCode = callSummaryAppError
+	}
+	summaries.record(callSummaryKey{
+		direction: inbound.String(),
+		service:   call.ServiceName(),
+		method:    call.MethodString(),
+		caller:    call.CallerName(),
+	}, now, now.Sub(response.calledAt), errCode)
+}
+
+func (response *OutboundCallResponse) recordCallSummary(now time.Time, latency time.Duration, unexpected error) {
+	summaries := response.call.conn.callSummaries
+	if summaries == nil {
+		return
+	}
+
+	var errCode string
+	if unexpected != nil {
+		errCode = GetSystemErrorCode(unexpected).MetricsKey()
+	} else if response.ApplicationError() {
+		errCode = callSummaryAppError
+	}
+	call := response.call
+	summaries.record(callSummaryKey{
+		direction: outbound.String(),
+		service:   call.callReq.Service,
+		method:    call.methodName,
+		// Use the caller name sent to the peer, which may be overridden by
+		// the call options, so the key matches the peer's inbound summary.
+		caller: call.callReq.Headers[CallerName],
+	}, now, latency, errCode)
+}
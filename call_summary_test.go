@@ -0,0 +1,219 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/temporalio/tchannel-go"
+
+	"github.com/temporalio/tchannel-go/json"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summariesByMethod(summaries []CallSummary) map[string]CallSummary {
+	m := make(map[string]CallSummary, len(summaries))
+	for _, s := range summaries {
+		m[s.Method] = s
+	}
+	return m
+}
+
+func TestCallSummaries(t *testing.T) {
+	clock := testutils.NewStubClock(time.Unix(1000, 0))
+	summaryOpts := &CallSummaryOptions{
+		Window:    time.Minute,
+		Buckets:   6,
+		Quantiles: []float64{0.5, 0.99},
+	}
+	opts := testutils.NewOpts().
+		SetTimeNow(clock.Now).
+		AddLogFilter("Couldn't find handler", 1).
+		NoRelay()
+	opts.CallSummaries = summaryOpts
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		testutils.RegisterFunc(ts.Server(), "echo", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			clock.Elapse(10 * time.Millisecond)
+			return &raw.Res{Arg2: args.Arg2, Arg3: args.Arg3}, nil
+		})
+		testutils.RegisterFunc(ts.Server(), "app-error", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{IsErr: true}, nil
+		})
+
+		clientOpts := testutils.NewOpts().SetTimeNow(clock.Now)
+		clientOpts.CallSummaries = summaryOpts
+		client := ts.NewClient(clientOpts)
+
+		ctx, cancel := NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		for i := 0; i < 3; i++ {
+			_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+			require.NoError(t, err, "echo failed")
+		}
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "app-error", nil, nil)
+		require.NoError(t, err, "app-error failed")
+		_, _, _, err = raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "unknown", nil, nil)
+		require.Error(t, err, "unknown method should fail")
+
+		// The server's summaries are recorded after the response is sent.
+		var serverSummaries []CallSummary
+		require.True(t, testutils.WaitFor(time.Second, func() bool {
+			serverSummaries = ts.Server().CallSummaries()
+			return len(serverSummaries) == 3
+		}), "unexpected server summaries: %+v", serverSummaries)
+
+		byMethod := summariesByMethod(serverSummaries)
+		echo := byMethod["echo"]
+		assert.Equal(t, "inbound", echo.Direction)
+		assert.Equal(t, ts.ServiceName(), echo.Service)
+		assert.Equal(t, "echo", echo.Method)
+		assert.Equal(t, client.ServiceName(), echo.Caller)
+		assert.Equal(t, time.Minute, echo.Window)
+		assert.Equal(t, uint64(3), echo.Calls)
+		assert.Empty(t, echo.Errors, "echo should not have errors")
+		assert.Equal(t, map[string]time.Duration{
+			"p50": 10 * time.Millisecond,
+			"p99": 10 * time.Millisecond,
+		}, echo.Latency, "unexpected echo latency")
+
+		assert.Equal(t, map[string]uint64{"application-error": 1}, byMethod["app-error"].Errors, "unexpected app-error errors")
+		assert.Equal(t, map[string]uint64{"bad-request": 1}, byMethod["unknown"].Errors, "unexpected unknown errors")
+
+		clientSummaries := client.CallSummaries()
+		require.Len(t, clientSummaries, 3, "unexpected client summaries: %+v", clientSummaries)
+		byMethod = summariesByMethod(clientSummaries)
+		assert.Equal(t, "outbound", byMethod["echo"].Direction)
+		assert.Equal(t, ts.ServiceName(), byMethod["echo"].Service)
+		assert.Equal(t, client.ServiceName(), byMethod["echo"].Caller)
+		assert.Equal(t, uint64(3), byMethod["echo"].Calls)
+		assert.Equal(t, map[string]uint64{"application-error": 1}, byMethod["app-error"].Errors)
+		assert.Equal(t, map[string]uint64{"bad-request": 1}, byMethod["unknown"].Errors)
+		assert.Equal(t, clientSummaries, client.IntrospectState(nil).CallSummaries, "IntrospectState should include summaries")
+
+		// The summaries are available using the _gometa_calls handler.
+		var resp []CallSummary
+		jctx, cancel := json.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		peer := client.Peers().GetOrAdd(ts.HostPort())
+		require.NoError(t, json.CallPeer(jctx, peer, "tchannel", "_gometa_calls", nil, &resp), "_gometa_calls failed")
+		assert.Contains(t, summariesByMethod(resp), "echo", "missing echo summary")
+
+		// Calls expire from the summaries once they're outside the window.
+		clock.Elapse(2 * time.Minute)
+		assert.Empty(t, client.CallSummaries(), "calls should expire")
+	})
+}
+
+func TestCallSummariesDisabled(t *testing.T) {
+	ch := testutils.NewClient(t, nil)
+	defer ch.Close()
+
+	assert.Nil(t, ch.CallSummaries(), "summaries should be nil when disabled")
+}
+
+func TestCallSummariesWindowSmallerThanBuckets(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		testutils.RegisterEcho(ts.Server(), nil)
+
+		clock := testutils.NewStubClock(time.Unix(1000, 0))
+		clientOpts := testutils.NewOpts().SetTimeNow(clock.Now)
+		clientOpts.CallSummaries = &CallSummaryOptions{
+			Window:  5 * time.Nanosecond,
+			Buckets: 10,
+		}
+		client := testutils.NewClient(t, clientOpts)
+		defer client.Close()
+
+		ctx, cancel := NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+		require.NoError(t, err, "echo failed")
+
+		summaries := client.CallSummaries()
+		require.Len(t, summaries, 1, "unexpected client summaries: %+v", summaries)
+		assert.Equal(t, uint64(1), summaries[0].Calls, "unexpected number of calls")
+	})
+}
+
+func TestCallSummariesCallerName(t *testing.T) {
+	clock := testutils.NewStubClock(time.Unix(1000, 0))
+	summaryOpts := &CallSummaryOptions{Window: time.Minute}
+	opts := testutils.NewOpts().SetTimeNow(clock.Now).NoRelay()
+	opts.CallSummaries = summaryOpts
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		testutils.RegisterEcho(ts.Server(), nil)
+
+		clientOpts := testutils.NewOpts().SetTimeNow(clock.Now)
+		clientOpts.CallSummaries = summaryOpts
+		client := ts.NewClient(clientOpts)
+
+		ctx, cancel := NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		call, err := client.BeginCall(ctx, ts.HostPort(), ts.ServiceName(), "echo", &CallOptions{
+			Format:     Raw,
+			CallerName: "override",
+		})
+		require.NoError(t, err, "BeginCall failed")
+		_, _, _, err = raw.WriteArgs(call, nil, nil)
+		require.NoError(t, err, "echo failed")
+
+		var serverSummaries []CallSummary
+		require.True(t, testutils.WaitFor(time.Second, func() bool {
+			serverSummaries = ts.Server().CallSummaries()
+			return len(serverSummaries) == 1
+		}), "unexpected server summaries: %+v", serverSummaries)
+		clientSummaries := client.CallSummaries()
+		require.Len(t, clientSummaries, 1, "unexpected client summaries: %+v", clientSummaries)
+
+		assert.Equal(t, "override", serverSummaries[0].Caller, "inbound caller should be the overridden name")
+		assert.Equal(t, "override", clientSummaries[0].Caller, "outbound caller should be the overridden name")
+
+		// Expire the summaries, which are checked for leaks.
+		clock.Elapse(2 * time.Minute)
+	})
+}
+
+func TestCallSummariesBeforeEpoch(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		testutils.RegisterEcho(ts.Server(), nil)
+
+		clock := testutils.NewStubClock(time.Unix(-1000, 0))
+		clientOpts := testutils.NewOpts().SetTimeNow(clock.Now)
+		clientOpts.CallSummaries = &CallSummaryOptions{Window: time.Minute}
+		client := testutils.NewClient(t, clientOpts)
+		defer client.Close()
+
+		ctx, cancel := NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+		require.NoError(t, err, "echo failed")
+
+		summaries := client.CallSummaries()
+		require.Len(t, summaries, 1, "unexpected client summaries: %+v", summaries)
+		assert.Equal(t, uint64(1), summaries[0].Calls, "unexpected number of calls")
+	})
+}
@@ -60,6 +60,10 @@ type CallOptions struct {
 	// Optionally override this field to support transparent proxying when inbound
 	// caller names vary across calls.
 	CallerName string
+
+	// ThriftProtocol is the Thrift protocol used to encode arg3, sent in the
+	// "tp" header. This header is only set if the ThriftProtocol is set.
+	ThriftProtocol string
 }
 
 var defaultCallOptions = &CallOptions{}
@@ -86,6 +90,9 @@ func (c *CallOptions) overrideHeaders(headers transportHeaders) {
 	if c.CallerName != "" {
 		headers[CallerName] = c.CallerName
 	}
+	if c.ThriftProtocol != "" {
+		headers[ThriftProtocol] = c.ThriftProtocol
+	}
 }
 
 // setResponseHeaders copies some headers from the incoming call request to the response.
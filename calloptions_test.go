@@ -32,6 +32,7 @@ func TestSetHeaders(t *testing.T) {
 		routingDelegate string
 		routingKey      string
 		callerName      string
+		thriftProtocol  string
 		expectedHeaders transportHeaders
 	}{
 		{
@@ -66,6 +67,14 @@ func TestSetHeaders(t *testing.T) {
 				RoutingKey: "canary",
 			},
 		},
+		{
+			format:         Thrift,
+			thriftProtocol: "compact",
+			expectedHeaders: transportHeaders{
+				ArgScheme:      Thrift.String(),
+				ThriftProtocol: "compact",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -74,6 +83,7 @@ func TestSetHeaders(t *testing.T) {
 			RoutingDelegate: tt.routingDelegate,
 			RoutingKey:      tt.routingKey,
 			CallerName:      tt.callerName,
+			ThriftProtocol:  tt.thriftProtocol,
 		}
 		headers := make(transportHeaders)
 		callOpts.setHeaders(headers)
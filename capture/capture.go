@@ -0,0 +1,345 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package capture writes and reads TChannel frame captures, and decodes the
+// captured frames into calls, responses and errors.
+//
+// A Writer can be used as the FrameTap in tchannel.ChannelOptions to
+// capture every frame sent and received by a channel's connections. Tapped
+// frames are queued and written in the background, so a slow disk doesn't
+// block connections. If the queue is full, frames are dropped.
+//
+// # Capture format
+//
+// A capture file starts with the 8 byte magic string "tchcap\x00\x01", where
+// the last byte is the format version. It's followed by a record for every
+// frame, with all integers encoded in big-endian:
+//
+//	time:8        Unix time in nanoseconds when the frame was sent or received
+//	connID:4      ID of the connection within its channel
+//	sent:1        1 if the frame was sent, 0 if it was received
+//	local~2       local address of the connection, prefixed with its length
+//	remote~2      remote address of the connection, prefixed with its length
+//	frame         the frame as sent on the wire, a 16 byte header with the
+//	              frame size followed by the payload
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/typed"
+
+	"go.uber.org/atomic"
+)
+
+// Magic is the header at the start of every capture file.
+const Magic = "tchcap\x00\x01"
+
+// _recordHeaderSize is the buffer size for a record's fields before the frame,
+// which is enough for any IPv6 host:port pair.
+const _recordHeaderSize = 1024
+
+// _defaultQueueSize is the default number of tapped frames that are queued
+// to be written.
+const _defaultQueueSize = 1024
+
+var (
+	// errBadMagic is returned when reading a file that is not a capture.
+	errBadMagic = errors.New("capture: not a tchannel capture file")
+
+	// errWriterClosed is returned when flushing a closed Writer.
+	errWriterClosed = errors.New("capture: writer is closed")
+)
+
+// Record is a single captured frame.
+type Record struct {
+	Time       time.Time
+	ConnID     uint32
+	Sent       bool
+	LocalAddr  string
+	RemoteAddr string
+	Frame      *tchannel.Frame
+}
+
+// WriterOptions are used to configure a Writer.
+type WriterOptions struct {
+	// QueueSize is the number of tapped frames that can be queued to be
+	// written. Frames tapped while the queue is full are dropped.
+	// Defaults to 1024.
+	QueueSize int
+}
+
+// Writer writes frames to a capture. It implements tchannel.FrameTap, and is
+// safe for concurrent use.
+type Writer struct {
+	queue   chan writerOp
+	closed  chan struct{}
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	closeOnce sync.Once
+
+	// mut protects the fields below, which are used by Write, and by the
+	// background goroutine that writes tapped frames.
+	mut sync.Mutex
+	w   *bufio.Writer
+	buf *typed.WriteBuffer
+	err error
+}
+
+// writerOp is a queued record, or a request to flush the queue if flushed
+// is set.
+type writerOp struct {
+	rec     Record
+	flushed chan error
+}
+
+var _ tchannel.FrameTap = (*Writer)(nil)
+
+// NewWriter returns a Writer that writes a capture to w.
+// Flush must be called to ensure all captured frames are written, and Close
+// to stop the background goroutine that writes tapped frames.
+func NewWriter(w io.Writer, opts *WriterOptions) (*Writer, error) {
+	if opts == nil {
+		opts = &WriterOptions{}
+	}
+	queueSize := opts.QueueSize
+	if queueSize <= 0 {
+		queueSize = _defaultQueueSize
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(Magic); err != nil {
+		return nil, err
+	}
+	cw := &Writer{
+		queue:  make(chan writerOp, queueSize),
+		closed: make(chan struct{}),
+		done:   make(chan struct{}),
+		w:      bw,
+		buf:    typed.NewWriteBufferWithSize(_recordHeaderSize),
+	}
+	go cw.writeQueued()
+	return cw, nil
+}
+
+// TapFrame queues a copy of the frame to be written to the capture, or drops
+// it if the queue is full. It does not block. Errors are reported by Flush.
+func (w *Writer) TapFrame(f tchannel.TappedFrame) {
+	select {
+	case <-w.closed:
+		w.dropped.Inc()
+		return
+	default:
+	}
+
+	// The tapped frame is pooled, so it must be copied before it's queued.
+	frame := tchannel.NewFrame(int(f.Frame.Header.PayloadSize()))
+	frame.Header = f.Frame.Header
+	copy(frame.Payload, f.Frame.SizedPayload())
+
+	op := writerOp{rec: Record{
+		Time:       f.Time,
+		ConnID:     f.ConnID,
+		Sent:       f.Sent,
+		LocalAddr:  f.LocalAddr,
+		RemoteAddr: f.RemoteAddr,
+		Frame:      frame,
+	}}
+	select {
+	case w.queue <- op:
+	default:
+		w.dropped.Inc()
+	}
+}
+
+// Dropped returns the number of tapped frames that were dropped because
+// the queue was full, or the Writer was closed.
+func (w *Writer) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// writeQueued writes queued records until the Writer is closed.
+func (w *Writer) writeQueued() {
+	defer close(w.done)
+	for {
+		select {
+		case op := <-w.queue:
+			if op.flushed != nil {
+				op.flushed <- w.flush()
+				continue
+			}
+			w.Write(op.rec)
+		case <-w.closed:
+			return
+		}
+	}
+}
+
+// Write writes a single record to the capture. Unlike TapFrame, it writes the
+// record before returning, and records queued by TapFrame may be written
+// after it.
+func (w *Writer) Write(r Record) error {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+
+	w.buf.Reset()
+	w.buf.WriteUint64(uint64(r.Time.UnixNano()))
+	w.buf.WriteUint32(r.ConnID)
+	if r.Sent {
+		w.buf.WriteSingleByte(1)
+	} else {
+		w.buf.WriteSingleByte(0)
+	}
+	w.buf.WriteLen16String(r.LocalAddr)
+	w.buf.WriteLen16String(r.RemoteAddr)
+	if err := w.buf.Err(); err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.buf.FlushTo(w.w); err != nil {
+		w.err = err
+		return err
+	}
+	if err := r.Frame.WriteOut(w.w); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// Flush writes any queued and buffered records, and returns the first error
+// encountered while writing the capture.
+func (w *Writer) Flush() error {
+	op := writerOp{flushed: make(chan error, 1)}
+	select {
+	case w.queue <- op:
+	case <-w.closed:
+		return errWriterClosed
+	}
+
+	select {
+	case err := <-op.flushed:
+		return err
+	case <-w.done:
+		return errWriterClosed
+	}
+}
+
+func (w *Writer) flush() error {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.w.Flush()
+	return w.err
+}
+
+// Close flushes the capture, and stops writing tapped frames. Frames that are
+// tapped after Close are dropped.
+func (w *Writer) Close() error {
+	err := errWriterClosed
+	w.closeOnce.Do(func() {
+		err = w.Flush()
+		close(w.closed)
+		<-w.done
+	})
+	return err
+}
+
+// Reader reads records from a capture.
+type Reader struct {
+	r      *bufio.Reader
+	header [8 + 4 + 1]byte
+}
+
+// NewReader returns a Reader for the capture in r, after validating the
+// capture's header.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errBadMagic
+		}
+		return nil, err
+	}
+	if !bytes.Equal(magic, []byte(Magic)) {
+		return nil, errBadMagic
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next record in the capture, or io.EOF at the end
+// of the capture.
+func (r *Reader) Next() (*Record, error) {
+	if _, err := io.ReadFull(r.r, r.header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("capture: truncated record: %v", err)
+		}
+		return nil, err
+	}
+
+	rbuf := typed.NewReadBuffer(r.header[:])
+	rec := &Record{
+		Time:   time.Unix(0, int64(rbuf.ReadUint64())),
+		ConnID: rbuf.ReadUint32(),
+		Sent:   rbuf.ReadSingleByte() == 1,
+	}
+
+	var err error
+	if rec.LocalAddr, err = r.readLen16String(); err != nil {
+		return nil, err
+	}
+	if rec.RemoteAddr, err = r.readLen16String(); err != nil {
+		return nil, err
+	}
+
+	rec.Frame = tchannel.NewFrame(tchannel.MaxFramePayloadSize)
+	if err := rec.Frame.ReadIn(r.r); err != nil {
+		return nil, fmt.Errorf("capture: failed to read frame: %v", err)
+	}
+	return rec, nil
+}
+
+func (r *Reader) readLen16String() (string, error) {
+	var lenBuf [2]byte
+	if _, err := io.ReadFull(r.r, lenBuf[:]); err != nil {
+		return "", fmt.Errorf("capture: truncated record: %v", err)
+	}
+	s := make([]byte, typed.NewReadBuffer(lenBuf[:]).ReadUint16())
+	if _, err := io.ReadFull(r.r, s); err != nil {
+		return "", fmt.Errorf("capture: truncated record: %v", err)
+	}
+	return string(s), nil
+}
@@ -0,0 +1,246 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/json"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/testutils"
+	"github.com/temporalio/tchannel-go/thrift"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.Lock()
+	defer b.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func decodeAll(t testing.TB, capture []byte) []*Message {
+	r, err := NewReader(bytes.NewReader(capture))
+	require.NoError(t, err, "NewReader failed")
+
+	var msgs []*Message
+	d := NewDecoder()
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Next failed")
+
+		msg, err := d.Add(rec)
+		require.NoError(t, err, "Add failed")
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	assert.Zero(t, d.Pending(), "unexpected incomplete messages")
+	return msgs
+}
+
+func findMessage(msgs []*Message, typ MessageType, sent bool, method string) *Message {
+	for _, m := range msgs {
+		if m.Type == typ && m.Sent == sent && (method == "" || m.Method == method) {
+			return m
+		}
+	}
+	return nil
+}
+
+func TestCaptureCalls(t *testing.T) {
+	var buf syncBuffer
+	w, err := NewWriter(&buf, nil)
+	require.NoError(t, err, "NewWriter failed")
+
+	opts := testutils.NewOpts().
+		AddLogFilter("Couldn't find handler", 1).
+		NoRelay()
+	opts.FrameTap = w
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		testutils.RegisterEcho(ts.Server(), nil)
+		json.Register(ts.Server(), json.Handlers{
+			"json-echo": func(ctx json.Context, arg map[string]string) (map[string]string, error) {
+				return arg, nil
+			},
+		}, nil)
+		client := ts.NewClient(nil)
+
+		ctx, cancel := tchannel.NewContextBuilder(testutils.Timeout(time.Second)).
+			SetShardKey("shard").
+			Build()
+		defer cancel()
+
+		largeArg3 := testutils.RandBytes(100000)
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", []byte("arg2"), largeArg3)
+		require.NoError(t, err, "echo failed")
+
+		_, _, _, err = raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "unknown", nil, nil)
+		require.Error(t, err, "unknown method should fail")
+
+		jctx := json.WithHeaders(ctx, map[string]string{"app": "header"})
+		var resp map[string]string
+		require.NoError(t, json.NewClient(client, ts.ServiceName(), &json.ClientOptions{
+			HostPort: ts.HostPort(),
+		}).Call(jctx, "json-echo", map[string]string{"k": "v"}, &resp), "json call failed")
+
+		// Frames are tapped by the connection's write loop, so wait for
+		// the responses to both echo calls to be captured.
+		require.True(t, testutils.WaitFor(time.Second, func() bool {
+			require.NoError(t, w.Flush(), "Flush failed")
+			var responses int
+			for _, m := range decodeAll(t, buf.Bytes()) {
+				if m.Type == CallRes && m.Sent {
+					responses++
+				}
+			}
+			return responses == 2
+		}), "missing captured responses")
+
+		// Close the writer before the test server checks for leaked goroutines.
+		require.NoError(t, w.Close(), "Close failed")
+	})
+
+	msgs := decodeAll(t, buf.Bytes())
+
+	req := findMessage(msgs, CallReq, false /* sent */, "echo")
+	require.NotNil(t, req, "missing echo call")
+	assert.Equal(t, "testService", req.Service)
+	assert.Equal(t, "arg2", string(req.Arg2))
+	assert.Equal(t, 100000, len(req.Arg3), "unexpected arg3 length")
+	assert.Greater(t, req.Frames, 1, "large arg3 should be fragmented")
+	assert.Equal(t, "shard", req.Headers["sk"], "missing shard key")
+	assert.Equal(t, tchannel.Raw, req.Format())
+	assert.True(t, req.TTL > 0 && req.TTL <= testutils.Timeout(time.Second), "unexpected TTL %v", req.TTL)
+
+	res := findMessage(msgs, CallRes, true /* sent */, "")
+	require.NotNil(t, res, "missing echo response")
+	assert.Equal(t, req.ID, res.ID, "response ID should match the call")
+	assert.Equal(t, 100000, len(res.Arg3), "unexpected response arg3 length")
+
+	errMsg := findMessage(msgs, Error, true /* sent */, "")
+	require.NotNil(t, errMsg, "missing error")
+	assert.Equal(t, tchannel.ErrCodeBadRequest, errMsg.ErrCode)
+	assert.Contains(t, errMsg.ErrMessage, "unknown")
+
+	jsonReq := findMessage(msgs, CallReq, false /* sent */, "json-echo")
+	require.NotNil(t, jsonReq, "missing json call")
+	appHeaders, err := jsonReq.AppHeaders()
+	require.NoError(t, err, "AppHeaders failed")
+	assert.Equal(t, map[string]string{"app": "header"}, appHeaders)
+	assert.JSONEq(t, `{"k": "v"}`, string(jsonReq.Arg3))
+}
+
+// blockingWriter is an io.Writer that blocks until unblock is closed.
+type blockingWriter struct {
+	syncBuffer
+	unblock chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.unblock
+	return w.syncBuffer.Write(p)
+}
+
+func TestWriterDropsFramesWhenFull(t *testing.T) {
+	bw := &blockingWriter{unblock: make(chan struct{})}
+	w, err := NewWriter(bw, &WriterOptions{QueueSize: 1})
+	require.NoError(t, err, "NewWriter failed")
+
+	// Frames larger than the write buffer are written through to bw, so the
+	// background goroutine blocks writing the first frame.
+	frame := tchannel.NewFrame(tchannel.MaxFramePayloadSize)
+	frame.Header.SetPayloadSize(tchannel.MaxFramePayloadSize)
+	tapped := tchannel.TappedFrame{Time: time.Now(), Frame: frame}
+
+	const numFrames = 5
+	tapDone := make(chan struct{})
+	go func() {
+		defer close(tapDone)
+		for i := 0; i < numFrames; i++ {
+			w.TapFrame(tapped)
+		}
+	}()
+	select {
+	case <-tapDone:
+	case <-time.After(testutils.Timeout(time.Second)):
+		t.Fatal("TapFrame blocked on a slow writer")
+	}
+	dropped := w.Dropped()
+	assert.True(t, dropped >= numFrames-2, "expected frames to be dropped, dropped %v", dropped)
+
+	close(bw.unblock)
+	require.NoError(t, w.Close(), "Close failed")
+	assert.Equal(t, errWriterClosed, w.Flush(), "Flush after Close should fail")
+
+	w.TapFrame(tapped)
+	assert.Equal(t, dropped+1, w.Dropped(), "frames tapped after Close should be dropped")
+
+	r, err := NewReader(bytes.NewReader(bw.Bytes()))
+	require.NoError(t, err, "NewReader failed")
+	var records uint64
+	for {
+		_, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Next failed")
+		records++
+	}
+	assert.Equal(t, numFrames-dropped, records, "unexpected number of captured frames")
+}
+
+func TestThriftAppHeaders(t *testing.T) {
+	var arg2 bytes.Buffer
+	require.NoError(t, thrift.WriteHeaders(&arg2, map[string]string{"k1": "v1", "k2": "v2"}), "WriteHeaders failed")
+
+	msg := &Message{
+		Type:    CallReq,
+		Headers: map[string]string{"as": "thrift"},
+		Arg2:    arg2.Bytes(),
+	}
+	headers, err := msg.AppHeaders()
+	require.NoError(t, err, "AppHeaders failed")
+	assert.Equal(t, map[string]string{"k1": "v1", "k2": "v2"}, headers)
+
+	msg.Arg2 = []byte{0, 1, 0}
+	_, err = msg.AppHeaders()
+	assert.Error(t, err, "truncated headers should fail")
+
+	msg.Headers["as"] = "raw"
+	headers, err = msg.AppHeaders()
+	assert.NoError(t, err, "raw arg2 should not fail")
+	assert.Nil(t, headers, "raw arg2 should not be decoded")
+}
+
+func TestReaderErrors(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("not a capture")))
+	assert.Equal(t, errBadMagic, err)
+
+	_, err = NewReader(bytes.NewReader(nil))
+	assert.Equal(t, errBadMagic, err)
+
+	r, err := NewReader(bytes.NewReader([]byte(Magic + "trunc")))
+	require.NoError(t, err, "NewReader failed")
+	_, err = r.Next()
+	assert.Error(t, err, "truncated record should fail")
+}
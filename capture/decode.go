@@ -0,0 +1,307 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/thrift/arg2"
+	"github.com/temporalio/tchannel-go/typed"
+)
+
+// MessageType is the type of a TChannel message.
+type MessageType byte
+
+// Message types, as defined by the TChannel protocol.
+const (
+	InitReq         MessageType = 0x01
+	InitRes         MessageType = 0x02
+	CallReq         MessageType = 0x03
+	CallRes         MessageType = 0x04
+	CallReqContinue MessageType = 0x13
+	CallResContinue MessageType = 0x14
+	Cancel          MessageType = 0xc0
+	Claim           MessageType = 0xc1
+	PingReq         MessageType = 0xd0
+	PingRes         MessageType = 0xd1
+	Error           MessageType = 0xff
+)
+
+const hasMoreFragmentsFlag = 0x01
+
+func (t MessageType) String() string {
+	switch t {
+	case InitReq:
+		return "init req"
+	case InitRes:
+		return "init res"
+	case CallReq:
+		return "call req"
+	case CallRes:
+		return "call res"
+	case CallReqContinue:
+		return "call req continue"
+	case CallResContinue:
+		return "call res continue"
+	case Cancel:
+		return "cancel"
+	case Claim:
+		return "claim"
+	case PingReq:
+		return "ping req"
+	case PingRes:
+		return "ping res"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("MessageType(0x%x)", byte(t))
+	}
+}
+
+// Message is a TChannel message decoded from one or more captured frames.
+type Message struct {
+	// Time, ConnID, Sent, LocalAddr and RemoteAddr are from the record
+	// of the message's first frame.
+	Time       time.Time
+	ConnID     uint32
+	Sent       bool
+	LocalAddr  string
+	RemoteAddr string
+
+	Type MessageType
+	ID   uint32
+
+	// Frames is the number of frames the message was decoded from.
+	Frames int
+
+	// TTL and Service are only set for call requests.
+	TTL     time.Duration
+	Service string
+
+	// ApplicationError is only set for call responses.
+	ApplicationError bool
+
+	// Headers are the transport headers for calls, and the init parameters
+	// for init messages.
+	Headers map[string]string
+
+	// Method, Arg2 and Arg3 are the arguments of calls. Method is empty
+	// for call responses.
+	Method string
+	Arg2   []byte
+	Arg3   []byte
+
+	// ErrCode and ErrMessage are only set for errors.
+	ErrCode    tchannel.SystemErrCode
+	ErrMessage string
+
+	// Version is the protocol version of init messages.
+	Version uint16
+}
+
+// Format returns the arg scheme of a call, e.g. "thrift" or "json".
+func (m *Message) Format() tchannel.Format {
+	return tchannel.Format(m.Headers["as"])
+}
+
+// AppHeaders decodes the application headers in arg2 for calls that use
+// the thrift or json arg schemes. It returns nil for other arg schemes.
+func (m *Message) AppHeaders() (map[string]string, error) {
+	switch m.Format() {
+	case tchannel.Thrift:
+		headers := make(map[string]string)
+		iter, err := arg2.NewKeyValIterator(m.Arg2)
+		for err == nil {
+			headers[string(iter.Key())] = string(iter.Value())
+			iter, err = iter.Next()
+		}
+		if err != io.EOF {
+			return nil, fmt.Errorf("failed to decode thrift headers: %v", err)
+		}
+		return headers, nil
+	case tchannel.JSON:
+		headers := make(map[string]string)
+		if len(m.Arg2) == 0 {
+			return headers, nil
+		}
+		if err := json.Unmarshal(m.Arg2, &headers); err != nil {
+			return nil, fmt.Errorf("failed to decode json headers: %v", err)
+		}
+		return headers, nil
+	default:
+		return nil, nil
+	}
+}
+
+type pendingKey struct {
+	localAddr  string
+	remoteAddr string
+	connID     uint32
+	sent       bool
+	id         uint32
+}
+
+type pendingCall struct {
+	msg  *Message
+	args [][]byte
+}
+
+// Decoder assembles captured frames into messages, joining the fragments
+// of calls and responses.
+type Decoder struct {
+	pending map[pendingKey]*pendingCall
+}
+
+// NewDecoder returns a new Decoder.
+func NewDecoder() *Decoder {
+	return &Decoder{pending: make(map[pendingKey]*pendingCall)}
+}
+
+// Pending returns the number of calls and responses that are waiting for
+// more fragments.
+func (d *Decoder) Pending() int {
+	return len(d.pending)
+}
+
+// Add decodes the record's frame, and returns the message if the frame
+// completes one. If the frame is a fragment of a larger message, nil
+// is returned.
+func (d *Decoder) Add(r *Record) (*Message, error) {
+	f := r.Frame
+	msg := &Message{
+		Time:       r.Time,
+		ConnID:     r.ConnID,
+		Sent:       r.Sent,
+		LocalAddr:  r.LocalAddr,
+		RemoteAddr: r.RemoteAddr,
+		Type:       MessageType(f.Header.MessageType()),
+		ID:         f.Header.ID,
+		Frames:     1,
+	}
+	key := pendingKey{r.LocalAddr, r.RemoteAddr, r.ConnID, r.Sent, f.Header.ID}
+	rbuf := typed.NewReadBuffer(f.SizedPayload())
+
+	switch msg.Type {
+	case InitReq, InitRes:
+		msg.Version = rbuf.ReadUint16()
+		msg.Headers = readHeaders(rbuf, 2)
+	case CallReq:
+		flags := rbuf.ReadSingleByte()
+		msg.TTL = time.Duration(rbuf.ReadUint32()) * time.Millisecond
+		rbuf.SkipBytes(25) // tracing
+		msg.Service = rbuf.ReadLen8String()
+		msg.Headers = readHeaders(rbuf, 1)
+		return d.addFragment(key, msg, flags, rbuf, false /* continuation */)
+	case CallRes:
+		flags := rbuf.ReadSingleByte()
+		msg.ApplicationError = rbuf.ReadSingleByte() != 0
+		rbuf.SkipBytes(25) // tracing
+		msg.Headers = readHeaders(rbuf, 1)
+		return d.addFragment(key, msg, flags, rbuf, false /* continuation */)
+	case CallReqContinue, CallResContinue:
+		flags := rbuf.ReadSingleByte()
+		return d.addFragment(key, msg, flags, rbuf, true /* continuation */)
+	case Error:
+		msg.ErrCode = tchannel.SystemErrCode(rbuf.ReadSingleByte())
+		rbuf.SkipBytes(25) // tracing
+		msg.ErrMessage = rbuf.ReadLen16String()
+		// An error ends any call that was in progress.
+		delete(d.pending, key)
+	}
+
+	if err := rbuf.Err(); err != nil {
+		return nil, fmt.Errorf("failed to decode %v: %v", msg.Type, err)
+	}
+	return msg, nil
+}
+
+func (d *Decoder) addFragment(key pendingKey, msg *Message, flags byte, rbuf *typed.ReadBuffer, continuation bool) (*Message, error) {
+	// Skip the checksum, which is 4 bytes for all types except none.
+	if csumType := rbuf.ReadSingleByte(); csumType != 0 {
+		rbuf.SkipBytes(4)
+	}
+
+	var chunks [][]byte
+	for rbuf.Err() == nil && rbuf.BytesRemaining() > 0 {
+		chunks = append(chunks, rbuf.ReadBytes(int(rbuf.ReadUint16())))
+	}
+	if err := rbuf.Err(); err != nil {
+		return nil, fmt.Errorf("failed to decode %v: %v", msg.Type, err)
+	}
+
+	call, ok := d.pending[key]
+	if continuation {
+		if !ok {
+			return nil, fmt.Errorf("%v %v without a preceding call", msg.Type, msg.ID)
+		}
+		call.msg.Frames++
+		if len(chunks) > 0 && len(call.args) > 0 {
+			// The first chunk in a continuation continues the last argument.
+			last := len(call.args) - 1
+			call.args[last] = append(call.args[last], chunks[0]...)
+			chunks = chunks[1:]
+		}
+	} else {
+		call = &pendingCall{msg: msg}
+	}
+	for _, c := range chunks {
+		call.args = append(call.args, append([]byte(nil), c...))
+	}
+
+	if flags&hasMoreFragmentsFlag != 0 {
+		d.pending[key] = call
+		return nil, nil
+	}
+
+	delete(d.pending, key)
+	args := call.args
+	for len(args) < 3 {
+		args = append(args, nil)
+	}
+	call.msg.Method = string(args[0])
+	call.msg.Arg2 = args[1]
+	call.msg.Arg3 = args[2]
+	return call.msg, nil
+}
+
+// readHeaders reads headers where the count, keys and values are prefixed
+// with lengths of the given size in bytes.
+func readHeaders(rbuf *typed.ReadBuffer, size int) map[string]string {
+	readLen := func() int {
+		if size == 1 {
+			return int(rbuf.ReadSingleByte())
+		}
+		return int(rbuf.ReadUint16())
+	}
+
+	n := readLen()
+	headers := make(map[string]string, n)
+	for i := 0; i < n && rbuf.Err() == nil; i++ {
+		k := rbuf.ReadString(readLen())
+		v := rbuf.ReadString(readLen())
+		headers[k] = v
+	}
+	return headers
+}
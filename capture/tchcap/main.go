@@ -0,0 +1,299 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// tchcap decodes TChannel frame captures written by the capture package, and
+// replays captured calls against a host:port.
+//
+// Usage:
+//
+//	tchcap decode [-max-arg n] <capture file>
+//	tchcap replay -peer host:port [-direction received|sent|all] [-service name] [-method name] [-timeout d] <capture file>
+//
+// Replay only replays calls that were received by default, since a capture
+// taken on a relay or proxy has every call twice, once as it was received and
+// once as it was forwarded. Use -direction sent for captures taken on a client.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/capture"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage:\n")
+	fmt.Fprintf(os.Stderr, "  %s decode [-max-arg n] <capture file>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s replay -peer host:port [-direction received|sent|all] [-service name] [-method name] [-timeout d] <capture file>\n", os.Args[0])
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch cmd := os.Args[1]; cmd {
+	case "decode":
+		err = decodeCmd(os.Args[2:])
+	case "replay":
+		err = replayCmd(os.Args[2:])
+	default:
+		log.Printf("unknown command %q", cmd)
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readMessages calls f for every message decoded from the capture file.
+func readMessages(path string, f func(*capture.Message)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open capture: %v", err)
+	}
+	defer file.Close()
+
+	r, err := capture.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read capture: %v", err)
+	}
+
+	d := capture.NewDecoder()
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read capture: %v", err)
+		}
+
+		msg, err := d.Add(rec)
+		if err != nil {
+			log.Printf("failed to decode frame: %v", err)
+			continue
+		}
+		if msg != nil {
+			f(msg)
+		}
+	}
+	if n := d.Pending(); n > 0 {
+		log.Printf("capture ended with %v incomplete messages", n)
+	}
+	return nil
+}
+
+func decodeCmd(args []string) error {
+	flags := flag.NewFlagSet("decode", flag.ExitOnError)
+	maxArg := flags.Int("max-arg", 256, "The maximum number of bytes of arg3 to print")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	return readMessages(flags.Arg(0), func(msg *capture.Message) {
+		fmt.Print(formatMessage(msg, *maxArg))
+	})
+}
+
+func formatMessage(msg *capture.Message, maxArg int) string {
+	var sb strings.Builder
+	from, to := msg.RemoteAddr, msg.LocalAddr
+	if msg.Sent {
+		from, to = msg.LocalAddr, msg.RemoteAddr
+	}
+	fmt.Fprintf(&sb, "%v conn=%v %v -> %v %v id=%v",
+		msg.Time.Format(time.RFC3339Nano), msg.ConnID, from, to, msg.Type, msg.ID)
+
+	switch msg.Type {
+	case capture.InitReq, capture.InitRes:
+		fmt.Fprintf(&sb, " version=%v\n", msg.Version)
+		fmt.Fprintf(&sb, "  params: %v\n", formatHeaders(msg.Headers))
+	case capture.CallReq, capture.CallRes:
+		if msg.Type == capture.CallReq {
+			fmt.Fprintf(&sb, " service=%v method=%v ttl=%v", msg.Service, msg.Method, msg.TTL)
+		} else {
+			fmt.Fprintf(&sb, " applicationError=%v", msg.ApplicationError)
+		}
+		fmt.Fprintf(&sb, " frames=%v\n", msg.Frames)
+		fmt.Fprintf(&sb, "  headers: %v\n", formatHeaders(msg.Headers))
+		if appHeaders, err := msg.AppHeaders(); err != nil {
+			fmt.Fprintf(&sb, "  arg2: %v (%q)\n", err, msg.Arg2)
+		} else if appHeaders != nil {
+			fmt.Fprintf(&sb, "  arg2: %v\n", formatHeaders(appHeaders))
+		} else {
+			fmt.Fprintf(&sb, "  arg2: %q\n", truncate(msg.Arg2, maxArg))
+		}
+		fmt.Fprintf(&sb, "  arg3: (%v bytes) %q\n", len(msg.Arg3), truncate(msg.Arg3, maxArg))
+	case capture.Error:
+		fmt.Fprintf(&sb, " code=%v message=%q\n", msg.ErrCode, msg.ErrMessage)
+	default:
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func formatHeaders(headers map[string]string) string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%v=%q", k, headers[k])
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func truncate(b []byte, max int) []byte {
+	if len(b) > max {
+		return b[:max]
+	}
+	return b
+}
+
+// replayOptions are the flags of the replay command.
+type replayOptions struct {
+	peer      string
+	direction string
+	service   string
+	method    string
+	timeout   time.Duration
+}
+
+// shouldReplay returns whether the message is a call that should be replayed.
+func (opts replayOptions) shouldReplay(msg *capture.Message) bool {
+	if msg.Type != capture.CallReq || (opts.method != "" && msg.Method != opts.method) {
+		return false
+	}
+	switch opts.direction {
+	case "sent":
+		return msg.Sent
+	case "all":
+		return true
+	default:
+		return !msg.Sent
+	}
+}
+
+func replayCmd(args []string) error {
+	var opts replayOptions
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	flags.StringVar(&opts.peer, "peer", "", "The host:port to replay calls against")
+	flags.StringVar(&opts.direction, "direction", "received", "Replay calls that were received, sent, or all calls")
+	flags.StringVar(&opts.service, "service", "", "Override the service of replayed calls")
+	flags.StringVar(&opts.method, "method", "", "Only replay calls to this method")
+	caller := flags.String("caller", "tchcap", "The caller name for replayed calls")
+	flags.DurationVar(&opts.timeout, "timeout", 0, "Timeout for each call, defaults to the captured TTL")
+	flags.Parse(args)
+	if opts.peer == "" || flags.NArg() != 1 {
+		usage()
+	}
+	switch opts.direction {
+	case "received", "sent", "all":
+	default:
+		return fmt.Errorf("invalid -direction %q, must be received, sent or all", opts.direction)
+	}
+
+	ch, err := tchannel.NewChannel(*caller, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create channel: %v", err)
+	}
+	defer ch.Close()
+
+	return replayCapture(ch, flags.Arg(0), opts, os.Stdout)
+}
+
+// replayCapture replays the calls in the capture file, and writes the result
+// of each call to out.
+func replayCapture(ch *tchannel.Channel, path string, opts replayOptions, out io.Writer) error {
+	return readMessages(path, func(msg *capture.Message) {
+		if !opts.shouldReplay(msg) {
+			return
+		}
+		if opts.service != "" {
+			msg.Service = opts.service
+		}
+
+		ttl := msg.TTL
+		if opts.timeout > 0 {
+			ttl = opts.timeout
+		}
+
+		start := time.Now()
+		res, err := replay(ch, opts.peer, msg, ttl)
+		if err != nil {
+			fmt.Fprintf(out, "%v::%v failed after %v: %v\n", msg.Service, msg.Method, time.Since(start), err)
+			return
+		}
+		fmt.Fprintf(out, "%v::%v succeeded after %v: applicationError=%v arg2=%v bytes arg3=%v bytes\n",
+			msg.Service, msg.Method, time.Since(start), res.ApplicationError, len(res.Arg2), len(res.Arg3))
+	})
+}
+
+type replayResult struct {
+	ApplicationError bool
+	Arg2, Arg3       []byte
+}
+
+func replay(ch *tchannel.Channel, hostPort string, msg *capture.Message, ttl time.Duration) (*replayResult, error) {
+	ctx, cancel := tchannel.NewContextBuilder(ttl).
+		SetShardKey(msg.Headers["sk"]).
+		SetRoutingKey(msg.Headers["rk"]).
+		SetRoutingDelegate(msg.Headers["rd"]).
+		Build()
+	defer cancel()
+
+	call, err := ch.BeginCall(ctx, hostPort, msg.Service, msg.Method, &tchannel.CallOptions{
+		Format: msg.Format(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tchannel.NewArgWriter(call.Arg2Writer()).Write(msg.Arg2); err != nil {
+		return nil, err
+	}
+	if err := tchannel.NewArgWriter(call.Arg3Writer()).Write(msg.Arg3); err != nil {
+		return nil, err
+	}
+
+	var res replayResult
+	response := call.Response()
+	if err := tchannel.NewArgReader(response.Arg2Reader()).Read(&res.Arg2); err != nil {
+		return nil, err
+	}
+	if err := tchannel.NewArgReader(response.Arg3Reader()).Read(&res.Arg3); err != nil {
+		return nil, err
+	}
+	res.ApplicationError = response.ApplicationError()
+	return &res, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/capture"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestShouldReplay(t *testing.T) {
+	received := &capture.Message{Type: capture.CallReq, Method: "echo"}
+	sent := &capture.Message{Type: capture.CallReq, Method: "echo", Sent: true}
+	res := &capture.Message{Type: capture.CallRes}
+
+	tests := []struct {
+		opts         replayOptions
+		wantReceived bool
+		wantSent     bool
+	}{
+		{opts: replayOptions{direction: "received"}, wantReceived: true},
+		{opts: replayOptions{direction: "sent"}, wantSent: true},
+		{opts: replayOptions{direction: "all"}, wantReceived: true, wantSent: true},
+		{opts: replayOptions{direction: "all", method: "other"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantReceived, tt.opts.shouldReplay(received), "received call with %+v", tt.opts)
+		assert.Equal(t, tt.wantSent, tt.opts.shouldReplay(sent), "sent call with %+v", tt.opts)
+		assert.False(t, tt.opts.shouldReplay(res), "responses should not be replayed with %+v", tt.opts)
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	msg := &capture.Message{
+		Time:       time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		ConnID:     1,
+		LocalAddr:  "local:1",
+		RemoteAddr: "remote:2",
+		Sent:       true,
+		Type:       capture.CallReq,
+		ID:         3,
+		Service:    "svc",
+		Method:     "echo",
+		TTL:        time.Second,
+		Frames:     1,
+		Headers:    map[string]string{"as": "raw", "cn": "caller"},
+		Arg2:       []byte("arg2"),
+		Arg3:       []byte("arg3 is long"),
+	}
+
+	want := strings.Join([]string{
+		"2020-01-02T03:04:05Z conn=1 local:1 -> remote:2 call req id=3 service=svc method=echo ttl=1s frames=1",
+		`  headers: {as="raw", cn="caller"}`,
+		`  arg2: "arg2"`,
+		`  arg3: (12 bytes) "arg3"`,
+		"",
+	}, "\n")
+	assert.Equal(t, want, formatMessage(msg, 4))
+}
+
+// captureCalls makes calls to echo with both the client and server tapped,
+// so every call is captured as sent by the client and received by the server.
+func captureCalls(t *testing.T, calls int) string {
+	path := filepath.Join(t.TempDir(), "capture")
+	f, err := os.Create(path)
+	require.NoError(t, err, "failed to create capture file")
+	defer f.Close()
+
+	w, err := capture.NewWriter(f, nil)
+	require.NoError(t, err, "NewWriter failed")
+
+	opts := testutils.NewOpts().NoRelay()
+	opts.FrameTap = w
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		testutils.RegisterEcho(ts.Server(), nil)
+
+		clientOpts := testutils.NewOpts()
+		clientOpts.FrameTap = w
+		client := ts.NewClient(clientOpts)
+
+		ctx, cancel := tchannel.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		for i := 0; i < calls; i++ {
+			_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", []byte("arg2"), []byte("arg3"))
+			require.NoError(t, err, "echo failed")
+		}
+
+		// Close the writer before the test server checks for leaked goroutines.
+		require.NoError(t, w.Close(), "Close failed")
+	})
+	return path
+}
+
+func TestReplayCapture(t *testing.T) {
+	const calls = 3
+	path := captureCalls(t, calls)
+
+	tests := []struct {
+		direction string
+		want      int
+	}{
+		{direction: "received", want: calls},
+		{direction: "sent", want: calls},
+		{direction: "all", want: 2 * calls},
+	}
+
+	for _, tt := range tests {
+		testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+			var replayed atomic.Int32
+			testutils.RegisterFunc(ts.Server(), "echo", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+				replayed.Inc()
+				assert.Equal(t, "arg2", string(args.Arg2), "unexpected arg2")
+				assert.Equal(t, "arg3", string(args.Arg3), "unexpected arg3")
+				return &raw.Res{Arg2: args.Arg2, Arg3: args.Arg3}, nil
+			})
+
+			var out bytes.Buffer
+			opts := replayOptions{
+				peer:      ts.HostPort(),
+				direction: tt.direction,
+				service:   ts.ServiceName(),
+				timeout:   testutils.Timeout(time.Second),
+			}
+			require.NoError(t, replayCapture(ts.NewClient(nil), path, opts, &out), "replay failed")
+
+			assert.Equal(t, tt.want, int(replayed.Load()), "unexpected replayed calls for direction %v", tt.direction)
+			assert.Equal(t, tt.want, strings.Count(out.String(), "succeeded"), "unexpected output: %v", out.String())
+		})
+	}
+}
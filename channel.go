@@ -34,6 +34,8 @@ import (
 	"github.com/temporalio/tchannel-go/tnet"
 
 	"github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/atomic"
 	"golang.org/x/net/context"
 )
@@ -74,11 +76,26 @@ type ChannelOptions struct {
 	// This is an unstable API - breaking changes are likely.
 	RelayLocalHandlers []string
 
+	// The list of methods that should be handled locally by this channel, while
+	// other methods of the same service are relayed. Methods should be in the
+	// format of Service::Method. This is useful to migrate endpoints into the
+	// relay one at a time.
+	// This is an unstable API - breaking changes are likely.
+	RelayLocalHandlerMethods []string
+
 	// The maximum allowable timeout for relayed calls (longer timeouts are
 	// clamped to this value). Passing zero uses the default of 2m.
 	// This is an unstable API - breaking changes are likely.
 	RelayMaxTimeout time.Duration
 
+	// The minimum remaining TTL required to relay a call. The relay subtracts
+	// the time spent in the relay (e.g. on peer selection or connection
+	// establishment) from the TTL before forwarding, and calls with less than
+	// this remaining are failed with a timeout error instead. Calls with less
+	// than a millisecond remaining are never relayed.
+	// This is an unstable API - breaking changes are likely.
+	RelayMinTimeout time.Duration
+
 	// If the relay needs to connect while processing a frame, this specifies
 	// the max connection timeout used.
 	RelayMaxConnectionTimeout time.Duration
@@ -94,9 +111,40 @@ type ChannelOptions struct {
 	// This is an unstable API - breaking changes are likely.
 	RelayTimerVerification bool
 
+	// RelayTracing enables creation of a span for each relayed call using the
+	// channel's Tracer. The span is a child of the caller's span, and the
+	// forwarded call is updated to be a child of the relay span.
+	// Only tracers that support Zipkin-style trace IDs are supported.
+	// This is an unstable API - breaking changes are likely.
+	RelayTracing bool
+
+	// RelayLogRateLimit configures the rate limiting of warnings logged by
+	// the relay for individual calls and frames, which are shared across all
+	// of the channel's connections. If nil, the defaults are used.
+	// Set Burst to a negative value to disable rate limiting.
+	RelayLogRateLimit *RateLimitedLoggerOptions
+
+	// RelayAdminEndpoints registers the relay admin endpoints (_relay_routes,
+	// _relay_stats, _relay_connections and _relay_drain) under the "tchannel"
+	// service on channels with a RelayHost. The endpoints are not
+	// authenticated, and _relay_drain changes which peers calls are relayed
+	// to, so they should only be enabled if the relay is not reachable by
+	// untrusted callers. Per-edge call counts are only tracked when enabled.
+	RelayAdminEndpoints bool
+
 	// The reporter to use for reporting stats for this channel.
 	StatsReporter StatsReporter
 
+	// AccessLogger is an optional sink that receives an entry for every
+	// completed call, both relayed and handled by this channel.
+	// See the accesslog package for built-in implementations.
+	AccessLogger AccessLogger
+
+	// CallSummaries enables in-memory summaries of recent inbound and outbound
+	// calls per service, method and caller, which are reported by
+	// IntrospectState and the _gometa_calls internal handler.
+	CallSummaries *CallSummaryOptions
+
 	// TimeNow is a variable for overriding time.Now in unit tests.
 	// Note: This is not a stable part of the API and may change.
 	TimeNow func() time.Time
@@ -120,6 +168,14 @@ type ChannelOptions struct {
 	// If not set, opentracing.GlobalTracer() is used.
 	Tracer opentracing.Tracer
 
+	// TracerProvider enables OpenTelemetry tracing. If set, client and server
+	// spans are created using a tracer from this provider, instead of Tracer.
+	TracerProvider trace.TracerProvider
+
+	// Propagator is used with TracerProvider to propagate the trace context in
+	// application headers. If not set, W3C trace context and baggage are used.
+	Propagator propagation.TextMapPropagator
+
 	// Handler is an alternate handler for all inbound requests, overriding the
 	// default handler that delegates to a subchannel.
 	Handler Handler
@@ -140,6 +196,10 @@ type ChannelOptions struct {
 	// the per-connection base context. This context is used as the parent context
 	// for incoming calls.
 	ConnContext func(ctx context.Context, conn net.Conn) context.Context
+
+	// FrameTap, if set, receives every frame sent and received on the
+	// channel's connections.
+	FrameTap FrameTap
 }
 
 // ChannelState is the state of a channel.
@@ -181,9 +241,13 @@ type Channel struct {
 	peers               *PeerList
 	relayHost           RelayHost
 	relayMaxTimeout     time.Duration
+	relayMinTimeout     time.Duration
 	relayMaxConnTimeout time.Duration
 	relayMaxTombs       uint64
 	relayTimerVerify    bool
+	relayTracing        bool
+	relayLogLimiter     *logRateLimiter
+	relayAdmin          *relayAdmin
 	internalHandlers    *handlerMap
 	handler             Handler
 	onPeerStatusChanged func(*Peer)
@@ -205,13 +269,18 @@ type Channel struct {
 // channelConnectionCommon is the list of common objects that both use
 // and can be copied directly from the channel to the connection.
 type channelConnectionCommon struct {
-	log           Logger
-	relayLocal    map[string]struct{}
-	statsReporter StatsReporter
-	tracer        opentracing.Tracer
-	subChannels   *subChannelMap
-	timeNow       func() time.Time
-	timeTicker    func(time.Duration) *time.Ticker
+	log               Logger
+	relayLocal        map[string]struct{}
+	relayLocalMethods map[string]map[string]struct{}
+	statsReporter     StatsReporter
+	accessLogger      AccessLogger
+	callSummaries     *callSummaries
+	tracer            opentracing.Tracer
+	otel              *otelTracing
+	subChannels       *subChannelMap
+	timeNow           func() time.Time
+	timeTicker        func(time.Duration) *time.Ticker
+	frameTap          FrameTap
 }
 
 // _nextChID is used to allocate unique IDs to every channel for debugging purposes.
@@ -275,6 +344,16 @@ func NewChannel(serviceName string, opts *ChannelOptions) (*Channel, error) {
 		return nil, err
 	}
 
+	localMethods := opts.RelayLocalHandlerMethods
+	if opts.RelayHost != nil && opts.RelayAdminEndpoints {
+		// The relay admin endpoints are always handled by the relay itself.
+		localMethods = append(relayAdminLocalMethods(), localMethods...)
+	}
+	relayLocalMethods, err := toServiceMethodSet("RelayLocalHandlerMethods", localMethods)
+	if err != nil {
+		return nil, err
+	}
+
 	// Default to dialContext if dialer is not passed in as an option
 	dialCtx := dialContext
 	if opts.Dialer != nil {
@@ -291,30 +370,51 @@ func NewChannel(serviceName string, opts *ChannelOptions) (*Channel, error) {
 
 	ch := &Channel{
 		channelConnectionCommon: channelConnectionCommon{
-			log:           logger,
-			relayLocal:    toStringSet(opts.RelayLocalHandlers),
-			statsReporter: statsReporter,
-			subChannels:   &subChannelMap{},
-			timeNow:       timeNow,
-			timeTicker:    timeTicker,
-			tracer:        opts.Tracer,
+			log:               logger,
+			relayLocal:        toStringSet(opts.RelayLocalHandlers),
+			relayLocalMethods: relayLocalMethods,
+			statsReporter:     statsReporter,
+			accessLogger:      opts.AccessLogger,
+			callSummaries:     newCallSummaries(opts.CallSummaries),
+			subChannels:       &subChannelMap{},
+			timeNow:           timeNow,
+			timeTicker:        timeTicker,
+			tracer:            opts.Tracer,
+			otel:              newOtelTracing(opts.TracerProvider, opts.Propagator),
+			frameTap:          opts.FrameTap,
 		},
 		chID:                chID,
 		connectionOptions:   opts.DefaultConnectionOptions.withDefaults(),
 		relayHost:           opts.RelayHost,
 		relayMaxTimeout:     validateRelayMaxTimeout(opts.RelayMaxTimeout, logger),
+		relayMinTimeout:     opts.RelayMinTimeout,
 		relayMaxConnTimeout: opts.RelayMaxConnectionTimeout,
 		relayMaxTombs:       opts.RelayMaxTombs,
 		relayTimerVerify:    opts.RelayTimerVerification,
+		relayTracing:        opts.RelayTracing,
 		dialer:              dialCtx,
 		connContext:         opts.ConnContext,
 		closed:              make(chan struct{}),
 	}
 	ch.peers = newRootPeerList(ch, opts.OnPeerStatusChanged).newChild()
+	if opts.RelayHost != nil {
+		if opts.RelayAdminEndpoints {
+			ch.relayAdmin = newRelayAdmin(_relayAdminMaxEdges)
+		}
+
+		var rateLimitOpts RateLimitedLoggerOptions
+		if opts.RelayLogRateLimit != nil {
+			rateLimitOpts = *opts.RelayLogRateLimit
+		}
+		if rateLimitOpts.TimeNow == nil {
+			rateLimitOpts.TimeNow = timeNow
+		}
+		ch.relayLogLimiter = newLogRateLimiter(rateLimitOpts, ch.log)
+	}
 
 	switch {
 	case len(opts.SkipHandlerMethods) > 0 && opts.Handler != nil:
-		sm, err := toServiceMethodSet(opts.SkipHandlerMethods)
+		sm, err := toServiceMethodSet("SkipHandlerMethods", opts.SkipHandlerMethods)
 		if err != nil {
 			return nil, err
 		}
@@ -909,13 +1009,14 @@ func toStringSet(ss []string) map[string]struct{} {
 }
 
 // take a list of service::method formatted string and make
-// the map[service]map[method]struct{} set
-func toServiceMethodSet(sms []string) (map[string]map[string]struct{}, error) {
+// the map[service]map[method]struct{} set. optName is the option
+// the list was configured with, and is used in errors.
+func toServiceMethodSet(optName string, sms []string) (map[string]map[string]struct{}, error) {
 	set := map[string]map[string]struct{}{}
 	for _, sm := range sms {
 		s := strings.Split(sm, "::")
 		if len(s) != 2 {
-			return nil, fmt.Errorf("each %q value should be of service::Method format but got %q", "SkipHandlerMethods", sm)
+			return nil, fmt.Errorf("each %q value should be of service::Method format but got %q", optName, sm)
 		}
 		svc, method := s[0], s[1]
 		if _, ok := set[svc]; !ok {
@@ -248,7 +248,7 @@ func TestToServiceMethodSet(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			r, err := toServiceMethodSet(tt.sms)
+			r, err := toServiceMethodSet("SkipHandlerMethods", tt.sms)
 			if tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 				return
@@ -0,0 +1,426 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// tcurl makes raw, json or thrift calls to a TChannel service, similar to
+// curl for HTTP.
+//
+// Examples:
+//
+//	tcurl -p 127.0.0.1:4040 -s svc -m echo --as raw -3 hello
+//	tcurl -p 127.0.0.1:4040 -s svc -m echo --as json -3 '{"msg": "hi"}'
+//	tcurl -p 127.0.0.1:4040 -s svc -t svc.thrift -m 'Svc::echo' -3 '{"msg": "hi"}'
+//	tcurl -p 127.0.0.1:4040 -s svc --health
+//	tcurl -p 127.0.0.1:4040 -s svc -m echo --as raw --benchmark-requests 1000
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/temporalio/tchannel-go"
+	tjson "github.com/temporalio/tchannel-go/json"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/thrift"
+	"github.com/temporalio/tchannel-go/thrift/dynamic"
+	"github.com/temporalio/tchannel-go/thrift/gen-go/meta"
+)
+
+var options = struct {
+	// Peers can be specified multiple times to call one of a set of peers.
+	Peers    []string `short:"p" long:"peer" description:"The host:port of a peer to call"`
+	PeerList string   `short:"P" long:"peerlist" description:"A file containing peers, one host:port per line or a JSON array"`
+
+	Service string `short:"s" long:"service" required:"true" description:"The service to call"`
+	Method  string `short:"m" long:"method" description:"The method to call, Service::method for thrift"`
+	As      string `long:"as" choice:"raw" choice:"json" choice:"thrift" description:"The arg scheme, defaults to thrift if --thrift is set and raw otherwise"`
+
+	Headers  string `long:"headers" description:"Application headers, a JSON object for json and thrift, or the raw arg2"`
+	Body     string `short:"3" long:"body" description:"The request body, a JSON object for json and thrift, or the raw arg3"`
+	BodyFile string `long:"body-file" description:"Read the request body from a file"`
+	Thrift   string `short:"t" long:"thrift" description:"The Thrift IDL file defining the method"`
+
+	Timeout     time.Duration `long:"timeout" default:"1s" description:"The timeout for each call"`
+	RoutingKey  string        `long:"rk" description:"The routing key for calls"`
+	ShardKey    string        `long:"sk" description:"The shard key for calls"`
+	Caller      string        `long:"caller" default:"tcurl" description:"The caller name to use"`
+	HealthCheck bool          `long:"health" description:"Call the Meta::health endpoint instead of a method"`
+
+	BenchmarkRequests    int           `long:"benchmark-requests" description:"Make this many calls and print a latency summary"`
+	BenchmarkDuration    time.Duration `long:"benchmark-duration" description:"Make calls for this long and print a latency summary"`
+	BenchmarkConcurrency int           `long:"benchmark-concurrency" default:"1" description:"The number of concurrent callers while benchmarking"`
+}{}
+
+// response is the result of a single call.
+type response struct {
+	OK       bool        `json:"ok"`
+	Headers  interface{} `json:"headers,omitempty"`
+	Body     interface{} `json:"body,omitempty"`
+	AppError bool        `json:"-"`
+}
+
+// caller makes a single call and returns the response.
+type caller func(ctx tchannel.ContextWithHeaders) (*response, error)
+
+func parseArgs() {
+	if _, err := flags.Parse(&options); err != nil {
+		os.Exit(-1)
+	}
+
+	if options.PeerList != "" {
+		peers, err := readPeerList(options.PeerList)
+		if err != nil {
+			log.Fatalf("failed to read peer list: %v", err)
+		}
+		options.Peers = append(options.Peers, peers...)
+	}
+	if len(options.Peers) == 0 {
+		log.Fatalf("at least one peer must be specified using --peer or --peerlist")
+	}
+	if options.Method == "" && !options.HealthCheck {
+		log.Fatalf("--method must be specified unless using --health")
+	}
+	if options.As == "" {
+		options.As = "raw"
+		if options.Thrift != "" {
+			options.As = "thrift"
+		}
+	}
+	if options.As == "thrift" && options.Thrift == "" && !options.HealthCheck {
+		log.Fatalf("--thrift must be specified for thrift calls")
+	}
+	if options.BodyFile != "" {
+		body, err := ioutil.ReadFile(options.BodyFile)
+		if err != nil {
+			log.Fatalf("failed to read body: %v", err)
+		}
+		options.Body = string(body)
+	}
+	if options.BenchmarkConcurrency < 1 {
+		options.BenchmarkConcurrency = 1
+	}
+}
+
+// readPeerList reads peers from a file containing either a JSON array of
+// host:ports, or a host:port per line.
+func readPeerList(file string) ([]string, error) {
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []string
+	if err := json.Unmarshal(contents, &peers); err == nil {
+		return peers, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(contents)))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			peers = append(peers, line)
+		}
+	}
+	return peers, scanner.Err()
+}
+
+func main() {
+	parseArgs()
+
+	ch, err := tchannel.NewChannel(options.Caller, nil)
+	if err != nil {
+		log.Fatalf("failed to create channel: %v", err)
+	}
+	defer ch.Close()
+
+	peers := ch.GetSubChannel(options.Service).Peers()
+	for _, hostPort := range options.Peers {
+		peers.Add(hostPort)
+	}
+
+	call, err := newCaller(ch)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if options.BenchmarkRequests > 0 || options.BenchmarkDuration > 0 {
+		benchmark(call)
+		return
+	}
+
+	res, err := doCall(call)
+	if err != nil {
+		log.Fatalf("call failed: %v", err)
+	}
+	out, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		log.Fatalf("failed to marshal response: %v", err)
+	}
+	fmt.Println(string(out))
+	if !res.OK {
+		os.Exit(1)
+	}
+}
+
+func doCall(call caller) (*response, error) {
+	ctx, cancel := tchannel.NewContextBuilder(options.Timeout).
+		SetRoutingKey(options.RoutingKey).
+		SetShardKey(options.ShardKey).
+		Build()
+	defer cancel()
+	return call(ctx)
+}
+
+func newCaller(ch *tchannel.Channel) (caller, error) {
+	if options.HealthCheck {
+		return healthCaller(ch), nil
+	}
+
+	switch options.As {
+	case "json":
+		return jsonCaller(ch)
+	case "thrift":
+		return thriftCaller(ch)
+	default:
+		return rawCaller(ch), nil
+	}
+}
+
+func rawCaller(ch *tchannel.Channel) caller {
+	sc := ch.GetSubChannel(options.Service)
+	return func(ctx tchannel.ContextWithHeaders) (*response, error) {
+		arg2, arg3, res, err := raw.CallSC(ctx, sc, options.Method, []byte(options.Headers), []byte(options.Body))
+		if err != nil {
+			return nil, err
+		}
+		return &response{
+			OK:       !res.ApplicationError(),
+			Headers:  string(arg2),
+			Body:     string(arg3),
+			AppError: res.ApplicationError(),
+		}, nil
+	}
+}
+
+func jsonCaller(ch *tchannel.Channel) (caller, error) {
+	headers, err := parseHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	var arg interface{}
+	if options.Body != "" {
+		if err := json.Unmarshal([]byte(options.Body), &arg); err != nil {
+			return nil, fmt.Errorf("failed to parse body: %v", err)
+		}
+	}
+
+	sc := ch.GetSubChannel(options.Service)
+	return func(ctx tchannel.ContextWithHeaders) (*response, error) {
+		ctx = tjson.WithHeaders(ctx, headers)
+
+		// Use CallSC rather than a Client, since only CallSC returns response headers.
+		var resp interface{}
+		err := tjson.CallSC(ctx, sc, options.Method, arg, &resp)
+		var appErr tjson.ErrApplication
+		if errors.As(err, &appErr) {
+			return &response{Headers: ctx.ResponseHeaders(), Body: appErr, AppError: true}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &response{OK: true, Headers: ctx.ResponseHeaders(), Body: resp}, nil
+	}, nil
+}
+
+func thriftCaller(ch *tchannel.Channel) (caller, error) {
+	headers, err := parseHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(options.Method, "::")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("thrift method %q must be of the form Service::method", options.Method)
+	}
+	idl, err := dynamic.Parse(options.Thrift)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := idl.Service(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	method, err := svc.Method(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.ArgsFromJSON([]byte(options.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	client := dynamic.NewClient(thrift.NewClient(ch, options.Service, nil), svc)
+	return func(ctx tchannel.ContextWithHeaders) (*response, error) {
+		ctx = thrift.WithHeaders(ctx, headers)
+
+		result, err := client.Call(ctx, method.Name(), args.Values)
+		if ex, ok := err.(*dynamic.Exception); ok {
+			body, err := dynamic.ToJSON(map[string]interface{}{ex.Name: ex.Values})
+			if err != nil {
+				return nil, err
+			}
+			return &response{Headers: ctx.ResponseHeaders(), Body: json.RawMessage(body), AppError: true}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			return &response{OK: true, Headers: ctx.ResponseHeaders()}, nil
+		}
+
+		body, err := dynamic.ToJSON(result)
+		if err != nil {
+			return nil, err
+		}
+		return &response{OK: true, Headers: ctx.ResponseHeaders(), Body: json.RawMessage(body)}, nil
+	}, nil
+}
+
+func healthCaller(ch *tchannel.Channel) caller {
+	client := thrift.NewClient(ch, options.Service, nil)
+	return func(ctx tchannel.ContextWithHeaders) (*response, error) {
+		args := &meta.MetaHealthArgs{Hr: &meta.HealthRequest{}}
+		var result meta.MetaHealthResult
+		if _, err := client.Call(thrift.Wrap(ctx), "Meta", "health", args, &result); err != nil {
+			return nil, err
+		}
+
+		status := result.GetSuccess()
+		if status == nil {
+			return nil, fmt.Errorf("health check returned no result")
+		}
+		return &response{OK: status.Ok, Body: status}, nil
+	}
+}
+
+func parseHeaders() (map[string]string, error) {
+	if options.Headers == "" {
+		return nil, nil
+	}
+
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(options.Headers), &headers); err != nil {
+		return nil, fmt.Errorf("failed to parse headers: %v", err)
+	}
+	return headers, nil
+}
+
+func benchmark(call caller) {
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		errs      = make(map[string]int)
+		remaining = options.BenchmarkRequests
+		wg        sync.WaitGroup
+	)
+
+	var deadline time.Time
+	start := time.Now()
+	if options.BenchmarkDuration > 0 {
+		deadline = start.Add(options.BenchmarkDuration)
+	}
+
+	// next reserves a request, returning false once the benchmark is done.
+	next := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return false
+		}
+		if options.BenchmarkRequests > 0 {
+			if remaining == 0 {
+				return false
+			}
+			remaining--
+		}
+		return true
+	}
+
+	for i := 0; i < options.BenchmarkConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next() {
+				callStart := time.Now()
+				res, err := doCall(call)
+				latency := time.Since(callStart)
+
+				mu.Lock()
+				latencies = append(latencies, latency)
+				if err != nil {
+					errs[err.Error()]++
+				} else if res.AppError {
+					errs["application error"]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	printBenchmark(latencies, errs, elapsed)
+}
+
+func printBenchmark(latencies []time.Duration, errs map[string]int, elapsed time.Duration) {
+	numErrors := 0
+	for _, count := range errs {
+		numErrors += count
+	}
+
+	fmt.Printf("Requests: %v, errors: %v, elapsed: %v, rps: %.1f\n",
+		len(latencies), numErrors, elapsed, float64(len(latencies))/elapsed.Seconds())
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		percentile := func(p float64) time.Duration {
+			return latencies[int(p*float64(len(latencies)-1))]
+		}
+		fmt.Printf("Latency: min %v, p50 %v, p90 %v, p99 %v, max %v\n",
+			latencies[0], percentile(0.5), percentile(0.9), percentile(0.99), latencies[len(latencies)-1])
+	}
+
+	errMsgs := make([]string, 0, len(errs))
+	for msg := range errs {
+		errMsgs = append(errMsgs, msg)
+	}
+	sort.Strings(errMsgs)
+	for _, msg := range errMsgs {
+		fmt.Printf("  %v: %v\n", errs[msg], msg)
+	}
+}
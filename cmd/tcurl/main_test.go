@@ -0,0 +1,272 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	tjson "github.com/temporalio/tchannel-go/json"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/testutils"
+	"github.com/temporalio/tchannel-go/thrift"
+	"github.com/temporalio/tchannel-go/thrift/dynamic"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+const storeIDL = "../../thrift/dynamic/test_files/store.thrift"
+
+// withOptions sets the global options for the duration of f.
+func withOptions(t testing.TB, f func()) {
+	saved := options
+	defer func() { options = saved }()
+
+	options.Timeout = testutils.Timeout(time.Second)
+	options.Caller = "tcurl-test"
+	f()
+}
+
+func TestReadPeerList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tcurl")
+	require.NoError(t, err, "TempDir failed")
+
+	tests := []struct {
+		msg      string
+		contents string
+		want     []string
+	}{
+		{
+			msg:      "json array",
+			contents: `["127.0.0.1:1", "127.0.0.1:2"]`,
+			want:     []string{"127.0.0.1:1", "127.0.0.1:2"},
+		},
+		{
+			msg:      "one peer per line",
+			contents: "# comment\n127.0.0.1:1\n\n  127.0.0.1:2  \n",
+			want:     []string{"127.0.0.1:1", "127.0.0.1:2"},
+		},
+	}
+
+	for i, tt := range tests {
+		file := filepath.Join(dir, "peers"+string(rune('0'+i)))
+		require.NoError(t, ioutil.WriteFile(file, []byte(tt.contents), 0644), "WriteFile failed")
+
+		peers, err := readPeerList(file)
+		require.NoError(t, err, "%v: readPeerList failed", tt.msg)
+		assert.Equal(t, tt.want, peers, "%v: unexpected peers", tt.msg)
+	}
+
+	_, err = readPeerList(filepath.Join(dir, "missing"))
+	assert.Error(t, err, "Expected error for missing peer list")
+}
+
+func TestParseHeaders(t *testing.T) {
+	withOptions(t, func() {
+		headers, err := parseHeaders()
+		require.NoError(t, err, "parseHeaders failed")
+		assert.Nil(t, headers, "Expected no headers")
+
+		options.Headers = `{"k": "v"}`
+		headers, err = parseHeaders()
+		require.NoError(t, err, "parseHeaders failed")
+		assert.Equal(t, map[string]string{"k": "v"}, headers)
+
+		options.Headers = `not json`
+		_, err = parseHeaders()
+		assert.Error(t, err, "Expected error for invalid headers")
+	})
+}
+
+func TestRawCaller(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		ts.RegisterFunc("echo", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{Arg2: args.Arg2, Arg3: args.Arg3}, nil
+		})
+		ts.RegisterFunc("fail", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{IsErr: true, Arg3: []byte("bad")}, nil
+		})
+
+		withOptions(t, func() {
+			options.Service = ts.ServiceName()
+			options.Method = "echo"
+			options.Headers = "h"
+			options.Body = "hello"
+			res, err := doCall(newTestCaller(t, ts))
+			require.NoError(t, err, "call failed")
+			assert.Equal(t, &response{OK: true, Headers: "h", Body: "hello"}, res)
+
+			options.Method = "fail"
+			res, err = doCall(newTestCaller(t, ts))
+			require.NoError(t, err, "call failed")
+			assert.False(t, res.OK, "Application errors should not be OK")
+			assert.True(t, res.AppError, "Expected application error")
+			assert.Equal(t, "bad", res.Body)
+		})
+	})
+}
+
+func TestJSONCaller(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		require.NoError(t, tjson.Register(ts.Server(), tjson.Handlers{
+			"echo": func(ctx tjson.Context, arg map[string]interface{}) (map[string]interface{}, error) {
+				ctx.SetResponseHeaders(ctx.Headers())
+				return arg, nil
+			},
+			"fail": func(ctx tjson.Context, arg map[string]interface{}) (map[string]interface{}, error) {
+				return nil, errors.New("bad")
+			},
+		}, nil), "Register failed")
+
+		withOptions(t, func() {
+			options.Service = ts.ServiceName()
+			options.As = "json"
+			options.Method = "echo"
+			options.Headers = `{"k": "v"}`
+			options.Body = `{"msg": "hi"}`
+			res, err := doCall(newTestCaller(t, ts))
+			require.NoError(t, err, "call failed")
+			assert.True(t, res.OK, "Expected successful call")
+			assert.Equal(t, map[string]string{"k": "v"}, res.Headers)
+			assert.Equal(t, map[string]interface{}{"msg": "hi"}, res.Body)
+
+			options.Method = "fail"
+			res, err = doCall(newTestCaller(t, ts))
+			require.NoError(t, err, "call failed")
+			assert.True(t, res.AppError, "Expected application error")
+
+			options.Body = `not json`
+			_, err = newCaller(ts.NewClient(nil))
+			assert.Error(t, err, "Expected error for invalid body")
+		})
+	})
+}
+
+func TestThriftCaller(t *testing.T) {
+	idl, err := dynamic.Parse(storeIDL)
+	require.NoError(t, err, "Parse failed")
+	svc, err := idl.Service("Store")
+	require.NoError(t, err, "Service failed")
+
+	server, err := dynamic.NewServer(svc, dynamic.Handlers{
+		"get": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			if args["key"] == "missing" {
+				return nil, &dynamic.Exception{Name: "notFound", Values: map[string]interface{}{"key": "missing"}}
+			}
+			return map[string]interface{}{"name": args["key"]}, nil
+		},
+		"put": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err, "NewServer failed")
+
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		thrift.NewServer(ts.Server()).Register(server)
+
+		withOptions(t, func() {
+			options.Service = ts.ServiceName()
+			options.As = "thrift"
+			options.Thrift = storeIDL
+			options.Method = "Store::get"
+			options.Body = `{"key": "k"}`
+			res, err := doCall(newTestCaller(t, ts))
+			require.NoError(t, err, "call failed")
+			assert.True(t, res.OK, "Expected successful call")
+			assert.JSONEq(t, `{"name": "k"}`, bodyJSON(t, res))
+
+			options.Body = `{"key": "missing"}`
+			res, err = doCall(newTestCaller(t, ts))
+			require.NoError(t, err, "call failed")
+			assert.True(t, res.AppError, "Expected application error")
+			assert.JSONEq(t, `{"notFound": {"key": "missing"}}`, bodyJSON(t, res))
+
+			options.Method = "Store::put"
+			options.Body = `{"key": "k", "item": {"name": "n"}}`
+			res, err = doCall(newTestCaller(t, ts))
+			require.NoError(t, err, "call failed")
+			assert.True(t, res.OK, "Expected successful call")
+			assert.Nil(t, res.Body, "void methods should have no body")
+
+			client := ts.NewClient(nil)
+			for _, method := range []string{"get", "Store::unknown", "Unknown::get"} {
+				options.Method = method
+				_, err := newCaller(client)
+				assert.Error(t, err, "Expected error for method %q", method)
+			}
+		})
+	})
+}
+
+func TestHealthCaller(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		thrift.NewServer(ts.Server())
+
+		withOptions(t, func() {
+			options.Service = ts.ServiceName()
+			options.HealthCheck = true
+			res, err := doCall(newTestCaller(t, ts))
+			require.NoError(t, err, "call failed")
+			assert.True(t, res.OK, "Expected healthy response")
+		})
+	})
+}
+
+func TestBenchmark(t *testing.T) {
+	withOptions(t, func() {
+		var calls int32
+		call := func(ctx tchannel.ContextWithHeaders) (*response, error) {
+			if atomic.AddInt32(&calls, 1)%2 == 0 {
+				return nil, errors.New("failed")
+			}
+			return &response{OK: true}, nil
+		}
+
+		options.BenchmarkRequests = 10
+		options.BenchmarkConcurrency = 3
+		benchmark(call)
+		assert.EqualValues(t, 10, atomic.LoadInt32(&calls), "Unexpected number of calls")
+	})
+}
+
+// newTestCaller returns a caller for the current options that calls ts.
+func newTestCaller(t testing.TB, ts *testutils.TestServer) caller {
+	ch := ts.NewClient(nil)
+	ch.GetSubChannel(options.Service).Peers().Add(ts.HostPort())
+	call, err := newCaller(ch)
+	require.NoError(t, err, "newCaller failed")
+	return call
+}
+
+// bodyJSON returns the response body serialized as JSON.
+func bodyJSON(t testing.TB, res *response) string {
+	body, err := json.Marshal(res.Body)
+	require.NoError(t, err, "failed to marshal body")
+	return string(body)
+}
@@ -670,6 +670,9 @@ func (c *Connection) readFrames(_ uint32) {
 		}
 
 		c.updateLastActivityRead(frame)
+		if c.frameTap != nil {
+			c.tapFrame(frame, false /* sent */)
+		}
 
 		var releaseFrame bool
 		if c.relay == nil {
@@ -741,6 +744,9 @@ func (c *Connection) writeFrames(_ uint32) {
 			}
 
 			c.updateLastActivityWrite(f)
+			if c.frameTap != nil {
+				c.tapFrame(f, true /* sent */)
+			}
 			err := f.WriteOut(c.conn)
 			c.opts.FramePool.Release(f)
 			if err != nil {
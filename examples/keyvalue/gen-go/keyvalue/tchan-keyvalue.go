@@ -10,6 +10,42 @@ import (
 	"github.com/temporalio/tchannel-go/thrift"
 )
 
+// thriftIDL is the Thrift IDL that this package was generated from.
+var thriftIDL = thrift.IDL{
+	EntryPoint: "keyvalue.thrift",
+	Files: map[string]string{
+		"keyvalue.thrift": "service baseService {\n" +
+			"  string HealthCheck()\n" +
+			"}\n" +
+			"\n" +
+			"exception KeyNotFound {\n" +
+			"  1: string key\n" +
+			"}\n" +
+			"\n" +
+			"exception InvalidKey {}\n" +
+			"\n" +
+			"service KeyValue extends baseService {\n" +
+			"  // If the key does not start with a letter, InvalidKey is returned.\n" +
+			"  // If the key does not exist, KeyNotFound is returned.\n" +
+			"  string Get(1: string key) throws (\n" +
+			"    1: KeyNotFound notFound\n" +
+			"    2: InvalidKey invalidKey)\n" +
+			"\n" +
+			"  // Set returns InvalidKey is an invalid key is sent.\n" +
+			"  void Set(1: string key, 2: string value) throws (\n" +
+			"    1: InvalidKey invalidKey\n" +
+			"  )\n" +
+			"}\n" +
+			"\n" +
+			"// Returned when the user is not authorized for the Admin service.\n" +
+			"exception NotAuthorized {}\n" +
+			"\n" +
+			"service Admin extends baseService {\n" +
+			"  void clearAll() throws (1: NotAuthorized notAuthorized)\n" +
+			"}\n",
+	},
+}
+
 // Interfaces for the service and client for the services defined in the IDL.
 
 // TChanAdmin is the interface that defines the server handler and client interface.
@@ -89,6 +125,11 @@ func (s *tchanAdminServer) Service() string {
 	return "Admin"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanAdminServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanAdminServer) Methods() []string {
 	return []string{
 		"clearAll",
@@ -109,31 +150,90 @@ func (s *tchanAdminServer) Handle(ctx thrift.Context, methodName string, protoco
 	}
 }
 
+func (s *tchanAdminServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "clearAll":
+		var req AdminClearAllArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	case "HealthCheck":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.ReadArgs(ctx, methodName, protocol)
+		}
+		return nil, fmt.Errorf("inherited method %v of service %v is not implemented by a TChanArgsServer", methodName, s.Service())
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanAdminServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "clearAll":
+		return s.handleClearAllArgs(ctx, args.(*AdminClearAllArgs))
+
+	case "HealthCheck":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.HandleArgs(ctx, methodName, args)
+		}
+		return false, nil, fmt.Errorf("inherited method %v of service %v is not implemented by a TChanArgsServer", methodName, s.Service())
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanAdminServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	case "clearAll":
+		var res AdminClearAllResult
+		return s.handleClearAllError(&res, err)
+
+	case "HealthCheck":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.HandleError(methodName, err)
+		}
+		return false, nil, err
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanAdminServer) handleClearAll(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req AdminClearAllArgs
-	var res AdminClearAllResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleClearAllArgs(ctx, &req)
+}
+
+func (s *tchanAdminServer) handleClearAllArgs(ctx thrift.Context, req *AdminClearAllArgs) (bool, athrift.TStruct, error) {
+	var res AdminClearAllResult
+
 	err :=
 		s.handler.ClearAll(ctx)
 
 	if err != nil {
-		switch v := err.(type) {
-		case *NotAuthorized:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for notAuthorized returned non-nil error type *NotAuthorized but nil value")
-			}
-			res.NotAuthorized = v
-		default:
-			return false, nil, err
-		}
-	} else {
+		return s.handleClearAllError(&res, err)
 	}
 
-	return err == nil, &res, nil
+	return true, &res, nil
+}
+
+func (s *tchanAdminServer) handleClearAllError(res *AdminClearAllResult, err error) (bool, athrift.TStruct, error) {
+	switch v := err.(type) {
+	case *NotAuthorized:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for notAuthorized returned non-nil error type *NotAuthorized but nil value")
+		}
+		res.NotAuthorized = v
+	default:
+		return false, nil, err
+	}
+	return false, res, nil
 }
 
 type tchanKeyValueClient struct {
@@ -214,6 +314,11 @@ func (s *tchanKeyValueServer) Service() string {
 	return "KeyValue"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanKeyValueServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanKeyValueServer) Methods() []string {
 	return []string{
 		"Get",
@@ -237,64 +342,143 @@ func (s *tchanKeyValueServer) Handle(ctx thrift.Context, methodName string, prot
 	}
 }
 
+func (s *tchanKeyValueServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "Get":
+		var req KeyValueGetArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	case "Set":
+		var req KeyValueSetArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	case "HealthCheck":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.ReadArgs(ctx, methodName, protocol)
+		}
+		return nil, fmt.Errorf("inherited method %v of service %v is not implemented by a TChanArgsServer", methodName, s.Service())
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanKeyValueServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "Get":
+		return s.handleGetArgs(ctx, args.(*KeyValueGetArgs))
+	case "Set":
+		return s.handleSetArgs(ctx, args.(*KeyValueSetArgs))
+
+	case "HealthCheck":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.HandleArgs(ctx, methodName, args)
+		}
+		return false, nil, fmt.Errorf("inherited method %v of service %v is not implemented by a TChanArgsServer", methodName, s.Service())
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanKeyValueServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	case "Get":
+		var res KeyValueGetResult
+		return s.handleGetError(&res, err)
+
+	case "Set":
+		var res KeyValueSetResult
+		return s.handleSetError(&res, err)
+
+	case "HealthCheck":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.HandleError(methodName, err)
+		}
+		return false, nil, err
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanKeyValueServer) handleGet(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req KeyValueGetArgs
-	var res KeyValueGetResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleGetArgs(ctx, &req)
+}
+
+func (s *tchanKeyValueServer) handleGetArgs(ctx thrift.Context, req *KeyValueGetArgs) (bool, athrift.TStruct, error) {
+	var res KeyValueGetResult
+
 	r, err :=
 		s.handler.Get(ctx, req.Key)
 
 	if err != nil {
-		switch v := err.(type) {
-		case *KeyNotFound:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for notFound returned non-nil error type *KeyNotFound but nil value")
-			}
-			res.NotFound = v
-		case *InvalidKey:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for invalidKey returned non-nil error type *InvalidKey but nil value")
-			}
-			res.InvalidKey = v
-		default:
-			return false, nil, err
-		}
-	} else {
-		res.Success = &r
+		return s.handleGetError(&res, err)
 	}
 
-	return err == nil, &res, nil
+	res.Success = &r
+	return true, &res, nil
+}
+
+func (s *tchanKeyValueServer) handleGetError(res *KeyValueGetResult, err error) (bool, athrift.TStruct, error) {
+	switch v := err.(type) {
+	case *KeyNotFound:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for notFound returned non-nil error type *KeyNotFound but nil value")
+		}
+		res.NotFound = v
+	case *InvalidKey:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for invalidKey returned non-nil error type *InvalidKey but nil value")
+		}
+		res.InvalidKey = v
+	default:
+		return false, nil, err
+	}
+	return false, res, nil
 }
 
 func (s *tchanKeyValueServer) handleSet(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req KeyValueSetArgs
-	var res KeyValueSetResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleSetArgs(ctx, &req)
+}
+
+func (s *tchanKeyValueServer) handleSetArgs(ctx thrift.Context, req *KeyValueSetArgs) (bool, athrift.TStruct, error) {
+	var res KeyValueSetResult
+
 	err :=
 		s.handler.Set(ctx, req.Key, req.Value)
 
 	if err != nil {
-		switch v := err.(type) {
-		case *InvalidKey:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for invalidKey returned non-nil error type *InvalidKey but nil value")
-			}
-			res.InvalidKey = v
-		default:
-			return false, nil, err
-		}
-	} else {
+		return s.handleSetError(&res, err)
 	}
 
-	return err == nil, &res, nil
+	return true, &res, nil
+}
+
+func (s *tchanKeyValueServer) handleSetError(res *KeyValueSetResult, err error) (bool, athrift.TStruct, error) {
+	switch v := err.(type) {
+	case *InvalidKey:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for invalidKey returned non-nil error type *InvalidKey but nil value")
+		}
+		res.InvalidKey = v
+	default:
+		return false, nil, err
+	}
+	return false, res, nil
 }
 
 type tchanBaseServiceClient struct {
@@ -344,6 +528,11 @@ func (s *tchanBaseServiceServer) Service() string {
 	return "baseService"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanBaseServiceServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanBaseServiceServer) Methods() []string {
 	return []string{
 		"HealthCheck",
@@ -360,22 +549,57 @@ func (s *tchanBaseServiceServer) Handle(ctx thrift.Context, methodName string, p
 	}
 }
 
+func (s *tchanBaseServiceServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "HealthCheck":
+		var req BaseServiceHealthCheckArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanBaseServiceServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "HealthCheck":
+		return s.handleHealthCheckArgs(ctx, args.(*BaseServiceHealthCheckArgs))
+
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanBaseServiceServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanBaseServiceServer) handleHealthCheck(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req BaseServiceHealthCheckArgs
-	var res BaseServiceHealthCheckResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleHealthCheckArgs(ctx, &req)
+}
+
+func (s *tchanBaseServiceServer) handleHealthCheckArgs(ctx thrift.Context, req *BaseServiceHealthCheckArgs) (bool, athrift.TStruct, error) {
+	var res BaseServiceHealthCheckResult
+
 	r, err :=
 		s.handler.HealthCheck(ctx)
 
 	if err != nil {
 		return false, nil, err
-	} else {
-		res.Success = &r
 	}
 
-	return err == nil, &res, nil
+	res.Success = &r
+	return true, &res, nil
 }
@@ -10,6 +10,31 @@ import (
 	"github.com/temporalio/tchannel-go/thrift"
 )
 
+// thriftIDL is the Thrift IDL that this package was generated from.
+var thriftIDL = thrift.IDL{
+	EntryPoint: "example.thrift",
+	Files: map[string]string{
+		"example.thrift": "struct HealthCheckRes {\n" +
+			"  1: bool healthy,\n" +
+			"  2: string msg,\n" +
+			"}\n" +
+			"\n" +
+			"service Base {\n" +
+			"  void BaseCall()\n" +
+			"}\n" +
+			"\n" +
+			"service First extends Base {\n" +
+			"  string Echo(1:string msg)\n" +
+			"  HealthCheckRes Healthcheck()\n" +
+			"  void AppError()\n" +
+			"}\n" +
+			"\n" +
+			"service Second {\n" +
+			"  void Test()\n" +
+			"}\n",
+	},
+}
+
 // Interfaces for the service and client for the services defined in the IDL.
 
 // TChanBase is the interface that defines the server handler and client interface.
@@ -80,6 +105,11 @@ func (s *tchanBaseServer) Service() string {
 	return "Base"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanBaseServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanBaseServer) Methods() []string {
 	return []string{
 		"BaseCall",
@@ -96,23 +126,58 @@ func (s *tchanBaseServer) Handle(ctx thrift.Context, methodName string, protocol
 	}
 }
 
+func (s *tchanBaseServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "BaseCall":
+		var req BaseBaseCallArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanBaseServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "BaseCall":
+		return s.handleBaseCallArgs(ctx, args.(*BaseBaseCallArgs))
+
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanBaseServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanBaseServer) handleBaseCall(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req BaseBaseCallArgs
-	var res BaseBaseCallResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleBaseCallArgs(ctx, &req)
+}
+
+func (s *tchanBaseServer) handleBaseCallArgs(ctx thrift.Context, req *BaseBaseCallArgs) (bool, athrift.TStruct, error) {
+	var res BaseBaseCallResult
+
 	err :=
 		s.handler.BaseCall(ctx)
 
 	if err != nil {
 		return false, nil, err
-	} else {
 	}
 
-	return err == nil, &res, nil
+	return true, &res, nil
 }
 
 type tchanFirstClient struct {
@@ -198,6 +263,11 @@ func (s *tchanFirstServer) Service() string {
 	return "First"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanFirstServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanFirstServer) Methods() []string {
 	return []string{
 		"AppError",
@@ -224,63 +294,135 @@ func (s *tchanFirstServer) Handle(ctx thrift.Context, methodName string, protoco
 	}
 }
 
+func (s *tchanFirstServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "AppError":
+		var req FirstAppErrorArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	case "Echo":
+		var req FirstEchoArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	case "Healthcheck":
+		var req FirstHealthcheckArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	case "BaseCall":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.ReadArgs(ctx, methodName, protocol)
+		}
+		return nil, fmt.Errorf("inherited method %v of service %v is not implemented by a TChanArgsServer", methodName, s.Service())
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanFirstServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "AppError":
+		return s.handleAppErrorArgs(ctx, args.(*FirstAppErrorArgs))
+	case "Echo":
+		return s.handleEchoArgs(ctx, args.(*FirstEchoArgs))
+	case "Healthcheck":
+		return s.handleHealthcheckArgs(ctx, args.(*FirstHealthcheckArgs))
+
+	case "BaseCall":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.HandleArgs(ctx, methodName, args)
+		}
+		return false, nil, fmt.Errorf("inherited method %v of service %v is not implemented by a TChanArgsServer", methodName, s.Service())
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanFirstServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	case "BaseCall":
+		if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+			return argsServer.HandleError(methodName, err)
+		}
+		return false, nil, err
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanFirstServer) handleAppError(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req FirstAppErrorArgs
-	var res FirstAppErrorResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleAppErrorArgs(ctx, &req)
+}
+
+func (s *tchanFirstServer) handleAppErrorArgs(ctx thrift.Context, req *FirstAppErrorArgs) (bool, athrift.TStruct, error) {
+	var res FirstAppErrorResult
+
 	err :=
 		s.handler.AppError(ctx)
 
 	if err != nil {
 		return false, nil, err
-	} else {
 	}
 
-	return err == nil, &res, nil
+	return true, &res, nil
 }
 
 func (s *tchanFirstServer) handleEcho(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req FirstEchoArgs
-	var res FirstEchoResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleEchoArgs(ctx, &req)
+}
+
+func (s *tchanFirstServer) handleEchoArgs(ctx thrift.Context, req *FirstEchoArgs) (bool, athrift.TStruct, error) {
+	var res FirstEchoResult
+
 	r, err :=
 		s.handler.Echo(ctx, req.Msg)
 
 	if err != nil {
 		return false, nil, err
-	} else {
-		res.Success = &r
 	}
 
-	return err == nil, &res, nil
+	res.Success = &r
+	return true, &res, nil
 }
 
 func (s *tchanFirstServer) handleHealthcheck(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req FirstHealthcheckArgs
-	var res FirstHealthcheckResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleHealthcheckArgs(ctx, &req)
+}
+
+func (s *tchanFirstServer) handleHealthcheckArgs(ctx thrift.Context, req *FirstHealthcheckArgs) (bool, athrift.TStruct, error) {
+	var res FirstHealthcheckResult
+
 	r, err :=
 		s.handler.Healthcheck(ctx)
 
 	if err != nil {
 		return false, nil, err
-	} else {
-		res.Success = r
 	}
 
-	return err == nil, &res, nil
+	res.Success = r
+	return true, &res, nil
 }
 
 type tchanSecondClient struct {
@@ -330,6 +472,11 @@ func (s *tchanSecondServer) Service() string {
 	return "Second"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanSecondServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanSecondServer) Methods() []string {
 	return []string{
 		"Test",
@@ -346,21 +493,56 @@ func (s *tchanSecondServer) Handle(ctx thrift.Context, methodName string, protoc
 	}
 }
 
+func (s *tchanSecondServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "Test":
+		var req SecondTestArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanSecondServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "Test":
+		return s.handleTestArgs(ctx, args.(*SecondTestArgs))
+
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanSecondServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanSecondServer) handleTest(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req SecondTestArgs
-	var res SecondTestResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleTestArgs(ctx, &req)
+}
+
+func (s *tchanSecondServer) handleTestArgs(ctx thrift.Context, req *SecondTestArgs) (bool, athrift.TStruct, error) {
+	var res SecondTestResult
+
 	err :=
 		s.handler.Test(ctx)
 
 	if err != nil {
 		return false, nil, err
-	} else {
 	}
 
-	return err == nil, &res, nil
+	return true, &res, nil
 }
@@ -178,6 +178,12 @@ func (r *fragmentingReader) Read(b []byte) (int, error) {
 			return totalRead, io.EOF
 		}
 
+		// Return the data we have rather than waiting for the next
+		// fragment, so streamed arguments can be read as they arrive.
+		if totalRead > 0 {
+			return totalRead, nil
+		}
+
 		if r.err = r.recvAndParseNextFragment(false); r.err != nil {
 			return totalRead, r.err
 		}
@@ -0,0 +1,197 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFragmentedArg writes arg as the last argument using fragments of
+// testFragmentPayloadSize, so every fragment holds at most 8 bytes of arg.
+func writeFragmentedArg(t *testing.T, ch fragmentChannel, arg []byte) {
+	w := newFragmentingWriter(NullLogger, ch, ChecksumTypeCrc32.New())
+	writer, err := w.ArgWriter(true /* last */)
+	require.NoError(t, err, "ArgWriter failed")
+	_, err = writer.Write(arg)
+	require.NoError(t, err, "Write failed")
+	require.NoError(t, writer.Close(), "Close failed")
+}
+
+func TestFragmentingReaderPartialReads(t *testing.T) {
+	arg := []byte("ABCDEFGHIJKLMNOPQRST")
+	ch := make(fragmentChannel, 10)
+	writeFragmentedArg(t, ch, arg)
+
+	r := newFragmentingReader(NullLogger, ch)
+	reader, err := r.ArgReader(true /* last */)
+	require.NoError(t, err, "ArgReader failed")
+
+	// Each read returns at most the data in a single fragment, even though
+	// the buffer is large enough for the whole argument.
+	var reads []string
+	buf := make([]byte, 64)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			reads = append(reads, string(buf[:n]))
+		}
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Read failed")
+	}
+	assert.Equal(t, []string{"ABCDEFGH", "IJKLMNOP", "QRST"}, reads, "unexpected reads")
+	assert.NoError(t, reader.Close(), "Close failed")
+}
+
+func TestFragmentingReaderSmallReads(t *testing.T) {
+	arg := []byte("ABCDEFGHIJKLMNOPQRST")
+	ch := make(fragmentChannel, 10)
+	writeFragmentedArg(t, ch, arg)
+
+	r := newFragmentingReader(NullLogger, ch)
+	reader, err := r.ArgReader(true /* last */)
+	require.NoError(t, err, "ArgReader failed")
+
+	// Reads that are smaller than a fragment are filled from the current
+	// fragment, and stop at the end of the fragment.
+	var reads []string
+	buf := make([]byte, 6)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			reads = append(reads, string(buf[:n]))
+		}
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Read failed")
+	}
+	assert.Equal(t, []string{"ABCDEF", "GH", "IJKLMN", "OP", "QRST"}, reads, "unexpected reads")
+	assert.NoError(t, reader.Close(), "Close failed")
+}
+
+func TestFragmentingReaderDoesNotWaitForNextFragment(t *testing.T) {
+	ch := make(fragmentChannel, 10)
+	w := newFragmentingWriter(NullLogger, ch, ChecksumTypeCrc32.New())
+	writer, err := w.ArgWriter(true /* last */)
+	require.NoError(t, err, "ArgWriter failed")
+
+	// Fill the first fragment, which is sent, and leave the rest buffered.
+	_, err = writer.Write([]byte("ABCDEFGHIJ"))
+	require.NoError(t, err, "Write failed")
+	require.Len(t, ch, 1, "expected only the first fragment to be sent")
+
+	r := newFragmentingReader(NullLogger, ch)
+	reader, err := r.ArgReader(true /* last */)
+	require.NoError(t, err, "ArgReader failed")
+
+	readDone := make(chan string)
+	go func() {
+		buf := make([]byte, 64)
+		n, _ := reader.Read(buf)
+		readDone <- string(buf[:n])
+	}()
+
+	select {
+	case got := <-readDone:
+		assert.Equal(t, "ABCDEFGH", got, "unexpected data")
+	case <-time.After(time.Second):
+		t.Fatal("Read blocked waiting for the next fragment")
+	}
+
+	require.NoError(t, writer.Close(), "Close failed")
+	rest, err := ioutil.ReadAll(reader)
+	require.NoError(t, err, "ReadAll failed")
+	assert.Equal(t, "IJ", string(rest), "unexpected remaining data")
+}
+
+func TestFragmentingReaderFullReaders(t *testing.T) {
+	// Callers that need the whole argument use helpers that read until EOF
+	// or until their buffer is full, so they are unaffected by partial reads.
+	const arg = `{"key": "ABCDEFGHIJKLMNOPQRSTUVWXYZ"}`
+
+	tests := []struct {
+		msg  string
+		read func(reader ArgReader) ([]byte, error)
+	}{
+		{
+			msg: "ioutil.ReadAll",
+			read: func(reader ArgReader) ([]byte, error) {
+				return ioutil.ReadAll(reader)
+			},
+		},
+		{
+			msg: "io.ReadFull",
+			read: func(reader ArgReader) ([]byte, error) {
+				buf := make([]byte, len(arg))
+				_, err := io.ReadFull(reader, buf)
+				return buf, err
+			},
+		},
+		{
+			msg: "ArgReadHelper.Read",
+			read: func(reader ArgReader) ([]byte, error) {
+				var bs []byte
+				err := NewArgReader(reader, nil).Read(&bs)
+				return bs, err
+			},
+		},
+		{
+			msg: "ArgReadHelper.ReadJSON",
+			read: func(reader ArgReader) ([]byte, error) {
+				var v map[string]string
+				if err := NewArgReader(reader, nil).ReadJSON(&v); err != nil {
+					return nil, err
+				}
+				return []byte(`{"key": "` + v["key"] + `"}`), nil
+			},
+		},
+		{
+			msg: "bytes.Buffer.ReadFrom",
+			read: func(reader ArgReader) ([]byte, error) {
+				var buf bytes.Buffer
+				_, err := buf.ReadFrom(reader)
+				return buf.Bytes(), err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		ch := make(fragmentChannel, 10)
+		writeFragmentedArg(t, ch, []byte(arg))
+
+		r := newFragmentingReader(NullLogger, ch)
+		reader, err := r.ArgReader(true /* last */)
+		require.NoError(t, err, "%v: ArgReader failed", tt.msg)
+
+		got, err := tt.read(reader)
+		require.NoError(t, err, "%v: read failed", tt.msg)
+		assert.Equal(t, arg, string(got), "%v: unexpected argument", tt.msg)
+	}
+}
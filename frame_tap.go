@@ -0,0 +1,64 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import "time"
+
+// TappedFrame is a frame that was sent or received on a connection.
+type TappedFrame struct {
+	// ConnID is the ID of the connection within its channel.
+	ConnID uint32
+
+	// LocalAddr and RemoteAddr are the addresses of the connection.
+	LocalAddr  string
+	RemoteAddr string
+
+	// Sent is true for frames sent on the connection, and false for
+	// frames that were received.
+	Sent bool
+
+	// Time is when the frame was sent or received.
+	Time time.Time
+
+	// Frame is only valid until TapFrame returns, as frames are pooled.
+	Frame *Frame
+}
+
+// FrameTap receives a copy of every frame sent and received on a connection
+// once the connection is established, and can be used to capture frames for
+// debugging. See the capture package for an implementation that writes
+// frames to a capture file.
+type FrameTap interface {
+	// TapFrame is called from the connection's read and write loops,
+	// so it should not block.
+	TapFrame(f TappedFrame)
+}
+
+func (c *Connection) tapFrame(frame *Frame, sent bool) {
+	c.frameTap.TapFrame(TappedFrame{
+		ConnID:     c.connID,
+		LocalAddr:  c.conn.LocalAddr().String(),
+		RemoteAddr: c.conn.RemoteAddr().String(),
+		Sent:       sent,
+		Time:       c.timeNow(),
+		Frame:      frame,
+	})
+}
@@ -1,6 +1,6 @@
 module github.com/temporalio/tchannel-go
 
-go 1.17
+go 1.18
 
 require (
 	github.com/apache/thrift v0.16.0
@@ -10,12 +10,17 @@ require (
 	github.com/jessevdk/go-flags v1.5.0
 	github.com/opentracing/opentracing-go v1.1.0
 	github.com/prashantv/protectmem v0.0.0-20171002184600-e20412882b3a
+	github.com/prometheus/client_golang v1.12.2
 	github.com/samuel/go-thrift v0.0.0-20190219015601-e8b6b52668fe
-	github.com/stretchr/testify v1.7.0
+	github.com/stretchr/testify v1.7.1
 	github.com/uber-go/tally v3.3.15+incompatible
 	github.com/uber/jaeger-client-go v2.22.1+incompatible
+	go.opentelemetry.io/otel v1.10.0
+	go.opentelemetry.io/otel/sdk v1.10.0
+	go.opentelemetry.io/otel/trace v1.10.0
 	go.uber.org/atomic v1.7.0
 	go.uber.org/multierr v1.7.0
+	go.uber.org/zap v1.21.0
 	golang.org/x/net v0.7.0
 	golang.org/x/sys v0.5.0
 	gopkg.in/yaml.v2 v2.4.0
@@ -23,11 +28,21 @@ require (
 
 require (
 	github.com/HdrHistogram/hdrhistogram-go v1.1.2 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/objx v0.1.0 // indirect
+	github.com/prometheus/client_model v0.2.0 // indirect
+	github.com/prometheus/common v0.32.1 // indirect
+	github.com/prometheus/procfs v0.7.3 // indirect
+	github.com/stretchr/objx v0.1.1 // indirect
 	github.com/uber/jaeger-lib v2.4.1+incompatible // indirect
+	google.golang.org/protobuf v1.26.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
@@ -0,0 +1,232 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package http
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/temporalio/tchannel-go"
+
+	"golang.org/x/net/context"
+)
+
+// Messages with trailers declare them using the Trailer header in arg2, and
+// set the Transfer-Encoding header to chunked. The body in arg3 is then
+// chunked using HTTP/1.1 chunked encoding, followed by the trailers.
+const (
+	trailerHeader          = "Trailer"
+	transferEncodingHeader = "Transfer-Encoding"
+	chunkedEncoding        = "chunked"
+)
+
+// copyBufferSize is the size of the buffer used to copy request bodies.
+const copyBufferSize = 32 * 1024
+
+// withTrailers returns the headers to write for a message with the given trailers.
+func withTrailers(header, trailer http.Header) http.Header {
+	if len(trailer) == 0 {
+		return header
+	}
+
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	header = header.Clone()
+	header.Set(trailerHeader, strings.Join(keys, ", "))
+	header.Set(transferEncodingHeader, chunkedEncoding)
+	return header
+}
+
+// trailerKeys returns the trailer keys declared in the Trailer header.
+func trailerKeys(header http.Header) []string {
+	var keys []string
+	for _, v := range header[trailerHeader] {
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = append(keys, http.CanonicalHeaderKey(k))
+			}
+		}
+	}
+	return keys
+}
+
+// bodyWriter writes a message body to arg3. If the message has trailers, the
+// body is chunked, and the trailers are written when the body is closed.
+type bodyWriter struct {
+	arg3    tchannel.ArgWriter
+	w       io.Writer
+	chunked io.WriteCloser
+}
+
+func newBodyWriter(arg3 tchannel.ArgWriter, chunked bool) *bodyWriter {
+	w := &bodyWriter{arg3: arg3, w: arg3}
+	if chunked {
+		w.chunked = httputil.NewChunkedWriter(arg3)
+		w.w = w.chunked
+	}
+	return w
+}
+
+func (w *bodyWriter) Write(bs []byte) (int, error) {
+	return w.w.Write(bs)
+}
+
+// Flush sends any written bytes to the peer without waiting for the fragment to fill.
+func (w *bodyWriter) Flush() error {
+	return w.arg3.Flush()
+}
+
+// copyFrom copies body to arg3, flushing after every read so the body is
+// streamed to the peer as it is read. It stops with the context's error once
+// ctx is done.
+func (w *bodyWriter) copyFrom(ctx context.Context, body io.Reader) error {
+	buf := make([]byte, copyBufferSize)
+	for {
+		n, err := body.Read(buf)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return tchannel.GetContextError(ctxErr)
+		}
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// closeOnDone closes body if ctx is done before the returned stop function is
+// called, which unblocks any pending read of the body.
+func closeOnDone(ctx context.Context, body io.Closer) (stop func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	var wg sync.WaitGroup
+	stopped := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			body.Close()
+		case <-stopped:
+		}
+	}()
+	return func() {
+		close(stopped)
+		wg.Wait()
+	}
+}
+
+// close ends the body, writing trailer if the body is chunked, and closes arg3.
+func (w *bodyWriter) close(trailer http.Header) error {
+	if w.chunked != nil {
+		if err := w.chunked.Close(); err != nil {
+			return err
+		}
+		if err := trailer.Write(w.arg3); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w.arg3, "\r\n"); err != nil {
+			return err
+		}
+	}
+	return w.arg3.Close()
+}
+
+// readBody returns the body for a message read from arg3. If the headers declare
+// trailers, the declared headers are removed, and the returned trailer contains
+// the declared keys. The trailer values are set once the body returns io.EOF.
+func readBody(arg3 tchannel.ArgReader, header http.Header) (io.ReadCloser, http.Header) {
+	if header.Get(transferEncodingHeader) != chunkedEncoding {
+		return arg3, nil
+	}
+
+	trailer := make(http.Header)
+	for _, k := range trailerKeys(header) {
+		trailer[k] = nil
+	}
+	header.Del(trailerHeader)
+	header.Del(transferEncodingHeader)
+
+	br := bufio.NewReader(arg3)
+	return &chunkedBody{
+		arg3:    arg3,
+		br:      br,
+		chunks:  httputil.NewChunkedReader(br),
+		trailer: trailer,
+	}, trailer
+}
+
+// chunkedBody reads a chunked body, followed by the trailers.
+type chunkedBody struct {
+	arg3    tchannel.ArgReader
+	br      *bufio.Reader
+	chunks  io.Reader
+	trailer http.Header
+	err     error
+}
+
+func (b *chunkedBody) Read(bs []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	n, err := b.chunks.Read(bs)
+	if err == io.EOF {
+		err = b.readTrailer()
+	}
+	b.err = err
+	return n, err
+}
+
+func (b *chunkedBody) readTrailer() error {
+	trailer, err := textproto.NewReader(b.br).ReadMIMEHeader()
+	if err != nil {
+		return err
+	}
+	for k, vs := range trailer {
+		b.trailer[k] = vs
+	}
+	return io.EOF
+}
+
+func (b *chunkedBody) Close() error {
+	return b.arg3.Close()
+}
@@ -249,3 +249,227 @@ func TestProxyRequests(t *testing.T) {
 		compareResponses(t, tt.name, resp1, resp2)
 	}
 }
+
+// withHTTPHandler registers a TChannel handler that calls h, and calls f with a
+// function to begin calls to the handler.
+func withHTTPHandler(t *testing.T, h http.HandlerFunc, f func(ctx context.Context, beginCall func() *tchannel.OutboundCall)) {
+	ch := testutils.NewServer(t, nil)
+	defer ch.Close()
+
+	ch.Register(tchannel.HandlerFunc(func(ctx context.Context, call *tchannel.InboundCall) {
+		req, err := ReadRequestWithContext(ctx, call)
+		if !assert.NoError(t, err, "ReadRequest failed") {
+			return
+		}
+
+		writer, finish := ResponseWriter(call.Response())
+		h(writer, req)
+		assert.NoError(t, finish(), "finish failed")
+	}), "http")
+
+	ctx, cancel := tchannel.NewContext(testutils.Timeout(time.Second))
+	defer cancel()
+
+	client := testutils.NewClient(t, nil)
+	defer client.Close()
+
+	f(ctx, func() *tchannel.OutboundCall {
+		call, err := client.BeginCall(ctx, ch.PeerInfo().HostPort, ch.ServiceName(), "http", nil)
+		require.NoError(t, err, "BeginCall failed")
+		return call
+	})
+}
+
+func TestStreamingResponse(t *testing.T) {
+	next := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "data: %v\n\n", i)
+			w.(http.Flusher).Flush()
+
+			// Wait for the client to read the event before sending the next.
+			select {
+			case <-next:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+
+	withHTTPHandler(t, handler, func(ctx context.Context, beginCall func() *tchannel.OutboundCall) {
+		req, err := http.NewRequest("GET", "/events", nil)
+		require.NoError(t, err, "NewRequest failed")
+
+		call := beginCall()
+		require.NoError(t, WriteRequest(call, req), "WriteRequest failed")
+		resp, err := ReadResponse(call.Response())
+		require.NoError(t, err, "ReadResponse failed")
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"), "Unexpected Content-Type")
+
+		buf := make([]byte, 100)
+		for i := 1; i <= 3; i++ {
+			n, err := resp.Body.Read(buf)
+			require.NoError(t, err, "Read event %v failed", i)
+			assert.Equal(t, fmt.Sprintf("data: %v\n\n", i), string(buf[:n]), "Unexpected event")
+			next <- struct{}{}
+		}
+
+		rest, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "ReadAll failed")
+		assert.Empty(t, rest, "Unexpected data after events")
+		assert.NoError(t, resp.Body.Close(), "Close failed")
+	})
+}
+
+func TestStreamingRequest(t *testing.T) {
+	received := make(chan string)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 100)
+		for {
+			n, err := r.Body.Read(buf)
+			if n > 0 {
+				received <- string(buf[:n])
+			}
+			if err == io.EOF {
+				break
+			}
+			if !assert.NoError(t, err, "Read request body failed") {
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	withHTTPHandler(t, handler, func(ctx context.Context, beginCall func() *tchannel.OutboundCall) {
+		bodyReader, bodyWriter := io.Pipe()
+		req, err := http.NewRequest("POST", "/upload", bodyReader)
+		require.NoError(t, err, "NewRequest failed")
+
+		call := beginCall()
+		writeErr := make(chan error, 1)
+		go func() { writeErr <- WriteRequest(call, req) }()
+
+		// Each write is received by the handler before the body is complete.
+		for _, chunk := range []string{"chunk1", "chunk2"} {
+			_, err := bodyWriter.Write([]byte(chunk))
+			require.NoError(t, err, "Write body failed")
+			select {
+			case got := <-received:
+				assert.Equal(t, chunk, got, "Unexpected chunk")
+			case <-ctx.Done():
+				t.Fatalf("Handler did not receive %v", chunk)
+			}
+		}
+		require.NoError(t, bodyWriter.Close(), "Close body failed")
+		require.NoError(t, <-writeErr, "WriteRequest failed")
+
+		resp, err := ReadResponse(call.Response())
+		require.NoError(t, err, "ReadResponse failed")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Unexpected status code")
+		_, err = ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "ReadAll failed")
+	})
+}
+
+func TestStreamingRequestCancelled(t *testing.T) {
+	received := make(chan string, 1)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 100)
+		n, _ := r.Body.Read(buf)
+		received <- string(buf[:n])
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	withHTTPHandler(t, handler, func(ctx context.Context, beginCall func() *tchannel.OutboundCall) {
+		reqCtx, cancel := context.WithCancel(ctx)
+		bodyReader, bodyWriter := io.Pipe()
+		req, err := http.NewRequest("POST", "/upload", bodyReader)
+		require.NoError(t, err, "NewRequest failed")
+		req = req.WithContext(reqCtx)
+
+		call := beginCall()
+		writeErr := make(chan error, 1)
+		go func() { writeErr <- WriteRequest(call, req) }()
+
+		_, err = bodyWriter.Write([]byte("chunk1"))
+		require.NoError(t, err, "Write body failed")
+		select {
+		case got := <-received:
+			assert.Equal(t, "chunk1", got, "Unexpected chunk")
+		case <-ctx.Done():
+			t.Fatal("Handler did not receive the first chunk")
+		}
+
+		// Cancel the request while WriteRequest is blocked reading the body.
+		cancel()
+		select {
+		case err := <-writeErr:
+			assert.Equal(t, tchannel.ErrRequestCancelled, err, "Unexpected WriteRequest error")
+		case <-ctx.Done():
+			t.Fatal("WriteRequest did not return after the request was cancelled")
+		}
+
+		_, err = bodyWriter.Write([]byte("chunk2"))
+		assert.Equal(t, io.ErrClosedPipe, err, "Body should be closed once the request is cancelled")
+	})
+}
+
+func TestTrailers(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.Header{"X-Req-Checksum": nil}, r.Trailer, "Trailer should be declared before the body is read")
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err, "Read request body failed")
+		assert.Equal(t, "request body", string(body), "Unexpected request body")
+		assert.Equal(t, "req-sum", r.Trailer.Get("X-Req-Checksum"), "Unexpected request trailer")
+		assert.Empty(t, r.Header.Get("Trailer"), "Trailer header should be removed")
+
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Write([]byte("response body"))
+		w.Header().Set("X-Checksum", "res-sum")
+		w.Header().Set(http.TrailerPrefix+"X-Undeclared", "extra")
+	}
+
+	withHTTPHandler(t, handler, func(ctx context.Context, beginCall func() *tchannel.OutboundCall) {
+		req, err := http.NewRequest("POST", "/trailers", strings.NewReader("request body"))
+		require.NoError(t, err, "NewRequest failed")
+		req.Trailer = http.Header{"X-Req-Checksum": {"req-sum"}}
+
+		call := beginCall()
+		require.NoError(t, WriteRequest(call, req), "WriteRequest failed")
+		resp, err := ReadResponse(call.Response())
+		require.NoError(t, err, "ReadResponse failed")
+		assert.Equal(t, http.Header{"X-Checksum": nil}, resp.Trailer, "Trailer should be declared before the body is read")
+		assert.Empty(t, resp.Header.Get("Transfer-Encoding"), "Transfer-Encoding header should be removed")
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "ReadAll failed")
+		assert.Equal(t, "response body", string(body), "Unexpected response body")
+		assert.Equal(t, http.Header{
+			"X-Checksum":   {"res-sum"},
+			"X-Undeclared": {"extra"},
+		}, resp.Trailer, "Unexpected response trailers")
+		assert.NoError(t, resp.Body.Close(), "Close failed")
+	})
+}
+
+func TestRequestContext(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.NotNil(t, tchannel.CurrentCall(r.Context()), "Request context should be the call's context")
+		_, ok := r.Context().Deadline()
+		assert.True(t, ok, "Request context should have the call's deadline")
+	}
+
+	withHTTPHandler(t, handler, func(ctx context.Context, beginCall func() *tchannel.OutboundCall) {
+		req, err := http.NewRequest("GET", "/", nil)
+		require.NoError(t, err, "NewRequest failed")
+
+		call := beginCall()
+		require.NoError(t, WriteRequest(call, req), "WriteRequest failed")
+		resp, err := ReadResponse(call.Response())
+		require.NoError(t, err, "ReadResponse failed")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Unexpected status code")
+		_, err = ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "ReadAll failed")
+	})
+}
@@ -0,0 +1,383 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/net/context"
+)
+
+// defaultForwardTimeout is the timeout used by the Forwarder for requests
+// that don't have a deadline.
+const defaultForwardTimeout = 30 * time.Second
+
+// hopHeaders are the hop-by-hop headers that are removed when forwarding
+// requests and responses between HTTP and TChannel.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// ForwarderOptions are options used to create a Forwarder.
+type ForwarderOptions struct {
+	// Timeout is used for requests that don't have a deadline.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// Tracer is used to extract the tracing context from incoming HTTP
+	// requests. Defaults to the subchannel's tracer.
+	Tracer opentracing.Tracer
+}
+
+// Forwarder is a http.Handler that forwards incoming HTTP requests to a
+// TChannel method using the http arg scheme.
+type Forwarder struct {
+	subChannel *tchannel.SubChannel
+	method     string
+	timeout    time.Duration
+	tracer     opentracing.Tracer
+}
+
+// NewForwarder returns a Forwarder that forwards requests to the given method
+// of the subchannel's service. The request deadline, or the configured timeout,
+// is used as the TChannel timeout, and cancelling the request cancels the call.
+//
+// The tracing context is extracted from the HTTP headers using the tracer, and
+// the OpenTelemetry global propagator, and is propagated to the TChannel call.
+func NewForwarder(subChannel *tchannel.SubChannel, method string, opts *ForwarderOptions) *Forwarder {
+	if opts == nil {
+		opts = &ForwarderOptions{}
+	}
+	f := &Forwarder{
+		subChannel: subChannel,
+		method:     method,
+		timeout:    opts.Timeout,
+		tracer:     opts.Tracer,
+	}
+	if f.timeout == 0 {
+		f.timeout = defaultForwardTimeout
+	}
+	if f.tracer == nil {
+		f.tracer = subChannel.Tracer()
+	}
+	return f
+}
+
+// ServeHTTP implements http.Handler.
+func (f *Forwarder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	timeout := f.timeout
+	if deadline, ok := r.Context().Deadline(); ok {
+		timeout = time.Until(deadline)
+		if timeout <= 0 {
+			http.Error(w, "request deadline exceeded", http.StatusGatewayTimeout)
+			return
+		}
+	}
+	ctx, cancel := tchannel.NewContextBuilder(timeout).SetParentContext(r.Context()).Build()
+	defer cancel()
+
+	parent, _ := f.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+	span := f.tracer.StartSpan(f.method, ext.RPCServerOption(parent))
+	ext.HTTPMethod.Set(span, r.Method)
+	ext.HTTPUrl.Set(span, r.URL.String())
+	defer span.Finish()
+
+	var callCtx context.Context = opentracing.ContextWithSpan(ctx, span)
+	callCtx = otel.GetTextMapPropagator().Extract(callCtx, propagation.HeaderCarrier(r.Header))
+
+	// The request is copied so that the trailers read from the body are
+	// still visible when the body is forwarded.
+	req := r.WithContext(callCtx)
+	req.Header = forwardHeader(r.Header)
+	f.tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+
+	call, err := f.subChannel.BeginCall(callCtx, f.method, &tchannel.CallOptions{Format: tchannel.HTTP})
+	if err != nil {
+		f.writeError(w, span, err)
+		return
+	}
+	if err := WriteRequest(call, req); err != nil {
+		f.writeError(w, span, err)
+		return
+	}
+
+	resp, err := ReadResponse(call.Response())
+	if err != nil {
+		f.writeError(w, span, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+	if err := copyResponse(w, resp); err != nil {
+		ext.Error.Set(span, true)
+	}
+}
+
+// writeError writes the HTTP status that matches the TChannel error.
+func (f *Forwarder) writeError(w http.ResponseWriter, span opentracing.Span, err error) {
+	status := errorStatus(err)
+	ext.Error.Set(span, true)
+	ext.HTTPStatusCode.Set(span, uint16(status))
+	http.Error(w, err.Error(), status)
+}
+
+// errorStatus returns the HTTP status code for a TChannel error.
+func errorStatus(err error) int {
+	switch tchannel.GetSystemErrorCode(err) {
+	case tchannel.ErrCodeTimeout:
+		return http.StatusGatewayTimeout
+	case tchannel.ErrCodeBusy, tchannel.ErrCodeDeclined:
+		return http.StatusServiceUnavailable
+	case tchannel.ErrCodeBadRequest:
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// ProxyOptions are options used to create a Proxy.
+type ProxyOptions struct {
+	// Transport is used to make the upstream HTTP requests.
+	// Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Tracer is used to continue the trace from the HTTP headers if the
+	// call did not have a span. Defaults to the global tracer.
+	Tracer opentracing.Tracer
+
+	// Logger is used to log errors writing the response to the call, once
+	// it is too late to send an error to the caller. Defaults to a no-op logger.
+	Logger tchannel.Logger
+}
+
+// Proxy is a tchannel.Handler that proxies calls using the http arg scheme
+// to an upstream HTTP server.
+type Proxy struct {
+	upstream  *url.URL
+	transport http.RoundTripper
+	tracer    opentracing.Tracer
+	logger    tchannel.Logger
+}
+
+// NewProxy returns a Proxy that sends requests to the upstream URL. The scheme
+// and host of the upstream are used for all requests, and its path is used as
+// a prefix for the request path.
+//
+// The call's deadline and cancellation apply to the upstream request, and the
+// call's tracing context is injected into the upstream request's headers
+// using the tracer, and the OpenTelemetry global propagator.
+func NewProxy(upstream *url.URL, opts *ProxyOptions) *Proxy {
+	if opts == nil {
+		opts = &ProxyOptions{}
+	}
+	p := &Proxy{
+		upstream:  upstream,
+		transport: opts.Transport,
+		tracer:    opts.Tracer,
+		logger:    opts.Logger,
+	}
+	if p.transport == nil {
+		p.transport = http.DefaultTransport
+	}
+	if p.tracer == nil {
+		p.tracer = opentracing.GlobalTracer()
+	}
+	if p.logger == nil {
+		p.logger = tchannel.NullLogger
+	}
+	return p
+}
+
+// Handle implements tchannel.Handler.
+func (p *Proxy) Handle(ctx context.Context, call *tchannel.InboundCall) {
+	r, err := ReadRequestWithContext(ctx, call)
+	if err != nil {
+		call.Response().SendSystemError(tchannel.NewSystemError(tchannel.ErrCodeBadRequest, "invalid HTTP request: %v", err))
+		return
+	}
+
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		parent, _ := p.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+		span = p.tracer.StartSpan(call.MethodString(), ext.RPCServerOption(parent))
+		defer span.Finish()
+		ctx = opentracing.ContextWithSpan(ctx, span)
+	}
+
+	req := r.WithContext(ctx)
+	req.Header = forwardHeader(r.Header)
+	req.URL.Scheme = p.upstream.Scheme
+	req.URL.Host = p.upstream.Host
+	req.URL.Path = joinPath(p.upstream.Path, req.URL.Path)
+	req.URL.RawPath = ""
+	if p.upstream.RawQuery != "" {
+		req.URL.RawQuery = joinQuery(p.upstream.RawQuery, req.URL.RawQuery)
+	}
+	req.Host = ""
+	p.tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := p.transport.RoundTrip(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			call.Response().SendSystemError(tchannel.ErrTimeout)
+			return
+		}
+
+		w := newTChanResponseWriter(call.Response())
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		p.finish(call, w)
+		return
+	}
+	defer resp.Body.Close()
+
+	w := newTChanResponseWriter(call.Response())
+	if err := copyResponse(w, resp); err != nil {
+		if !w.wroteHeaders {
+			if ctx.Err() == context.DeadlineExceeded {
+				call.Response().SendSystemError(tchannel.ErrTimeout)
+			} else {
+				call.Response().SendSystemError(tchannel.NewSystemError(tchannel.ErrCodeNetwork, "failed to read upstream HTTP response: %v", err))
+			}
+			return
+		}
+		p.logger.WithFields(
+			tchannel.LogField{Key: "method", Value: call.MethodString()},
+			tchannel.ErrField(err),
+		).Warn("Failed to copy upstream HTTP response.")
+	}
+	p.finish(call, w)
+}
+
+// finish completes the response, logging any error since the response may
+// already be partially written.
+func (p *Proxy) finish(call *tchannel.InboundCall, w *tchanResponseWriter) {
+	if err := w.finish(); err != nil {
+		p.logger.WithFields(
+			tchannel.LogField{Key: "method", Value: call.MethodString()},
+			tchannel.ErrField(err),
+		).Warn("Failed to finish HTTP response.")
+	}
+}
+
+// forwardHeader returns a copy of header without the hop-by-hop headers.
+func forwardHeader(header http.Header) http.Header {
+	h := header.Clone()
+	if h == nil {
+		h = make(http.Header)
+	}
+	for _, v := range h["Connection"] {
+		for _, k := range strings.Split(v, ",") {
+			h.Del(strings.TrimSpace(k))
+		}
+	}
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+	return h
+}
+
+// copyResponse writes resp to w, flushing the body as it is read so that
+// streamed responses are forwarded as they arrive, and sets any trailers
+// once the body has been read.
+func copyResponse(w http.ResponseWriter, resp *http.Response) error {
+	header := w.Header()
+	for k, vs := range forwardHeader(resp.Header) {
+		header[k] = vs
+	}
+	if len(resp.Trailer) > 0 {
+		keys := make([]string, 0, len(resp.Trailer))
+		for k := range resp.Trailer {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		header.Set(trailerHeader, strings.Join(keys, ", "))
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	err := copyFlush(w, resp.Body)
+	for k, vs := range resp.Trailer {
+		header[k] = vs
+	}
+	return err
+}
+
+// copyFlush copies body to w, flushing after every write if w is a
+// http.Flusher.
+func copyFlush(w io.Writer, body io.Reader) error {
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, copyBufferSize)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// joinPath joins the upstream path prefix with the request path.
+func joinPath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "":
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// joinQuery joins the upstream query with the request query.
+func joinQuery(upstream, query string) string {
+	if query == "" {
+		return upstream
+	}
+	return upstream + "&" + query
+}
@@ -0,0 +1,330 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withForwarder sets up an HTTP server that forwards requests to a TChannel
+// server, which proxies them to the upstream handler, and calls f with the
+// URL of the HTTP server.
+func withForwarder(t *testing.T, upstream http.HandlerFunc, opts *ForwarderOptions, f func(url string)) {
+	upstreamServer := httptest.NewServer(upstream)
+	defer upstreamServer.Close()
+
+	upstreamURL, err := url.Parse(upstreamServer.URL + "/base")
+	require.NoError(t, err, "Failed to parse upstream URL")
+
+	var tracer opentracing.Tracer
+	if opts != nil {
+		tracer = opts.Tracer
+	}
+
+	serverOpts := testutils.NewOpts()
+	serverOpts.Tracer = tracer
+	ch := testutils.NewServer(t, serverOpts)
+	defer ch.Close()
+	ch.Register(NewProxy(upstreamURL, &ProxyOptions{Tracer: tracer}), "http")
+
+	clientOpts := testutils.NewOpts()
+	clientOpts.Tracer = tracer
+	client := testutils.NewClient(t, clientOpts)
+	defer client.Close()
+
+	sc := client.GetSubChannel(ch.ServiceName())
+	sc.Peers().Add(ch.PeerInfo().HostPort)
+
+	frontend := httptest.NewServer(NewForwarder(sc, "http", opts))
+	defer frontend.Close()
+
+	f(frontend.URL)
+}
+
+func TestForwarderProxy(t *testing.T) {
+	upstream := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/base/echo", r.URL.Path, "Unexpected path")
+		assert.Equal(t, "q=1", r.URL.RawQuery, "Unexpected query")
+		assert.Equal(t, "v1", r.Header.Get("X-Custom"), "Missing custom header")
+		assert.Empty(t, r.Header.Get("X-Hop"), "Hop-by-hop header should be removed")
+
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err, "Failed to read request body")
+		assert.Equal(t, "t1", r.Trailer.Get("X-Req-Trailer"), "Missing request trailer")
+
+		w.Header().Set("X-Response", "v2")
+		w.Header().Set("Trailer", "X-Resp-Trailer")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "echo: ")
+		w.Write(body)
+		w.Header().Set("X-Resp-Trailer", "t2")
+	}
+
+	withForwarder(t, upstream, nil, func(url string) {
+		req, err := http.NewRequest("POST", url+"/echo?q=1", ioutil.NopCloser(strings.NewReader("hello")))
+		require.NoError(t, err, "NewRequest failed")
+		req.Header.Set("X-Custom", "v1")
+		req.Header.Set("Connection", "X-Hop")
+		req.Header.Set("X-Hop", "hop")
+		req.Trailer = http.Header{"X-Req-Trailer": {"t1"}}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err, "HTTP request failed")
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "Failed to read response body")
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, "Unexpected status")
+		assert.Equal(t, "v2", resp.Header.Get("X-Response"), "Missing response header")
+		assert.Equal(t, "echo: hello", string(body), "Unexpected body")
+		assert.Equal(t, "t2", resp.Trailer.Get("X-Resp-Trailer"), "Missing response trailer")
+	})
+}
+
+func TestForwarderStreaming(t *testing.T) {
+	next := make(chan struct{})
+	upstream := func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 3; i++ {
+			io.WriteString(w, "event\n")
+			w.(http.Flusher).Flush()
+			<-next
+		}
+	}
+
+	withForwarder(t, upstream, nil, func(url string) {
+		resp, err := http.Get(url)
+		require.NoError(t, err, "HTTP request failed")
+		defer resp.Body.Close()
+
+		buf := make([]byte, len("event\n"))
+		for i := 0; i < 3; i++ {
+			_, err := io.ReadFull(resp.Body, buf)
+			require.NoError(t, err, "Read event %v failed", i)
+			assert.Equal(t, "event\n", string(buf), "Unexpected event")
+			next <- struct{}{}
+		}
+	})
+}
+
+func TestForwarderErrors(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	upstream := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}
+
+	opts := &ForwarderOptions{Timeout: testutils.Timeout(50 * time.Millisecond)}
+	withForwarder(t, upstream, opts, func(url string) {
+		resp, err := http.Get(url)
+		require.NoError(t, err, "HTTP request failed")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode, "Timeout should map to gateway timeout")
+	})
+}
+
+func TestForwarderUnknownMethod(t *testing.T) {
+	ch := testutils.NewServer(t, testutils.NewOpts().AddLogFilter("Couldn't find handler.", 1))
+	defer ch.Close()
+
+	client := testutils.NewClient(t, nil)
+	defer client.Close()
+	sc := client.GetSubChannel(ch.ServiceName())
+	sc.Peers().Add(ch.PeerInfo().HostPort)
+
+	frontend := httptest.NewServer(NewForwarder(sc, "unknown", nil))
+	defer frontend.Close()
+
+	resp, err := http.Get(frontend.URL)
+	require.NoError(t, err, "HTTP request failed")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Unknown method should map to bad request")
+}
+
+func TestForwarderExpiredDeadline(t *testing.T) {
+	client := testutils.NewClient(t, nil)
+	defer client.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	recorder := httptest.NewRecorder()
+	NewForwarder(client.GetSubChannel("svc"), "http", nil).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusGatewayTimeout, recorder.Code, "Expired request should map to gateway timeout")
+}
+
+func TestProxyUpstreamError(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err, "Failed to parse upstream URL")
+	upstream.Close()
+
+	withHTTPProxy(t, upstreamURL, nil, func(call *tchannel.OutboundCall) {
+		req, err := http.NewRequest("GET", "/", nil)
+		require.NoError(t, err, "NewRequest failed")
+		require.NoError(t, WriteRequest(call, req), "WriteRequest failed")
+
+		resp, err := ReadResponse(call.Response())
+		require.NoError(t, err, "ReadResponse failed")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode, "Upstream error should map to bad gateway")
+	})
+}
+
+// truncatedUpstream returns the URL of an upstream server that responds with
+// the given body, but closes the connection before the promised body length.
+func truncatedUpstream(t *testing.T, body string) (*url.URL, func()) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err, "Hijack failed")
+		defer conn.Close()
+
+		fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Length: %v\r\n\r\n%v", len(body)+10, body)
+		buf.Flush()
+	}))
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err, "Failed to parse upstream URL")
+	return upstreamURL, upstream.Close
+}
+
+func TestProxyUpstreamBodyError(t *testing.T) {
+	upstreamURL, closeUpstream := truncatedUpstream(t, "")
+	defer closeUpstream()
+
+	withHTTPProxy(t, upstreamURL, nil, func(call *tchannel.OutboundCall) {
+		req, err := http.NewRequest("GET", "/", nil)
+		require.NoError(t, err, "NewRequest failed")
+		require.NoError(t, WriteRequest(call, req), "WriteRequest failed")
+
+		_, err = ReadResponse(call.Response())
+		require.Error(t, err, "ReadResponse should fail when the upstream body can't be read")
+		assert.Equal(t, tchannel.ErrCodeNetwork, tchannel.GetSystemErrorCode(err), "Unexpected error code")
+	})
+}
+
+func TestProxyUpstreamBodyErrorAfterWrite(t *testing.T) {
+	upstreamURL, closeUpstream := truncatedUpstream(t, "partial")
+	defer closeUpstream()
+
+	var logs lockedBuffer
+	opts := &ProxyOptions{Logger: tchannel.NewLogger(&logs)}
+	withHTTPProxy(t, upstreamURL, opts, func(call *tchannel.OutboundCall) {
+		req, err := http.NewRequest("GET", "/", nil)
+		require.NoError(t, err, "NewRequest failed")
+		require.NoError(t, WriteRequest(call, req), "WriteRequest failed")
+
+		resp, err := ReadResponse(call.Response())
+		require.NoError(t, err, "ReadResponse failed")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Unexpected status code")
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "Read body failed")
+		assert.Equal(t, "partial", string(body), "Unexpected body")
+	})
+
+	assert.True(t, testutils.WaitFor(time.Second, func() bool {
+		return strings.Contains(logs.String(), "Failed to copy upstream HTTP response.")
+	}), "Upstream body error was not logged, logs: %v", logs.String())
+}
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use.
+type lockedBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+// withHTTPProxy registers a Proxy for upstream, and calls f with a call to it.
+func withHTTPProxy(t *testing.T, upstream *url.URL, opts *ProxyOptions, f func(call *tchannel.OutboundCall)) {
+	ch := testutils.NewServer(t, nil)
+	defer ch.Close()
+	ch.Register(NewProxy(upstream, opts), "http")
+
+	client := testutils.NewClient(t, nil)
+	defer client.Close()
+
+	ctx, cancel := tchannel.NewContext(testutils.Timeout(time.Second))
+	defer cancel()
+
+	call, err := client.BeginCall(ctx, ch.PeerInfo().HostPort, ch.ServiceName(), "http", &tchannel.CallOptions{Format: tchannel.HTTP})
+	require.NoError(t, err, "BeginCall failed")
+	f(call)
+}
+
+func TestForwarderTracing(t *testing.T) {
+	tracer := mocktracer.New()
+
+	var upstreamSpan opentracing.SpanContext
+	upstream := func(w http.ResponseWriter, r *http.Request) {
+		sc, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+		assert.NoError(t, err, "Upstream request is missing tracing headers")
+		upstreamSpan = sc
+	}
+
+	root := tracer.StartSpan("root")
+	withForwarder(t, upstream, &ForwarderOptions{Tracer: tracer}, func(url string) {
+		req, err := http.NewRequest("GET", url, nil)
+		require.NoError(t, err, "NewRequest failed")
+		require.NoError(t, tracer.Inject(root.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header)))
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err, "HTTP request failed")
+		resp.Body.Close()
+	})
+
+	require.NotNil(t, upstreamSpan, "Upstream did not receive a span")
+	assert.Equal(t, root.Context().(mocktracer.MockSpanContext).TraceID,
+		upstreamSpan.(mocktracer.MockSpanContext).TraceID, "Trace should continue through the bridge")
+}
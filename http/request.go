@@ -21,20 +21,26 @@
 package http
 
 import (
-	"io"
 	"net/http"
 
 	"github.com/temporalio/tchannel-go"
 	"github.com/temporalio/tchannel-go/typed"
+
+	"golang.org/x/net/context"
 )
 
-// WriteRequest writes a http.Request to the given writers.
+// WriteRequest writes a http.Request to the given writers. The body is sent
+// in arg3 fragments as it is read, so it's streamed to the peer. Trailers in
+// req.Trailer are sent after the body, so they may be set while the body is read.
+// If req's context is done before the body is sent, the body is closed and the
+// context's error is returned. The call should then be abandoned by cancelling
+// its context, which is done automatically if req's context is the call's context.
 func WriteRequest(call tchannel.ArgWritable, req *http.Request) error {
 	// TODO(prashant): Allow creating write buffers that let you grow the buffer underneath.
 	wb := typed.NewWriteBufferWithSize(10000)
 	wb.WriteLen8String(req.Method)
 	writeVarintString(wb, req.URL.String())
-	writeHeaders(wb, req.Header)
+	writeHeaders(wb, withTrailers(req.Header, req.Trailer))
 
 	arg2Writer, err := call.Arg2Writer()
 	if err != nil {
@@ -52,15 +58,28 @@ func WriteRequest(call tchannel.ArgWritable, req *http.Request) error {
 		return err
 	}
 
+	body := newBodyWriter(arg3Writer, len(req.Trailer) > 0)
 	if req.Body != nil {
-		if _, err = io.Copy(arg3Writer, req.Body); err != nil {
+		ctx := req.Context()
+		stop := closeOnDone(ctx, req.Body)
+		defer stop()
+
+		// Start the call before reading a body of unknown length, since the
+		// first read may block until the caller produces data.
+		if req.ContentLength <= 0 {
+			if err := body.Flush(); err != nil {
+				return err
+			}
+		}
+		if err := body.copyFrom(ctx, req.Body); err != nil {
 			return err
 		}
 	}
-	return arg3Writer.Close()
+	return body.close(req.Trailer)
 }
 
-// ReadRequest reads a http.Request from the given readers.
+// ReadRequest reads a http.Request from the given readers. The body is read from
+// arg3 as fragments are received, and r.Trailer is set once the body is read.
 func ReadRequest(call tchannel.ArgReadable) (*http.Request, error) {
 	var arg2 []byte
 	if err := tchannel.NewArgReader(call.Arg2Reader()).Read(&arg2); err != nil {
@@ -80,6 +99,22 @@ func ReadRequest(call tchannel.ArgReadable) (*http.Request, error) {
 		return nil, err
 	}
 
-	r.Body, err = call.Arg3Reader()
-	return r, err
+	arg3Reader, err := call.Arg3Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body, r.Trailer = readBody(arg3Reader, r.Header)
+	return r, nil
+}
+
+// ReadRequestWithContext reads a http.Request like ReadRequest, and sets the
+// request's context to ctx, which should be the context of the call, so that
+// handlers stop when the call is cancelled or times out.
+func ReadRequestWithContext(ctx context.Context, call tchannel.ArgReadable) (*http.Request, error) {
+	r, err := ReadRequest(call)
+	if err != nil {
+		return nil, err
+	}
+	return r.WithContext(ctx), nil
 }
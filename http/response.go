@@ -22,14 +22,16 @@ package http
 
 import (
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 
 	"github.com/temporalio/tchannel-go"
 	"github.com/temporalio/tchannel-go/typed"
 )
 
-// ReadResponse reads a http.Response from the given readers.
+// ReadResponse reads a http.Response from the given readers. The body is read
+// from arg3 as fragments are received, and response.Trailer is set once the
+// body is read.
 func ReadResponse(call tchannel.ArgReadable) (*http.Response, error) {
 	var arg2 []byte
 	if err := tchannel.NewArgReader(call.Arg2Reader()).Read(&arg2); err != nil {
@@ -58,7 +60,7 @@ func ReadResponse(call tchannel.ArgReadable) (*http.Response, error) {
 		return nil, err
 	}
 
-	response.Body = arg3Reader
+	response.Body, response.Trailer = readBody(arg3Reader, response.Header)
 	return response, nil
 }
 
@@ -66,8 +68,16 @@ type tchanResponseWriter struct {
 	headers    http.Header
 	statusCode int
 	response   tchannel.ArgWritable
-	arg3Writer io.WriteCloser
+	body       *bodyWriter
 	err        error
+
+	// wroteHeaders is set once writing the headers has been attempted, after
+	// which the response can no longer be replaced with an error.
+	wroteHeaders bool
+
+	// trailers are the trailer keys declared in the Trailer header when the
+	// headers were written.
+	trailers []string
 }
 
 func newTChanResponseWriter(response tchannel.ArgWritable) *tchanResponseWriter {
@@ -88,11 +98,18 @@ func (w *tchanResponseWriter) WriteHeader(statusCode int) {
 
 // writeHeaders writes out the HTTP headers as arg2, and creates the arg3 writer.
 func (w *tchanResponseWriter) writeHeaders() {
+	w.wroteHeaders = true
+	headers := w.headers
+	if w.trailers = trailerKeys(w.headers); len(w.trailers) > 0 {
+		headers = w.headers.Clone()
+		headers.Set(transferEncodingHeader, chunkedEncoding)
+	}
+
 	// TODO(prashant): Allow creating write buffers that let you grow the buffer underneath.
 	wb := typed.NewWriteBufferWithSize(10000)
 	wb.WriteUint16(uint16(w.statusCode))
 	writeVarintString(wb, http.StatusText(w.statusCode))
-	writeHeaders(wb, w.headers)
+	writeHeaders(wb, headers)
 
 	arg2Writer, err := w.response.Arg2Writer()
 	if err != nil {
@@ -106,7 +123,12 @@ func (w *tchanResponseWriter) writeHeaders() {
 		return
 	}
 
-	w.arg3Writer, w.err = w.response.Arg3Writer()
+	arg3Writer, err := w.response.Arg3Writer()
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.body = newBodyWriter(arg3Writer, len(w.trailers) > 0)
 }
 
 func (w *tchanResponseWriter) Write(bs []byte) (int, error) {
@@ -114,25 +136,61 @@ func (w *tchanResponseWriter) Write(bs []byte) (int, error) {
 		return 0, w.err
 	}
 
-	if w.arg3Writer == nil {
+	if w.body == nil {
 		w.writeHeaders()
 	}
 	if w.err != nil {
 		return 0, w.err
 	}
 
-	return w.arg3Writer.Write(bs)
+	return w.body.Write(bs)
+}
+
+// Flush sends any buffered data to the client as an arg3 fragment, writing
+// the headers first if they have not been written.
+func (w *tchanResponseWriter) Flush() {
+	if w.body == nil && w.err == nil {
+		w.writeHeaders()
+	}
+	if w.err != nil {
+		return
+	}
+	w.err = w.body.Flush()
+}
+
+// trailer returns the trailers to send after the body, which are the declared
+// trailers, and any headers set with the http.TrailerPrefix.
+func (w *tchanResponseWriter) trailer() http.Header {
+	trailer := make(http.Header)
+	for _, k := range w.trailers {
+		if vs, ok := w.headers[k]; ok {
+			trailer[k] = vs
+		}
+	}
+	for k, vs := range w.headers {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			trailer[strings.TrimPrefix(k, http.TrailerPrefix)] = vs
+		}
+	}
+	return trailer
 }
 
 func (w *tchanResponseWriter) finish() error {
-	if w.arg3Writer == nil || w.err != nil {
+	if w.body == nil && w.err == nil {
+		w.writeHeaders()
+	}
+	if w.err != nil {
 		return w.err
 	}
-	return w.arg3Writer.Close()
+	return w.body.close(w.trailer())
 }
 
 // ResponseWriter returns a http.ResponseWriter that will write to an underlying writer.
 // It also returns a function that should be called once the handler has completed.
+// The body is sent in arg3 fragments as they fill up, and the writer implements
+// http.Flusher to send written data immediately. Trailers are supported using
+// the Trailer header or http.TrailerPrefix, but trailers set with the prefix are
+// only sent if the Trailer header declared at least one trailer.
 func ResponseWriter(response tchannel.ArgWritable) (http.ResponseWriter, func() error) {
 	responseWriter := newTChanResponseWriter(response)
 	return responseWriter, responseWriter.finish
@@ -10,6 +10,48 @@ import (
 	"github.com/temporalio/tchannel-go/thrift"
 )
 
+// thriftIDL is the Thrift IDL that this package was generated from.
+var thriftIDL = thrift.IDL{
+	EntryPoint: "hyperbahn.thrift",
+	Files: map[string]string{
+		"hyperbahn.thrift": "exception NoPeersAvailable {\n" +
+			"    1: required string message\n" +
+			"    2: required string serviceName\n" +
+			"}\n" +
+			"\n" +
+			"exception InvalidServiceName {\n" +
+			"    1: required string message\n" +
+			"    2: required string serviceName\n" +
+			"}\n" +
+			"\n" +
+			"struct DiscoveryQuery {\n" +
+			"    1: required string serviceName\n" +
+			"}\n" +
+			"\n" +
+			"union IpAddress {\n" +
+			"  1: i32 ipv4\n" +
+			"}\n" +
+			"\n" +
+			"struct ServicePeer {\n" +
+			"  1: required IpAddress ip\n" +
+			"  2: required i32 port\n" +
+			"}\n" +
+			"\n" +
+			"struct DiscoveryResult {\n" +
+			"  1: required list<ServicePeer> peers\n" +
+			"}\n" +
+			"\n" +
+			"service Hyperbahn {\n" +
+			"    DiscoveryResult discover(\n" +
+			"        1: required DiscoveryQuery query\n" +
+			"    ) throws (\n" +
+			"        1: NoPeersAvailable noPeersAvailable\n" +
+			"        2: InvalidServiceName invalidServiceName\n" +
+			"    )\n" +
+			"}",
+	},
+}
+
 // Interfaces for the service and client for the services defined in the IDL.
 
 // TChanHyperbahn is the interface that defines the server handler and client interface.
@@ -72,6 +114,11 @@ func (s *tchanHyperbahnServer) Service() string {
 	return "Hyperbahn"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanHyperbahnServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanHyperbahnServer) Methods() []string {
 	return []string{
 		"discover",
@@ -88,35 +135,79 @@ func (s *tchanHyperbahnServer) Handle(ctx thrift.Context, methodName string, pro
 	}
 }
 
+func (s *tchanHyperbahnServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "discover":
+		var req HyperbahnDiscoverArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanHyperbahnServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "discover":
+		return s.handleDiscoverArgs(ctx, args.(*HyperbahnDiscoverArgs))
+
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanHyperbahnServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	case "discover":
+		var res HyperbahnDiscoverResult
+		return s.handleDiscoverError(&res, err)
+
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanHyperbahnServer) handleDiscover(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req HyperbahnDiscoverArgs
-	var res HyperbahnDiscoverResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleDiscoverArgs(ctx, &req)
+}
+
+func (s *tchanHyperbahnServer) handleDiscoverArgs(ctx thrift.Context, req *HyperbahnDiscoverArgs) (bool, athrift.TStruct, error) {
+	var res HyperbahnDiscoverResult
+
 	r, err :=
 		s.handler.Discover(ctx, req.Query)
 
 	if err != nil {
-		switch v := err.(type) {
-		case *NoPeersAvailable:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for noPeersAvailable returned non-nil error type *NoPeersAvailable but nil value")
-			}
-			res.NoPeersAvailable = v
-		case *InvalidServiceName:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for invalidServiceName returned non-nil error type *InvalidServiceName but nil value")
-			}
-			res.InvalidServiceName = v
-		default:
-			return false, nil, err
-		}
-	} else {
-		res.Success = r
+		return s.handleDiscoverError(&res, err)
 	}
 
-	return err == nil, &res, nil
+	res.Success = r
+	return true, &res, nil
+}
+
+func (s *tchanHyperbahnServer) handleDiscoverError(res *HyperbahnDiscoverResult, err error) (bool, athrift.TStruct, error) {
+	switch v := err.(type) {
+	case *NoPeersAvailable:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for noPeersAvailable returned non-nil error type *NoPeersAvailable but nil value")
+		}
+		res.NoPeersAvailable = v
+	case *InvalidServiceName:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for invalidServiceName returned non-nil error type *InvalidServiceName but nil value")
+		}
+		res.InvalidServiceName = v
+	default:
+		return false, nil, err
+	}
+	return false, res, nil
 }
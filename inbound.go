@@ -27,6 +27,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
 )
 
@@ -109,6 +110,9 @@ func (c *Connection) handleCallReq(frame *Frame) bool {
 
 	call.mex = mex
 	call.initialFragment = initialFragment
+	call.bytesRead = uint64(frame.Header.FrameSize())
+	call.timeToLive = callReq.TimeToLive
+	call.tracing = callReq.Tracing
 	call.serviceName = string(callReq.Service)
 	call.headers = callReq.Headers
 	call.response = response
@@ -191,16 +195,23 @@ func (c *Connection) dispatchInbound(_ uint32, _ uint32, call *InboundCall, fram
 		}
 	}()
 
+	ctx := call.mex.ctx
+	if c.otel != nil && !usesApplicationHeaders(call.Format()) {
+		// Calls with application headers start their span in ExtractInboundSpan,
+		// so the trace context in the headers is used.
+		ctx, call.response.otelSpan = c.startInboundOtelSpan(ctx, call, call.methodString)
+	}
+
 	// Internal handlers (e.g., introspection) trump all other user-registered handlers on
 	// the "tchannel" name.
 	if call.ServiceName() == "tchannel" {
 		if h := c.internalHandlers.find(call.Method()); h != nil {
-			h.Handle(call.mex.ctx, call)
+			h.Handle(ctx, call)
 			return
 		}
 	}
 
-	c.handler.Handle(call.mex.ctx, call)
+	c.handler.Handle(ctx, call)
 }
 
 // An InboundCall is an incoming call from a peer
@@ -213,6 +224,8 @@ type InboundCall struct {
 	method          []byte
 	methodString    string
 	headers         transportHeaders
+	timeToLive      time.Duration
+	tracing         Span
 	statsReporter   StatsReporter
 	commonStatsTags map[string]string
 }
@@ -257,6 +270,11 @@ func (call *InboundCall) RoutingDelegate() string {
 	return call.headers[RoutingDelegate]
 }
 
+// ThriftProtocol returns the Thrift protocol from the ThriftProtocol transport header.
+func (call *InboundCall) ThriftProtocol() string {
+	return call.headers[ThriftProtocol]
+}
+
 // LocalPeer returns the local peer information for this call.
 func (call *InboundCall) LocalPeer() LocalPeerInfo {
 	return call.conn.localPeerInfo
@@ -275,6 +293,7 @@ func (call *InboundCall) CallOptions() *CallOptions {
 		ShardKey:        call.ShardKey(),
 		RoutingDelegate: call.RoutingDelegate(),
 		RoutingKey:      call.RoutingKey(),
+		ThriftProtocol:  call.ThriftProtocol(),
 	}
 }
 
@@ -328,8 +347,10 @@ type InboundCallResponse struct {
 	timeNow          func() time.Time
 	applicationError bool
 	systemError      bool
+	systemErrCode    SystemErrCode
 	headers          transportHeaders
 	span             opentracing.Span
+	otelSpan         trace.Span
 	statsReporter    StatsReporter
 	commonStatsTags  map[string]string
 }
@@ -343,10 +364,15 @@ func (response *InboundCallResponse) SendSystemError(err error) error {
 	// Fail all future attempts to read fragments
 	response.state = reqResWriterComplete
 	response.systemError = true
+	response.systemErrCode = GetSystemErrorCode(err)
 	response.doneSending()
 	response.call.releasePreviousFragment()
 
 	span := CurrentSpan(response.mex.ctx)
+	if otelSpan := response.otelSpan; otelSpan != nil {
+		s := spanFromOtel(otelSpan.SpanContext(), trace.SpanContext{})
+		span = &s
+	}
 
 	return response.conn.SendSystemError(response.mex.msgID, *span, err)
 }
@@ -398,9 +424,14 @@ func (response *InboundCallResponse) doneSending() {
 		}
 		span.FinishWithOptions(opentracing.FinishOptions{FinishTime: now})
 	}
+	if span := response.otelSpan; span != nil {
+		endOtelSpan(span, response.applicationError || response.systemError, nil, now)
+	}
 
 	latency := now.Sub(response.calledAt)
-	response.statsReporter.RecordTimer("inbound.calls.latency", response.commonStatsTags, latency)
+	recordLatency(response.statsReporter, "inbound.calls.latency", response.commonStatsTags, latency)
+	recordSize(response.statsReporter, "inbound.calls.request-bytes", response.commonStatsTags, response.call.bytesRead)
+	recordSize(response.statsReporter, "inbound.calls.response-bytes", response.commonStatsTags, response.bytesWritten.Load())
 
 	if response.systemError {
 		// TODO(prashant): Report the error code type as per metrics doc and enable.
@@ -411,6 +442,9 @@ func (response *InboundCallResponse) doneSending() {
 		response.statsReporter.IncCounter("inbound.calls.success", response.commonStatsTags, 1)
 	}
 
+	response.logAccess(now)
+	response.recordCallSummary(now)
+
 	// Cancel the context since the response is complete.
 	response.cancel()
 
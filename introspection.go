@@ -84,6 +84,9 @@ type RuntimeState struct {
 	// and hence are not reported as part of root peers.
 	InactiveConnections []ConnectionRuntimeState `json:"inactiveConnections"`
 
+	// CallSummaries contains summaries of recent calls, if enabled.
+	CallSummaries []CallSummary `json:"callSummaries,omitempty"`
+
 	// OtherChannels is information about any other channels running in this process.
 	OtherChannels map[string][]ChannelInfo `json:"otherChannels,omitEmpty"`
 
@@ -242,6 +245,7 @@ func (ch *Channel) IntrospectState(opts *IntrospectionOptions) *RuntimeState {
 		NumConnections:      numConns,
 		Connections:         connIDs,
 		InactiveConnections: getConnectionRuntimeState(inactiveConns, opts),
+		CallSummaries:       ch.CallSummaries(),
 		OtherChannels:       ch.IntrospectOthers(opts),
 		RuntimeVersion:      introspectRuntimeVersion(),
 	}
@@ -507,6 +511,19 @@ func (ch *Channel) IntrospectNumConnections() int {
 	return numConns
 }
 
+// CloseConnection starts a graceful close of the connection with the given ID.
+// Note: like other introspection APIs, this is intended for debugging.
+func (ch *Channel) CloseConnection(id uint32) error {
+	ch.mutable.RLock()
+	conn, ok := ch.mutable.conns[id]
+	ch.mutable.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("failed to find connection with id %v", id)
+	}
+	return conn.close(LogField{"reason", "closed by introspection"})
+}
+
 func handleInternalRuntime(arg3 []byte) interface{} {
 	var opts GoRuntimeStateOptions
 	json.Unmarshal(arg3, &opts)
@@ -534,40 +551,71 @@ func introspectRuntimeVersion() RuntimeVersion {
 // registerInternal registers the following internal handlers which return runtime state:
 //  _gometa_introspect: TChannel internal state.
 //  _gometa_runtime: Golang runtime stats.
+//  _gometa_calls: Summaries of recent calls, if CallSummaries is enabled.
+// Channels with a RelayHost and RelayAdminEndpoints also register the relay admin
+// handlers (see relay_admin.go) under the "tchannel" service.
 func (ch *Channel) createInternalHandlers() *handlerMap {
 	internalHandlers := &handlerMap{}
 
-	endpoints := []struct {
+	type endpoint struct {
 		name    string
-		handler func([]byte) interface{}
-	}{
-		{"_gometa_introspect", ch.handleIntrospection},
-		{"_gometa_runtime", handleInternalRuntime},
+		handler internalHandler
+	}
+	endpoints := []endpoint{
+		{"_gometa_introspect", infallible(ch.handleIntrospection)},
+		{"_gometa_runtime", infallible(handleInternalRuntime)},
+	}
+	if ch.callSummaries != nil {
+		endpoints = append(endpoints, endpoint{"_gometa_calls", infallible(ch.handleCallSummaries)})
 	}
 
 	for _, ep := range endpoints {
-		// We need ep in our closure.
-		ep := ep
-		handler := func(ctx context.Context, call *InboundCall) {
-			var arg2, arg3 []byte
-			if err := NewArgReader(call.Arg2Reader()).Read(&arg2); err != nil {
-				return
-			}
-			if err := NewArgReader(call.Arg3Reader()).Read(&arg3); err != nil {
-				return
-			}
-			if err := NewArgWriter(call.Response().Arg2Writer()).Write(nil); err != nil {
-				return
-			}
-			NewArgWriter(call.Response().Arg3Writer()).WriteJSON(ep.handler(arg3))
-		}
-
-		h := HandlerFunc(handler)
+		h := newInternalHandler(ep.handler)
 		internalHandlers.Register(h, ep.name)
 
 		// Register under the service name of channel as well (for backwards compatibility).
 		ch.GetSubChannel(ch.PeerInfo().ServiceName).Register(h, ep.name)
 	}
 
+	if ch.relayAdmin != nil {
+		for name, handler := range ch.relayAdminEndpoints() {
+			internalHandlers.Register(newInternalHandler(handler), name)
+		}
+	}
+
 	return internalHandlers
 }
+
+// internalHandler handles the arg3 of an internal call, returning a result
+// that is written as JSON, or an error that is sent as a system error.
+type internalHandler func(arg3 []byte) (interface{}, error)
+
+// infallible returns an internalHandler for a handler that cannot fail.
+func infallible(handler func([]byte) interface{}) internalHandler {
+	return func(arg3 []byte) (interface{}, error) {
+		return handler(arg3), nil
+	}
+}
+
+// newInternalHandler returns a Handler that passes arg3 to the given handler
+// and writes the result as JSON.
+func newInternalHandler(handler internalHandler) Handler {
+	return HandlerFunc(func(ctx context.Context, call *InboundCall) {
+		var arg2, arg3 []byte
+		if err := NewArgReader(call.Arg2Reader()).Read(&arg2); err != nil {
+			return
+		}
+		if err := NewArgReader(call.Arg3Reader()).Read(&arg3); err != nil {
+			return
+		}
+		result, err := handler(arg3)
+		if err != nil {
+			call.Response().SendSystemError(err)
+			return
+		}
+		if err := NewArgWriter(call.Response().Arg2Writer()).Write(nil); err != nil {
+			return
+		}
+		NewArgWriter(call.Response().Arg3Writer()).WriteJSON(result)
+	})
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package introspection exposes the introspection state of all channels in
+// the process over HTTP, as JSON and as a browsable HTML page.
+//
+// The handler should be mounted with its prefix stripped, e.g.:
+//
+//	mux.Handle("/debug/tchannel/", http.StripPrefix("/debug/tchannel", introspection.NewHandler()))
+//
+// The following endpoints are available under the prefix:
+//
+//	/                  HTML page listing all channels, filtered by the "filter" parameter.
+//	/channels          JSON introspection state of all channels, keyed by service.
+//	/runtime           JSON Go runtime state.
+//	/goroutines        Text dump of all goroutine stacks.
+//	/close-connection  Closes the connection specified by the "channel" and
+//	                   "connection" parameters. Only POST requests with a
+//	                   non-empty X-Requested-By header are allowed.
+//
+// The / and /channels endpoints accept the boolean parameters
+// includeExchanges, includeEmptyPeers, includeTombstones and
+// includeOtherChannels, which map to tchannel.IntrospectionOptions.
+//
+// The handler does not authenticate requests, so it should only be served on
+// an internal port. Browsers can't send the X-Requested-By header cross-origin
+// without a CORS preflight, so other sites can't close connections.
+package introspection
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/temporalio/tchannel-go"
+)
+
+// requestedByHeader must be set on requests that modify state. It can't be set
+// by a cross-origin form submission, which protects against CSRF.
+const requestedByHeader = "X-Requested-By"
+
+// NewHandler returns an http.Handler that serves the introspection state
+// of all channels in the process.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", handleIndex)
+	mux.HandleFunc("/channels", handleChannels)
+	mux.HandleFunc("/runtime", handleRuntime)
+	mux.HandleFunc("/goroutines", handleGoroutines)
+	mux.HandleFunc("/close-connection", handleCloseConnection)
+	return mux
+}
+
+// ChannelState is the introspection state of a single channel.
+type ChannelState struct {
+	ServiceName string                 `json:"serviceName"`
+	State       *tchannel.RuntimeState `json:"state"`
+}
+
+func introspectionOptions(r *http.Request) *tchannel.IntrospectionOptions {
+	boolParam := func(name string) bool {
+		v, _ := strconv.ParseBool(r.FormValue(name))
+		return v
+	}
+	return &tchannel.IntrospectionOptions{
+		IncludeExchanges:     boolParam("includeExchanges"),
+		IncludeEmptyPeers:    boolParam("includeEmptyPeers"),
+		IncludeTombstones:    boolParam("includeTombstones"),
+		IncludeOtherChannels: boolParam("includeOtherChannels"),
+	}
+}
+
+// introspectChannels returns the state of all channels whose service name or
+// host:port contains filter, sorted by service name and channel ID.
+func introspectChannels(opts *tchannel.IntrospectionOptions, filter string) []ChannelState {
+	var states []ChannelState
+	for svc, channels := range tchannel.AllChannels() {
+		for _, ch := range channels {
+			if filter != "" && !strings.Contains(svc, filter) && !strings.Contains(ch.PeerInfo().HostPort, filter) {
+				continue
+			}
+			states = append(states, ChannelState{
+				ServiceName: svc,
+				State:       ch.IntrospectState(opts),
+			})
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].ServiceName != states[j].ServiceName {
+			return states[i].ServiceName < states[j].ServiceName
+		}
+		return states[i].State.ID < states[j].State.ID
+	})
+	return states
+}
+
+func handleChannels(w http.ResponseWriter, r *http.Request) {
+	channels := make(map[string][]*tchannel.RuntimeState)
+	for _, s := range introspectChannels(introspectionOptions(r), r.FormValue("filter")) {
+		channels[s.ServiceName] = append(channels[s.ServiceName], s.State)
+	}
+	writeJSON(w, http.StatusOK, channels)
+}
+
+func handleRuntime(w http.ResponseWriter, r *http.Request) {
+	state := tchannel.GoRuntimeState{
+		NumGoroutines: runtime.NumGoroutine(),
+		NumCPU:        runtime.NumCPU(),
+		NumCGo:        runtime.NumCgoCall(),
+	}
+	runtime.ReadMemStats(&state.MemStats)
+	writeJSON(w, http.StatusOK, state)
+}
+
+func handleGoroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	pprof.Lookup("goroutine").WriteTo(w, 2 /* debug: print stacks like a panic */)
+}
+
+func handleCloseConnection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "close-connection requires a POST")
+		return
+	}
+	if r.Header.Get(requestedByHeader) == "" {
+		writeError(w, http.StatusForbidden, "close-connection requires the "+requestedByHeader+" header")
+		return
+	}
+
+	chID, err := strconv.ParseUint(r.FormValue("channel"), 10, 32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, `"channel" must be a channel ID`)
+		return
+	}
+	connID, err := strconv.ParseUint(r.FormValue("connection"), 10, 32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, `"connection" must be a connection ID`)
+		return
+	}
+
+	ch, ok := findChannel(uint32(chID))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("failed to find channel with id %v", chID))
+		return
+	}
+	if err := ch.CloseConnection(uint32(connID)); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"channel":    chID,
+		"connection": connID,
+		"closed":     true,
+	})
+}
+
+func findChannel(id uint32) (*tchannel.Channel, bool) {
+	for _, channels := range tchannel.AllChannels() {
+		for _, ch := range channels {
+			if ch.ReportInfo(nil).ID == id {
+				return ch, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package introspection
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// httpClient disables keep-alives so connections don't outlive the test server,
+// which would fail the goroutine leak checks.
+var httpClient = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+func get(t testing.TB, u string) (int, string) {
+	resp, err := httpClient.Get(u)
+	require.NoError(t, err, "GET %v failed", u)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err, "failed to read response")
+	return resp.StatusCode, string(body)
+}
+
+func TestHandler(t *testing.T) {
+	opts := testutils.NewOpts().NoRelay()
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		testutils.RegisterEcho(ts.Server(), nil)
+		client := ts.NewClient(nil)
+
+		ctx, cancel := tchannel.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+		require.NoError(t, err, "echo failed")
+
+		server := httptest.NewServer(http.StripPrefix("/debug/tchannel", NewHandler()))
+		defer server.Close()
+		base := server.URL + "/debug/tchannel/"
+
+		status, body := get(t, base+"channels?filter="+url.QueryEscape(ts.HostPort()))
+		require.Equal(t, http.StatusOK, status, "unexpected status: %v", body)
+		var channels map[string][]*tchannel.RuntimeState
+		require.NoError(t, json.Unmarshal([]byte(body), &channels), "failed to parse channels")
+		require.Len(t, channels[ts.ServiceName()], 1, "expected only the filtered server channel")
+		state := channels[ts.ServiceName()][0]
+		assert.Equal(t, ts.HostPort(), state.LocalPeer.HostPort, "unexpected channel")
+		assert.Contains(t, state.SubChannels, ts.ServiceName(), "missing subchannel")
+		assert.Len(t, channels, 1, "filter should exclude other channels")
+
+		status, body = get(t, base+"?filter="+url.QueryEscape(ts.ServiceName()))
+		require.Equal(t, http.StatusOK, status, "unexpected status: %v", body)
+		assert.Contains(t, body, ts.HostPort(), "HTML page should list the channel")
+		assert.Contains(t, body, "closeConnection(", "HTML page should allow closing connections")
+
+		status, body = get(t, base+"runtime")
+		require.Equal(t, http.StatusOK, status, "unexpected status: %v", body)
+		assert.Contains(t, body, "numGoRoutines")
+
+		status, body = get(t, base+"goroutines")
+		require.Equal(t, http.StatusOK, status, "unexpected status: %v", body)
+		assert.Contains(t, body, "goroutine ")
+
+		status, _ = get(t, base+"unknown")
+		assert.Equal(t, http.StatusNotFound, status, "unknown paths should 404")
+
+		// Close the server's connection from the client.
+		status, _ = get(t, base+"close-connection")
+		assert.Equal(t, http.StatusMethodNotAllowed, status, "close-connection should require POST")
+
+		var connID uint32
+		for _, peer := range state.RootPeers {
+			for _, conn := range peer.InboundConnections {
+				connID = conn.ID
+			}
+		}
+		require.NotZero(t, connID, "missing inbound connection")
+
+		closeForm := url.Values{
+			"channel":    {strconv.Itoa(int(state.ID))},
+			"connection": {strconv.Itoa(int(connID))},
+		}
+		resp, err := httpClient.PostForm(base+"close-connection", closeForm)
+		require.NoError(t, err, "close-connection failed")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, "close-connection should require the X-Requested-By header")
+		assert.Equal(t, 1, ts.Server().IntrospectNumConnections(), "connection should not be closed without the header")
+
+		resp = postClose(t, base, closeForm)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "unexpected close-connection status")
+		assert.True(t, testutils.WaitFor(time.Second, func() bool {
+			return ts.Server().IntrospectNumConnections() == 0
+		}), "connection was not closed")
+
+		resp = postClose(t, base, closeForm)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, "closing a closed connection should fail")
+	})
+}
+
+// postClose posts the form to the close-connection endpoint with the
+// X-Requested-By header set.
+func postClose(t testing.TB, base string, form url.Values) *http.Response {
+	req, err := http.NewRequest("POST", base+"close-connection", strings.NewReader(form.Encode()))
+	require.NoError(t, err, "NewRequest failed")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Requested-By", "test")
+
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err, "close-connection failed")
+	resp.Body.Close()
+	return resp
+}
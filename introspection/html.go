@@ -0,0 +1,137 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package introspection
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"github.com/temporalio/tchannel-go"
+)
+
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.MarshalIndent(v, "", "  ")
+		return string(b), err
+	},
+	"connRow": newConnRow,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>TChannel introspection</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: left; font-size: 0.9em; }
+pre { background: #f6f6f6; padding: 0.5em; overflow: auto; max-height: 40em; }
+summary { cursor: pointer; }
+</style>
+<script>
+function closeConnection(channel, connection) {
+  fetch("close-connection", {
+    method: "POST",
+    headers: {"X-Requested-By": "introspection"},
+    body: new URLSearchParams({channel: channel, connection: connection}),
+  }).then(function(resp) {
+    if (!resp.ok) {
+      return resp.text().then(function(msg) { alert(msg); });
+    }
+    location.reload();
+  });
+}
+</script>
+</head>
+<body>
+<h1>TChannel introspection</h1>
+<form method="GET" action="./">
+  <input type="text" name="filter" value="{{.Filter}}" placeholder="service or host:port">
+  <label><input type="checkbox" name="includeExchanges" value="true" {{if .Options.IncludeExchanges}}checked{{end}}> exchanges</label>
+  <label><input type="checkbox" name="includeTombstones" value="true" {{if .Options.IncludeTombstones}}checked{{end}}> tombstones</label>
+  <label><input type="checkbox" name="includeEmptyPeers" value="true" {{if .Options.IncludeEmptyPeers}}checked{{end}}> empty peers</label>
+  <input type="submit" value="Filter">
+</form>
+<p>
+  <a href="channels?{{.Query}}">JSON</a> |
+  <a href="runtime">Go runtime</a> |
+  <a href="goroutines">Goroutine dump</a>
+</p>
+{{range .Channels}}
+<h2>{{.ServiceName}} (channel {{.State.ID}}, {{.State.LocalPeer.HostPort}}, {{.State.ChannelState}})</h2>
+<h3>Subchannels</h3>
+<table>
+<tr><th>Service</th><th>Handler</th><th>Methods</th></tr>
+{{range $svc, $sc := .State.SubChannels}}<tr><td>{{$svc}}</td><td>{{$sc.Handler.Type}}</td><td>{{range $sc.Handler.Methods}}{{.}} {{end}}</td></tr>
+{{end}}</table>
+<h3>Connections</h3>
+<table>
+<tr><th>Peer</th><th>ID</th><th>Direction</th><th>State</th><th>Remote process</th><th>Inbound exchanges</th><th>Outbound exchanges</th><th>Relay items (in/out)</th><th></th></tr>
+{{$chID := .State.ID}}{{range $hostPort, $peer := .State.RootPeers}}{{range $peer.InboundConnections}}{{template "conn" (connRow $chID $hostPort "inbound" .)}}{{end}}{{range $peer.OutboundConnections}}{{template "conn" (connRow $chID $hostPort "outbound" .)}}{{end}}{{end}}
+</table>
+<details><summary>Full state</summary><pre>{{json .State}}</pre></details>
+{{else}}
+<p>No channels found.</p>
+{{end}}
+</body>
+</html>
+{{define "conn"}}<tr>
+<td>{{.HostPort}}</td><td>{{.Conn.ID}}</td><td>{{.Direction}}</td><td>{{.Conn.ConnectionState}}</td><td>{{.Conn.RemotePeer.ProcessName}}</td>
+<td>{{.Conn.InboundExchange.Count}}</td><td>{{.Conn.OutboundExchange.Count}}</td><td>{{.Conn.Relayer.InboundItems.Count}}/{{.Conn.Relayer.OutboundItems.Count}}</td>
+<td><button onclick="closeConnection({{.ChannelID}}, {{.Conn.ID}})">Close</button></td>
+</tr>
+{{end}}`))
+
+type connRow struct {
+	ChannelID uint32
+	HostPort  string
+	Direction string
+	Conn      tchannel.ConnectionRuntimeState
+}
+
+func newConnRow(chID uint32, hostPort, direction string, conn tchannel.ConnectionRuntimeState) connRow {
+	return connRow{chID, hostPort, direction, conn}
+}
+
+func handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	opts := introspectionOptions(r)
+	filter := r.FormValue("filter")
+	data := struct {
+		Filter   string
+		Query    string
+		Options  *tchannel.IntrospectionOptions
+		Channels []ChannelState
+	}{
+		Filter:   filter,
+		Query:    r.URL.RawQuery,
+		Options:  opts,
+		Channels: introspectChannels(opts, filter),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := indexTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package json
+
+import (
+	"fmt"
+
+	"github.com/temporalio/tchannel-go"
+
+	"golang.org/x/net/context"
+)
+
+// TypedHandler is a JSON handler for a method with request type Req and
+// response type Resp. Req and Resp are usually pointers to structs.
+type TypedHandler[Req, Resp any] func(ctx Context, req Req) (Resp, error)
+
+// Handle registers a typed handler for method. Unlike Register, the handler's
+// types are checked at compile time.
+//
+// Errors returned by the handler are handled the same way as Register: a
+// SystemError is sent as a system error, and any other error is returned to
+// the caller as an application error, which clients return as ErrApplication.
+// Errors that occur while handling a call are passed to onError.
+func Handle[Req, Resp any](registrar tchannel.Registrar, method string, h TypedHandler[Req, Resp], onError func(context.Context, error)) {
+	handler := tchannel.HandlerFunc(func(ctx context.Context, call *tchannel.InboundCall) {
+		if err := handleTyped(ctx, call, h, registrar); err != nil {
+			onError(ctx, err)
+		}
+	})
+	registrar.Register(handler, method)
+}
+
+func handleTyped[Req, Resp any](tctx context.Context, call *tchannel.InboundCall, h TypedHandler[Req, Resp], registrar tchannel.Registrar) error {
+	ctx, err := readHeaders(tctx, call, tchannel.TracerFromRegistrar(registrar))
+	if err != nil {
+		return err
+	}
+
+	var req Req
+	if err := tchannel.NewArgReader(call.Arg3Reader()).ReadJSON(&req); err != nil {
+		return fmt.Errorf("arg3 read failed: %v", err)
+	}
+
+	res, err := h(ctx, req)
+	return writeResponse(ctx, call, res, err)
+}
+
+// Call makes a JSON call to method using client, with the same retries as
+// Client.Call, and returns the response. If the handler returns an error, the
+// error is returned as an ErrApplication.
+func Call[Req, Resp any](ctx Context, client *Client, method string, req Req) (Resp, error) {
+	var resp Resp
+	if err := client.Call(ctx, method, req, &resp); err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return resp, nil
+}
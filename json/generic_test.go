@@ -0,0 +1,123 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package json
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+type echoReq struct {
+	Message string
+}
+
+type echoRes struct {
+	Message string
+	Header  string
+}
+
+func echo(ctx Context, req *echoReq) (*echoRes, error) {
+	switch req.Message {
+	case "app-error":
+		return nil, errors.New("app failed")
+	case "busy":
+		return nil, tchannel.ErrServerBusy
+	}
+	return &echoRes{Message: req.Message, Header: ctx.Headers()["hdr"]}, nil
+}
+
+func newTypedServer(t *testing.T) (*tchannel.Channel, *Client) {
+	ch := testutils.NewServer(t, nil)
+	onError := func(ctx context.Context, err error) {
+		t.Errorf("onError: %v", err)
+	}
+	Handle(ch, "echo", echo, onError)
+	Handle(ch, "map", func(ctx Context, req map[string]interface{}) (map[string]interface{}, error) {
+		return req, nil
+	}, onError)
+	return ch, NewClient(ch, ch.ServiceName(), &ClientOptions{HostPort: ch.PeerInfo().HostPort})
+}
+
+func TestTypedCall(t *testing.T) {
+	ch, client := newTypedServer(t)
+	defer ch.Close()
+
+	ctx, cancel := NewContext(testutils.Timeout(time.Second))
+	defer cancel()
+	ctx = WithHeaders(ctx, map[string]string{"hdr": "val"})
+
+	res, err := Call[*echoReq, *echoRes](ctx, client, "echo", &echoReq{Message: "hello"})
+	require.NoError(t, err, "Call failed")
+	assert.Equal(t, &echoRes{Message: "hello", Header: "val"}, res, "Unexpected response")
+
+	arg := map[string]interface{}{"k": "v", "n": 1.0}
+	mapRes, err := Call[map[string]interface{}, map[string]interface{}](ctx, client, "map", arg)
+	require.NoError(t, err, "Call with maps failed")
+	assert.Equal(t, arg, mapRes, "Unexpected map response")
+
+	// Typed handlers can be called using the legacy client.
+	var legacyRes echoRes
+	require.NoError(t, client.Call(ctx, "echo", &echoReq{Message: "legacy"}, &legacyRes), "Client.Call failed")
+	assert.Equal(t, echoRes{Message: "legacy", Header: "val"}, legacyRes, "Unexpected legacy response")
+}
+
+func TestTypedCallErrors(t *testing.T) {
+	ch, client := newTypedServer(t)
+	defer ch.Close()
+
+	ctx, cancel := NewContext(testutils.Timeout(time.Second))
+	defer cancel()
+
+	res, err := Call[*echoReq, *echoRes](ctx, client, "echo", &echoReq{Message: "app-error"})
+	assert.Nil(t, res, "Unexpected response for error")
+	assert.Equal(t, ErrApplication{"type": "error", "message": "app failed"}, err, "Unexpected application error")
+
+	_, err = Call[*echoReq, *echoRes](ctx, client, "echo", &echoReq{Message: "busy"})
+	require.Error(t, err, "Call should fail")
+	assert.Contains(t, err.Error(), tchannel.ErrServerBusy.Error(), "Unexpected system error")
+}
+
+func TestTypedCallLegacyHandler(t *testing.T) {
+	ch := testutils.NewServer(t, nil)
+	defer ch.Close()
+
+	handler := &testHandler{t: t}
+	require.NoError(t, Register(ch, Handlers{"leaf": handler.leaf}, handler.onError))
+
+	ctx, cancel := NewContext(testutils.Timeout(time.Second))
+	defer cancel()
+
+	client := NewClient(ch, ch.ServiceName(), &ClientOptions{HostPort: ch.PeerInfo().HostPort})
+	res, err := Call[*struct{}, *Res](ctx, client, "leaf", nil)
+	require.NoError(t, err, "Call failed")
+	assert.Equal(t, &Res{"leaf called!"}, res, "Unexpected response")
+}
@@ -93,6 +93,8 @@ func toHandler(f interface{}) (*handler, error) {
 // Register registers the specified methods specified as a map from method name to the
 // JSON handler function. The handler functions should have the following signature:
 // func(context.Context, *ArgType)(*ResType, error)
+// Handler signatures are checked when they're registered. Use Handle to check
+// them at compile time instead.
 func Register(registrar tchannel.Registrar, funcs Handlers, onError func(context.Context, error)) error {
 	handlers := make(map[string]*handler)
 
@@ -125,12 +127,10 @@ func Register(registrar tchannel.Registrar, funcs Handlers, onError func(context
 
 // Handle deserializes the JSON arguments and calls the underlying handler.
 func (h *handler) Handle(tctx context.Context, call *tchannel.InboundCall) error {
-	var headers map[string]string
-	if err := tchannel.NewArgReader(call.Arg2Reader()).ReadJSON(&headers); err != nil {
-		return fmt.Errorf("arg2 read failed: %v", err)
+	ctx, err := readHeaders(tctx, call, h.tracer())
+	if err != nil {
+		return err
 	}
-	tctx = tchannel.ExtractInboundSpan(tctx, call, headers, h.tracer())
-	ctx := WithHeaders(tctx, headers)
 
 	var arg3 reflect.Value
 	var callArg reflect.Value
@@ -149,9 +149,23 @@ func (h *handler) Handle(tctx context.Context, call *tchannel.InboundCall) error
 	args := []reflect.Value{reflect.ValueOf(ctx), callArg}
 	results := h.handler.Call(args)
 
-	res := results[0].Interface()
-	err := results[1].Interface()
-	// If an error was returned, we create an error arg3 to respond with.
+	handlerErr, _ := results[1].Interface().(error)
+	return writeResponse(ctx, call, results[0].Interface(), handlerErr)
+}
+
+// readHeaders reads the JSON headers from arg2, and returns the Context for the call.
+func readHeaders(tctx context.Context, call *tchannel.InboundCall, tracer opentracing.Tracer) (Context, error) {
+	var headers map[string]string
+	if err := tchannel.NewArgReader(call.Arg2Reader()).ReadJSON(&headers); err != nil {
+		return nil, fmt.Errorf("arg2 read failed: %v", err)
+	}
+	tctx = tchannel.ExtractInboundSpan(tctx, call, headers, tracer)
+	return WithHeaders(tctx, headers), nil
+}
+
+// writeResponse writes the response headers and the result of a handler.
+// If the handler returned an error, an error arg3 is written instead.
+func writeResponse(ctx Context, call *tchannel.InboundCall, res interface{}, err error) error {
 	if err != nil {
 		// TODO(prashantv): More consistent error handling between json/raw/thrift..
 		if serr, ok := err.(tchannel.SystemError); ok {
@@ -165,7 +179,7 @@ func (h *handler) Handle(tctx context.Context, call *tchannel.InboundCall) error
 			Message string `json:"message"`
 		}{
 			Type:    "error",
-			Message: err.(error).Error(),
+			Message: err.Error(),
 		}
 	}
 
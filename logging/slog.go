@@ -0,0 +1,242 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+)
+
+// slogLevelFatal is used for Fatal messages, as slog has no fatal level.
+const slogLevelFatal = slog.LevelError + 4
+
+type slogLogger struct {
+	logger *slog.Logger
+	fields tchannel.LogFields
+}
+
+// NewSlogLogger returns a tchannel.Logger that writes to the given slog logger.
+// Fatal messages are logged at slog.LevelError+4 before exiting.
+func NewSlogLogger(logger *slog.Logger) tchannel.Logger {
+	return slogLogger{logger: logger}
+}
+
+func slogLevel(level tchannel.LogLevel) slog.Level {
+	switch level {
+	case tchannel.LogLevelAll, tchannel.LogLevelDebug:
+		return slog.LevelDebug
+	case tchannel.LogLevelInfo:
+		return slog.LevelInfo
+	case tchannel.LogLevelWarn:
+		return slog.LevelWarn
+	case tchannel.LogLevelError:
+		return slog.LevelError
+	default:
+		return slogLevelFatal
+	}
+}
+
+func (l slogLogger) Enabled(level tchannel.LogLevel) bool {
+	return l.logger.Enabled(context.Background(), slogLevel(level))
+}
+
+func (l slogLogger) Fatal(msg string) {
+	l.log(slogLevelFatal, msg)
+	os.Exit(1)
+}
+
+func (l slogLogger) Error(msg string) { l.log(slog.LevelError, msg) }
+func (l slogLogger) Warn(msg string)  { l.log(slog.LevelWarn, msg) }
+func (l slogLogger) Info(msg string)  { l.log(slog.LevelInfo, msg) }
+func (l slogLogger) Debug(msg string) { l.log(slog.LevelDebug, msg) }
+
+func (l slogLogger) Infof(msg string, args ...interface{}) {
+	l.logf(slog.LevelInfo, msg, args...)
+}
+
+func (l slogLogger) Debugf(msg string, args ...interface{}) {
+	l.logf(slog.LevelDebug, msg, args...)
+}
+
+func (l slogLogger) logf(level slog.Level, msg string, args ...interface{}) {
+	// Check the level before formatting the message.
+	if l.logger.Enabled(context.Background(), level) {
+		l.log(level, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l slogLogger) log(level slog.Level, msg string) {
+	l.logger.Log(context.Background(), level, msg)
+}
+
+func (l slogLogger) Fields() tchannel.LogFields {
+	return l.fields
+}
+
+func (l slogLogger) WithFields(fields ...tchannel.LogField) tchannel.Logger {
+	args := make([]interface{}, len(fields))
+	for i, f := range fields {
+		args[i] = slogAttr(f)
+	}
+
+	newFields := make(tchannel.LogFields, 0, len(l.fields)+len(fields))
+	newFields = append(newFields, l.fields...)
+	newFields = append(newFields, fields...)
+	return slogLogger{
+		logger: l.logger.With(args...),
+		fields: newFields,
+	}
+}
+
+// slogAttr converts a LogField to a typed slog attribute.
+func slogAttr(f tchannel.LogField) slog.Attr {
+	switch v := f.Value.(type) {
+	case string:
+		return slog.String(f.Key, v)
+	case int:
+		return slog.Int(f.Key, v)
+	case int32:
+		return slog.Int64(f.Key, int64(v))
+	case int64:
+		return slog.Int64(f.Key, v)
+	case uint16:
+		return slog.Uint64(f.Key, uint64(v))
+	case uint32:
+		return slog.Uint64(f.Key, uint64(v))
+	case uint64:
+		return slog.Uint64(f.Key, v)
+	case float64:
+		return slog.Float64(f.Key, v)
+	case bool:
+		return slog.Bool(f.Key, v)
+	case time.Duration:
+		return slog.Duration(f.Key, v)
+	case time.Time:
+		return slog.Time(f.Key, v)
+	case error:
+		return slog.String(f.Key, v.Error())
+	default:
+		return slog.Any(f.Key, v)
+	}
+}
+
+type slogHandler struct {
+	logger tchannel.Logger
+	group  string
+}
+
+// NewSlogHandler returns a slog.Handler that writes records to the given
+// tchannel.Logger. Attributes are passed as LogFields, with the names of any
+// groups joined to the attribute key using ".". Records at levels above
+// slog.LevelError are logged as errors, as Fatal would exit the process.
+func NewSlogHandler(logger tchannel.Logger) slog.Handler {
+	return slogHandler{logger: logger}
+}
+
+func tchannelLevel(level slog.Level) tchannel.LogLevel {
+	switch {
+	case level >= slog.LevelError:
+		return tchannel.LogLevelError
+	case level >= slog.LevelWarn:
+		return tchannel.LogLevelWarn
+	case level >= slog.LevelInfo:
+		return tchannel.LogLevelInfo
+	default:
+		return tchannel.LogLevelDebug
+	}
+}
+
+func (h slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(tchannelLevel(level))
+}
+
+func (h slogHandler) Handle(_ context.Context, r slog.Record) error {
+	logger := h.logger
+	if r.NumAttrs() > 0 {
+		fields := make(tchannel.LogFields, 0, r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			fields = appendSlogAttr(fields, h.group, a)
+			return true
+		})
+		logger = logger.WithFields(fields...)
+	}
+
+	switch tchannelLevel(r.Level) {
+	case tchannel.LogLevelError:
+		logger.Error(r.Message)
+	case tchannel.LogLevelWarn:
+		logger.Warn(r.Message)
+	case tchannel.LogLevelInfo:
+		logger.Info(r.Message)
+	default:
+		logger.Debug(r.Message)
+	}
+	return nil
+}
+
+func (h slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(tchannel.LogFields, 0, len(attrs))
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, h.group, a)
+	}
+	return slogHandler{
+		logger: h.logger.WithFields(fields...),
+		group:  h.group,
+	}
+}
+
+func (h slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return slogHandler{
+		logger: h.logger,
+		group:  h.group + name + ".",
+	}
+}
+
+// appendSlogAttr appends the attribute to fields, flattening groups into
+// dotted keys.
+func appendSlogAttr(fields tchannel.LogFields, prefix string, a slog.Attr) tchannel.LogFields {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			fields = appendSlogAttr(fields, groupPrefix, ga)
+		}
+		return fields
+	}
+	if a.Key == "" {
+		return fields
+	}
+	return append(fields, tchannel.LogField{Key: prefix + a.Key, Value: v.Any()})
+}
@@ -0,0 +1,78 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	assert.False(t, logger.Enabled(tchannel.LogLevelDebug), "debug should be disabled")
+	assert.True(t, logger.Enabled(tchannel.LogLevelInfo), "info should be enabled")
+
+	logger.Debugf("debug %v", "message")
+	assert.Empty(t, buf.String(), "debug messages should not be logged")
+
+	withFields := logger.WithFields(
+		tchannel.LogField{Key: "service", Value: "svc"},
+		tchannel.LogField{Key: "count", Value: 3},
+		tchannel.LogField{Key: "timeout", Value: time.Second},
+	)
+	assert.Len(t, withFields.Fields(), 3, "unexpected fields")
+	assert.Empty(t, logger.Fields(), "WithFields should not modify the original logger")
+
+	withFields.Infof("info %v", "message")
+	withFields.Warn("warn message")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2, "unexpected number of log lines")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry), "failed to parse log line")
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "info message", entry["msg"], "Infof should format the message")
+	assert.Equal(t, "svc", entry["service"])
+	assert.Equal(t, float64(3), entry["count"], "int fields should be logged as numbers")
+	assert.Equal(t, float64(time.Second), entry["timeout"], "durations should be logged as durations")
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry), "failed to parse log line")
+	assert.Equal(t, "WARN", entry["level"])
+}
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	tlogger := tchannel.NewLevelLogger(tchannel.NewLogger(&buf), tchannel.LogLevelInfo)
+	logger := slog.New(NewSlogHandler(tlogger))
+
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelDebug), "debug should be disabled")
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelWarn), "warn should be enabled")
+
+	logger.Debug("debug message")
+	assert.Empty(t, buf.String(), "debug messages should not be logged")
+
+	logger.With("service", "svc").WithGroup("req").Info("info message", "method", "echo", slog.Group("peer", "host", "h:1"))
+	out := buf.String()
+	assert.Contains(t, out, "[I] info message")
+	assert.Contains(t, out, "{service svc}")
+	assert.Contains(t, out, "{req.method echo}")
+	assert.Contains(t, out, "{req.peer.host h:1}")
+
+	buf.Reset()
+	logger.Log(context.Background(), slog.LevelError+4, "fatal message")
+	assert.Contains(t, buf.String(), "[E] fatal message", "levels above error should be logged as errors")
+}
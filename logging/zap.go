@@ -0,0 +1,109 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package logging
+
+import (
+	"fmt"
+
+	"github.com/temporalio/tchannel-go"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type zapLogger struct {
+	logger *zap.Logger
+	fields tchannel.LogFields
+}
+
+// NewZapLogger returns a tchannel.Logger that writes to the given zap logger.
+// Fields are added to the zap logger once when WithFields is called, so they
+// are not re-encoded for every message.
+func NewZapLogger(logger *zap.Logger) tchannel.Logger {
+	// Skip the adapter's frame so callers are reported correctly.
+	return zapLogger{logger: logger.WithOptions(zap.AddCallerSkip(1))}
+}
+
+func zapLevel(level tchannel.LogLevel) zapcore.Level {
+	switch level {
+	case tchannel.LogLevelAll, tchannel.LogLevelDebug:
+		return zapcore.DebugLevel
+	case tchannel.LogLevelInfo:
+		return zapcore.InfoLevel
+	case tchannel.LogLevelWarn:
+		return zapcore.WarnLevel
+	case tchannel.LogLevelError:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.FatalLevel
+	}
+}
+
+func (l zapLogger) Enabled(level tchannel.LogLevel) bool {
+	return l.logger.Core().Enabled(zapLevel(level))
+}
+
+func (l zapLogger) Fatal(msg string) { l.logger.Fatal(msg) }
+func (l zapLogger) Error(msg string) { l.logger.Error(msg) }
+func (l zapLogger) Warn(msg string)  { l.logger.Warn(msg) }
+func (l zapLogger) Info(msg string)  { l.logger.Info(msg) }
+func (l zapLogger) Debug(msg string) { l.logger.Debug(msg) }
+
+func (l zapLogger) Infof(msg string, args ...interface{}) {
+	if ce := l.logger.Check(zapcore.InfoLevel, msg); ce != nil {
+		ce.Message = fmt.Sprintf(msg, args...)
+		ce.Write()
+	}
+}
+
+func (l zapLogger) Debugf(msg string, args ...interface{}) {
+	if ce := l.logger.Check(zapcore.DebugLevel, msg); ce != nil {
+		ce.Message = fmt.Sprintf(msg, args...)
+		ce.Write()
+	}
+}
+
+func (l zapLogger) Fields() tchannel.LogFields {
+	return l.fields
+}
+
+func (l zapLogger) WithFields(fields ...tchannel.LogField) tchannel.Logger {
+	zapFields := make([]zap.Field, len(fields))
+	for i, f := range fields {
+		zapFields[i] = zapField(f)
+	}
+
+	newFields := make(tchannel.LogFields, 0, len(l.fields)+len(fields))
+	newFields = append(newFields, l.fields...)
+	newFields = append(newFields, fields...)
+	return zapLogger{
+		logger: l.logger.With(zapFields...),
+		fields: newFields,
+	}
+}
+
+// zapField converts a LogField to a typed zap field.
+func zapField(f tchannel.LogField) zap.Field {
+	if err, ok := f.Value.(error); ok {
+		return zap.NamedError(f.Key, err)
+	}
+	return zap.Any(f.Key, f.Value)
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZapLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := NewZapLogger(zap.New(core))
+
+	assert.False(t, logger.Enabled(tchannel.LogLevelDebug), "debug should be disabled")
+	assert.True(t, logger.Enabled(tchannel.LogLevelInfo), "info should be enabled")
+	assert.True(t, logger.Enabled(tchannel.LogLevelError), "error should be enabled")
+
+	logger.Debugf("debug %v", "message")
+	logger.Debug("debug message")
+	assert.Equal(t, 0, logs.Len(), "debug messages should not be logged")
+
+	withFields := logger.WithFields(
+		tchannel.LogField{Key: "service", Value: "svc"},
+		tchannel.LogField{Key: "count", Value: 3},
+	).WithFields(
+		tchannel.LogField{Key: "timeout", Value: time.Second},
+		tchannel.ErrField(errors.New("failed")),
+	)
+	assert.Equal(t, tchannel.LogFields{
+		{Key: "service", Value: "svc"},
+		{Key: "count", Value: 3},
+		{Key: "timeout", Value: time.Second},
+		{Key: "error", Value: "failed"},
+	}, withFields.Fields(), "unexpected fields")
+	assert.Empty(t, logger.Fields(), "WithFields should not modify the original logger")
+
+	withFields.Infof("info %v", "message")
+	withFields.Warn("warn message")
+	withFields.Error("error message")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 3, "unexpected number of log entries")
+	assert.Equal(t, "info message", entries[0].Message, "Infof should format the message")
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Equal(t, zapcore.ErrorLevel, entries[2].Level)
+	assert.Equal(t, map[string]interface{}{
+		"service": "svc",
+		"count":   int64(3),
+		"timeout": time.Second,
+		"error":   "failed",
+	}, entries[2].ContextMap(), "fields should be typed zap fields")
+}
@@ -175,6 +175,11 @@ const (
 	// requested service. A relay may use the routing key over the service if
 	// it knows about traffic groups.
 	RoutingKey TransportHeaderName = "rk"
+
+	// ThriftProtocol header specifies the Thrift protocol used to encode arg3
+	// for calls using the "thrift" arg scheme. The binary protocol is used
+	// if the header is not set.
+	ThriftProtocol TransportHeaderName = "tp"
 )
 
 // transportHeaders are passed as part of a CallReq/CallRes
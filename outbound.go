@@ -28,6 +28,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
 )
 
@@ -96,6 +97,7 @@ func (c *Connection) beginCall(ctx context.Context, serviceName, methodName stri
 		Service:    serviceName,
 		TimeToLive: timeToLive,
 	}
+	call.methodName = methodName
 	call.statsReporter = c.statsReporter
 	call.createStatsTags(c.commonStatsTags, callOptions, methodName)
 	call.log = c.log.WithFields(LogField{"Out-Call", requestID})
@@ -117,7 +119,11 @@ func (c *Connection) beginCall(ctx context.Context, serviceName, methodName stri
 	response.requestState = callOptions.RequestState
 	response.mex = mex
 	response.log = c.log.WithFields(LogField{"Out-Response", requestID})
-	response.span = c.startOutboundSpan(ctx, serviceName, methodName, call, now)
+	if c.otel != nil {
+		response.otelCtx, response.otelSpan = c.startOutboundOtelSpan(ctx, serviceName, methodName, call, now)
+	} else {
+		response.span = c.startOutboundSpan(ctx, serviceName, methodName, call, now)
+	}
 	response.messageForFragment = func(initial bool) message {
 		if initial {
 			return &response.callRes
@@ -130,6 +136,7 @@ func (c *Connection) beginCall(ctx context.Context, serviceName, methodName stri
 	response.commonStatsTags = call.commonStatsTags
 
 	call.response = response
+	response.call = call
 
 	if err := call.writeMethod([]byte(methodName)); err != nil {
 		return nil, err
@@ -163,6 +170,7 @@ type OutboundCall struct {
 	reqResWriter
 
 	callReq         callReq
+	methodName      string
 	response        *OutboundCallResponse
 	statsReporter   StatsReporter
 	commonStatsTags map[string]string
@@ -224,10 +232,13 @@ type OutboundCallResponse struct {
 	callRes callRes
 
 	requestState *RequestState
+	call         *OutboundCall
 	// startedAt is the time at which the outbound call was started.
 	startedAt       time.Time
 	timeNow         func() time.Time
 	span            opentracing.Span
+	otelSpan        trace.Span
+	otelCtx         context.Context
 	statsReporter   StatsReporter
 	commonStatsTags map[string]string
 }
@@ -333,13 +344,19 @@ func (response *OutboundCallResponse) doneReading(unexpected error) {
 		}
 		span.FinishWithOptions(opentracing.FinishOptions{FinishTime: now})
 	}
+	if span := response.otelSpan; span != nil {
+		endOtelSpan(span, !isSuccess && lastAttempt, unexpected, now)
+	}
 
 	latency := now.Sub(response.startedAt)
-	response.statsReporter.RecordTimer("outbound.calls.per-attempt.latency", response.commonStatsTags, latency)
+	recordLatency(response.statsReporter, "outbound.calls.per-attempt.latency", response.commonStatsTags, latency)
 	if lastAttempt {
 		requestLatency := response.requestState.SinceStart(now, latency)
-		response.statsReporter.RecordTimer("outbound.calls.latency", response.commonStatsTags, requestLatency)
+		recordLatency(response.statsReporter, "outbound.calls.latency", response.commonStatsTags, requestLatency)
+		response.recordCallSummary(now, requestLatency, unexpected)
 	}
+	recordSize(response.statsReporter, "outbound.calls.request-bytes", response.commonStatsTags, response.call.bytesWritten.Load())
+	recordSize(response.statsReporter, "outbound.calls.response-bytes", response.commonStatsTags, response.bytesRead)
 	if retryCount := response.requestState.RetryCount(); retryCount > 0 {
 		retryTags := cloneTags(response.commonStatsTags)
 		retryTags["retry-count"] = fmt.Sprint(retryCount)
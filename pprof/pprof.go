@@ -40,7 +40,7 @@ func serveHTTP(req *http.Request, response *tchannel.InboundCallResponse) {
 // The _pprof endpoint uses as-http and is a tunnel to the default serve mux.
 func Register(registrar tchannel.Registrar) {
 	handler := func(ctx context.Context, call *tchannel.InboundCall) {
-		req, err := thttp.ReadRequest(call)
+		req, err := thttp.ReadRequestWithContext(ctx, call)
 		if err != nil {
 			registrar.Logger().WithFields(
 				tchannel.LogField{Key: "err", Value: err.Error()},
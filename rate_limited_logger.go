@@ -0,0 +1,340 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultLogRateInterval = time.Second
+	_defaultLogRateBurst    = 10
+)
+
+// RateLimitedLoggerOptions are used to configure a rate-limited Logger.
+type RateLimitedLoggerOptions struct {
+	// Interval is the period over which similar messages are counted.
+	// Defaults to 1 second.
+	Interval time.Duration
+
+	// Burst is the number of similar messages that are logged each Interval,
+	// after which they are suppressed until the Interval ends.
+	// Defaults to 10. A negative value disables rate limiting.
+	Burst int
+
+	// LevelBurst overrides Burst for specific levels. A negative value
+	// disables rate limiting for that level.
+	// Fatal messages are never rate limited.
+	LevelBurst map[LogLevel]int
+
+	// KeyFields lists the fields whose values are part of a message's
+	// signature. Messages with the same level, message and key field values
+	// are similar, regardless of any other fields.
+	KeyFields []string
+
+	// TimeNow is a variable for overriding time.Now in unit tests.
+	// Note: This is not a stable part of the API and may change.
+	TimeNow func() time.Time
+}
+
+// logRateLimiter tracks similar messages across all loggers that share it.
+type logRateLimiter struct {
+	interval  time.Duration
+	bursts    [LogLevelFatal]int
+	keyFields map[string]struct{}
+	timeNow   func() time.Time
+
+	// logger is used to log summaries, which cover messages from every
+	// logger that shares the limiter, so it shouldn't have their fields.
+	logger Logger
+
+	sync.Mutex
+	nextSweep time.Time
+	entries   map[logSignature]*logRateEntry
+}
+
+type logSignature struct {
+	level LogLevel
+	msg   string
+	keys  string
+}
+
+type logRateEntry struct {
+	windowStart time.Time
+	count       int
+	suppressed  int
+
+	// keyFields are the key fields of the signature, which are logged
+	// with the summary.
+	keyFields LogFields
+}
+
+type suppressedSummary struct {
+	sig        logSignature
+	suppressed int
+	keyFields  LogFields
+}
+
+// NewRateLimitedLogger returns a Logger that limits the number of similar
+// messages logged by logger, and any loggers created from it using WithFields.
+// Once the limit is reached, similar messages are dropped, and a summary of
+// the number of suppressed messages is logged by the first message after the
+// Interval ends. Summaries are logged using logger, with only the key fields.
+func NewRateLimitedLogger(logger Logger, opts RateLimitedLoggerOptions) Logger {
+	return newLogRateLimiter(opts, logger).wrap(logger)
+}
+
+// newLogRateLimiter returns a logRateLimiter that logs summaries using logger,
+// or nil if rate limiting is disabled.
+func newLogRateLimiter(opts RateLimitedLoggerOptions, logger Logger) *logRateLimiter {
+	if opts.Burst < 0 {
+		return nil
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = _defaultLogRateInterval
+	}
+	if opts.Burst == 0 {
+		opts.Burst = _defaultLogRateBurst
+	}
+	if opts.TimeNow == nil {
+		opts.TimeNow = time.Now
+	}
+
+	l := &logRateLimiter{
+		interval:  opts.Interval,
+		keyFields: make(map[string]struct{}, len(opts.KeyFields)),
+		timeNow:   opts.TimeNow,
+		logger:    logger,
+		entries:   make(map[logSignature]*logRateEntry),
+	}
+	for i := range l.bursts {
+		l.bursts[i] = opts.Burst
+	}
+	for level, burst := range opts.LevelBurst {
+		if level >= 0 && level < LogLevelFatal {
+			l.bursts[level] = burst
+		}
+	}
+	for _, k := range opts.KeyFields {
+		l.keyFields[k] = struct{}{}
+	}
+	return l
+}
+
+// wrap returns a rate-limited logger that uses l. If l is nil, the
+// logger is returned as-is.
+func (l *logRateLimiter) wrap(logger Logger) Logger {
+	if l == nil {
+		return logger
+	}
+	rl := rateLimitedLogger{logger: logger, limiter: l}
+	rl.keys = rl.appendKeys("", logger.Fields())
+	return rl
+}
+
+// allow returns whether a message with the given signature should be logged,
+// along with summaries of messages that were suppressed in expired intervals.
+// fields are the fields of the logger for the message.
+func (l *logRateLimiter) allow(sig logSignature, fields LogFields) (bool, []suppressedSummary) {
+	burst := l.bursts[sig.level]
+	if burst < 0 {
+		return true, nil
+	}
+
+	var summaries []suppressedSummary
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.timeNow()
+	if !now.Before(l.nextSweep) {
+		// Periodically drop expired entries so the map doesn't grow
+		// with the number of distinct messages, and so summaries are
+		// logged even if there are no further similar messages.
+		l.nextSweep = now.Add(l.interval)
+		for s, e := range l.entries {
+			windowEnd := e.windowStart.Add(l.interval)
+			if !now.Before(windowEnd) {
+				summaries = appendSuppressed(summaries, s, e)
+				delete(l.entries, s)
+				continue
+			}
+			if e.suppressed > 0 && windowEnd.Before(l.nextSweep) {
+				l.nextSweep = windowEnd
+			}
+		}
+	}
+
+	e, ok := l.entries[sig]
+	if ok && now.Sub(e.windowStart) >= l.interval {
+		summaries = appendSuppressed(summaries, sig, e)
+		ok = false
+	}
+	if !ok {
+		l.entries[sig] = &logRateEntry{windowStart: now, count: 1, keyFields: l.filterKeyFields(fields)}
+		return true, summaries
+	}
+
+	e.count++
+	if e.count <= burst {
+		return true, summaries
+	}
+	e.suppressed++
+	if windowEnd := e.windowStart.Add(l.interval); e.suppressed == 1 && windowEnd.Before(l.nextSweep) {
+		// Sweep at the first message after the interval ends, so the
+		// summary isn't delayed until the next periodic sweep.
+		l.nextSweep = windowEnd
+	}
+	return false, summaries
+}
+
+// filterKeyFields returns the key fields in fields.
+func (l *logRateLimiter) filterKeyFields(fields LogFields) LogFields {
+	var keyFields LogFields
+	for _, f := range fields {
+		if _, ok := l.keyFields[f.Key]; ok {
+			keyFields = append(keyFields, f)
+		}
+	}
+	return keyFields
+}
+
+func appendSuppressed(summaries []suppressedSummary, sig logSignature, e *logRateEntry) []suppressedSummary {
+	if e.suppressed == 0 {
+		return summaries
+	}
+	return append(summaries, suppressedSummary{sig, e.suppressed, e.keyFields})
+}
+
+func (l *logRateLimiter) logSummary(s suppressedSummary) {
+	fields := append(LogFields{{"suppressed", s.suppressed}}, s.keyFields...)
+	logAtLevel(
+		l.logger.WithFields(fields...),
+		s.sig.level,
+		fmt.Sprintf("Suppressed %d similar messages: %s", s.suppressed, s.sig.msg),
+	)
+}
+
+type rateLimitedLogger struct {
+	logger  Logger
+	limiter *logRateLimiter
+
+	// keys is the signature of the logger's key fields.
+	keys string
+}
+
+func (l rateLimitedLogger) appendKeys(keys string, fields LogFields) string {
+	if len(l.limiter.keyFields) == 0 {
+		return keys
+	}
+
+	var sb strings.Builder
+	sb.WriteString(keys)
+	for _, f := range fields {
+		if _, ok := l.limiter.keyFields[f.Key]; ok {
+			fmt.Fprintf(&sb, "%s=%v;", f.Key, f.Value)
+		}
+	}
+	return sb.String()
+}
+
+func (l rateLimitedLogger) allow(level LogLevel, msg string) bool {
+	if !l.logger.Enabled(level) {
+		return false
+	}
+
+	ok, summaries := l.limiter.allow(logSignature{level, msg, l.keys}, l.logger.Fields())
+	for _, s := range summaries {
+		l.limiter.logSummary(s)
+	}
+	return ok
+}
+
+func logAtLevel(logger Logger, level LogLevel, msg string) {
+	switch level {
+	case LogLevelError:
+		logger.Error(msg)
+	case LogLevelWarn:
+		logger.Warn(msg)
+	case LogLevelInfo:
+		logger.Info(msg)
+	default:
+		logger.Debug(msg)
+	}
+}
+
+func (l rateLimitedLogger) Enabled(level LogLevel) bool {
+	return l.logger.Enabled(level)
+}
+
+func (l rateLimitedLogger) Fatal(msg string) {
+	l.logger.Fatal(msg)
+}
+
+func (l rateLimitedLogger) Error(msg string) {
+	if l.allow(LogLevelError, msg) {
+		l.logger.Error(msg)
+	}
+}
+
+func (l rateLimitedLogger) Warn(msg string) {
+	if l.allow(LogLevelWarn, msg) {
+		l.logger.Warn(msg)
+	}
+}
+
+func (l rateLimitedLogger) Infof(msg string, args ...interface{}) {
+	if l.allow(LogLevelInfo, msg) {
+		l.logger.Infof(msg, args...)
+	}
+}
+
+func (l rateLimitedLogger) Info(msg string) {
+	if l.allow(LogLevelInfo, msg) {
+		l.logger.Info(msg)
+	}
+}
+
+func (l rateLimitedLogger) Debugf(msg string, args ...interface{}) {
+	if l.allow(LogLevelDebug, msg) {
+		l.logger.Debugf(msg, args...)
+	}
+}
+
+func (l rateLimitedLogger) Debug(msg string) {
+	if l.allow(LogLevelDebug, msg) {
+		l.logger.Debug(msg)
+	}
+}
+
+func (l rateLimitedLogger) Fields() LogFields {
+	return l.logger.Fields()
+}
+
+func (l rateLimitedLogger) WithFields(fields ...LogField) Logger {
+	return rateLimitedLogger{
+		logger:  l.logger.WithFields(fields...),
+		limiter: l.limiter,
+		keys:    l.appendKeys(l.keys, fields),
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/temporalio/tchannel-go"
+
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lockedBuffer is a buffer that is safe for concurrent use.
+type lockedBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func logLines(b *lockedBuffer) []string {
+	b.Lock()
+	defer b.Unlock()
+
+	out := strings.TrimSpace(b.buf.String())
+	b.buf.Reset()
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+func TestRateLimitedLogger(t *testing.T) {
+	var buf lockedBuffer
+	clock := testutils.NewStubClock(time.Unix(1000, 0))
+	logger := NewRateLimitedLogger(NewLogger(&buf), RateLimitedLoggerOptions{
+		Interval: time.Second,
+		Burst:    2,
+		TimeNow:  clock.Now,
+	})
+
+	for i := 0; i < 5; i++ {
+		logger.WithFields(LogField{"i", i}).Warn("hot warning")
+	}
+	logger.Warn("other warning")
+	logger.Error("hot warning")
+	lines := logLines(&buf)
+	assert.Len(t, lines, 4, "expected burst of hot warnings, and other messages: %v", lines)
+
+	// After the interval, the next call logs a summary of suppressed messages.
+	clock.Elapse(time.Second)
+	logger.Warn("hot warning")
+	lines = logLines(&buf)
+	if assert.Len(t, lines, 2, "expected summary and message") {
+		assert.Contains(t, lines[0], "Suppressed 3 similar messages: hot warning")
+		assert.Contains(t, lines[0], "{suppressed 3}")
+		assert.NotContains(t, lines[0], "{i ", "summary should not use the fields of any message")
+		assert.Contains(t, lines[1], "hot warning")
+	}
+
+	// Messages that were not suppressed don't log a summary.
+	clock.Elapse(time.Second)
+	logger.Warn("other warning")
+	assert.Len(t, logLines(&buf), 1, "unexpected summary")
+}
+
+func TestRateLimitedLoggerKeyFields(t *testing.T) {
+	var buf lockedBuffer
+	clock := testutils.NewStubClock(time.Unix(1000, 0))
+	logger := NewRateLimitedLogger(NewLogger(&buf), RateLimitedLoggerOptions{
+		Burst:     1,
+		KeyFields: []string{"peer"},
+		TimeNow:   clock.Now,
+	})
+
+	peer1 := logger.WithFields(LogField{"peer", "1"})
+	peer2 := logger.WithFields(LogField{"peer", "2"})
+	for i := 0; i < 3; i++ {
+		peer1.WithFields(LogField{"id", i}).Warn("warning")
+		peer2.WithFields(LogField{"id", i}).Warn("warning")
+		peer2.Infof("formatted %v", i)
+	}
+	lines := logLines(&buf)
+	assert.Len(t, lines, 3, "expected one message per signature: %v", lines)
+}
+
+func TestRateLimitedLoggerLevels(t *testing.T) {
+	var buf lockedBuffer
+	logger := NewRateLimitedLogger(NewLevelLogger(NewLogger(&buf), LogLevelInfo), RateLimitedLoggerOptions{
+		Burst: 1,
+		LevelBurst: map[LogLevel]int{
+			LogLevelError: -1,
+			LogLevelWarn:  3,
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		logger.Error("error")
+		logger.Warn("warn")
+		logger.Info("info")
+		logger.Debug("debug")
+	}
+	lines := logLines(&buf)
+	assert.Len(t, lines, 5+3+1, "unexpected number of messages: %v", lines)
+	assert.True(t, logger.Enabled(LogLevelInfo), "Enabled should use the underlying logger")
+	assert.False(t, logger.Enabled(LogLevelDebug), "Enabled should use the underlying logger")
+
+	disabled := NewRateLimitedLogger(NewLogger(&buf), RateLimitedLoggerOptions{Burst: -1})
+	for i := 0; i < 20; i++ {
+		disabled.Warn("warn")
+	}
+	assert.Len(t, logLines(&buf), 20, "negative Burst should disable rate limiting")
+}
+
+func TestRateLimitedLoggerFlushesSummary(t *testing.T) {
+	var buf lockedBuffer
+	clock := testutils.NewStubClock(time.Unix(1000, 0))
+	logger := NewRateLimitedLogger(NewLogger(&buf), RateLimitedLoggerOptions{
+		Interval:  time.Second,
+		Burst:     1,
+		KeyFields: []string{"peer"},
+		TimeNow:   clock.Now,
+	})
+
+	// Start the periodic sweep before the hot warnings.
+	logger.Info("start")
+	clock.Elapse(100 * time.Millisecond)
+	for i := 0; i < 4; i++ {
+		logger.WithFields(LogField{"peer", "1"}, LogField{"conn", i}).Warn("hot warning")
+	}
+	assert.Len(t, logLines(&buf), 2, "expected the first message and burst of hot warnings")
+
+	// The summary is not logged until the interval ends.
+	clock.Elapse(900 * time.Millisecond)
+	logger.Info("before the interval ends")
+	assert.Len(t, logLines(&buf), 1, "summary logged before the interval ended")
+
+	// Once it ends, any message logs the summary, with only the key fields.
+	clock.Elapse(100 * time.Millisecond)
+	logger.Info("after the interval ends")
+	lines := logLines(&buf)
+	if assert.Len(t, lines, 2, "expected summary and message") {
+		assert.Contains(t, lines[0], "Suppressed 3 similar messages: hot warning")
+		assert.Contains(t, lines[0], "{peer 1}", "summary should have the key fields")
+		assert.NotContains(t, lines[0], "{conn ", "summary should not have other fields")
+	}
+
+	// Once the summary is logged, the next message starts a new interval
+	// and doesn't log the summary again.
+	logger.WithFields(LogField{"peer", "1"}).Warn("hot warning")
+	lines = logLines(&buf)
+	if assert.Len(t, lines, 1, "expected only the message") {
+		assert.NotContains(t, lines[0], "Suppressed")
+	}
+}
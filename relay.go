@@ -32,6 +32,8 @@ import (
 
 	"github.com/temporalio/tchannel-go/relay"
 	"github.com/temporalio/tchannel-go/typed"
+
+	"github.com/opentracing/opentracing-go"
 	"go.uber.org/atomic"
 )
 
@@ -43,6 +45,9 @@ const (
 	_relayTombTTL = 3 * time.Second
 	// _defaultRelayMaxTimeout is the default max TTL for relayed calls.
 	_defaultRelayMaxTimeout = 2 * time.Minute
+	// _relayMinTimeout is the smallest TTL that can be relayed, as TTLs are sent
+	// with millisecond resolution.
+	_relayMinTimeout = time.Millisecond
 )
 
 // Error strings.
@@ -123,6 +128,17 @@ func (r *relayItems) Count() int {
 	return n
 }
 
+// counts returns the number of non-tombstone items and tombstones in the relay.
+func (r *relayItems) counts() RelayItemSetCounts {
+	r.RLock()
+	counts := RelayItemSetCounts{
+		Items:      len(r.items) - int(r.tombs),
+		Tombstones: r.tombs,
+	}
+	r.RUnlock()
+	return counts
+}
+
 // Get checks for a relay item by ID, and will stop the timeout with the
 // read lock held (to avoid a race between timeout stop and deletion).
 // It returns whether a timeout was stopped, and if the item was found.
@@ -213,12 +229,19 @@ const (
 type Relayer struct {
 	relayHost      RelayHost
 	maxTimeout     time.Duration
+	minTimeout     time.Duration
 	maxConnTimeout time.Duration
+	tracing        bool
+	admin          *relayAdmin
 
 	// localHandlers is the set of service names that are handled by the local
 	// channel.
 	localHandler map[string]struct{}
 
+	// localHandlerMethods is the set of methods, keyed by service name, that
+	// are handled by the local channel.
+	localHandlerMethods map[string]map[string]struct{}
+
 	// outbound is the remapping for requests that originated on this
 	// connection, and are outbound towards some other connection.
 	// It stores remappings for all request frames read on this connection.
@@ -242,22 +265,27 @@ type Relayer struct {
 
 // NewRelayer constructs a Relayer.
 func NewRelayer(ch *Channel, conn *Connection) *Relayer {
+	logger := ch.relayLogLimiter.wrap(conn.log)
 	r := &Relayer{
-		relayHost:      ch.RelayHost(),
-		maxTimeout:     ch.relayMaxTimeout,
-		maxConnTimeout: ch.relayMaxConnTimeout,
-		localHandler:   ch.relayLocal,
-		outbound:       newRelayItems(conn.log.WithFields(LogField{"relayItems", "outbound"}), ch.relayMaxTombs),
-		inbound:        newRelayItems(conn.log.WithFields(LogField{"relayItems", "inbound"}), ch.relayMaxTombs),
-		peers:          ch.RootPeers(),
-		conn:           conn,
+		relayHost:           ch.RelayHost(),
+		maxTimeout:          ch.relayMaxTimeout,
+		minTimeout:          ch.relayMinTimeout,
+		maxConnTimeout:      ch.relayMaxConnTimeout,
+		tracing:             ch.relayTracing,
+		admin:               ch.relayAdmin,
+		localHandler:        ch.relayLocal,
+		localHandlerMethods: ch.relayLocalMethods,
+		outbound:            newRelayItems(logger.WithFields(LogField{"relayItems", "outbound"}), ch.relayMaxTombs),
+		inbound:             newRelayItems(logger.WithFields(LogField{"relayItems", "inbound"}), ch.relayMaxTombs),
+		peers:               ch.RootPeers(),
+		conn:                conn,
 		relayConn: &relay.Conn{
 			RemoteAddr:        conn.conn.RemoteAddr().String(),
 			RemoteProcessName: conn.RemotePeerInfo().ProcessName,
 			IsOutbound:        conn.connDirection == outbound,
 			Context:           conn.baseContext,
 		},
-		logger: conn.log,
+		logger: logger,
 	}
 	r.timeouts = newRelayTimerPool(r.timeoutRelayItem, ch.relayTimerVerify)
 	return r
@@ -404,6 +432,12 @@ func (r *Relayer) getDestination(f *lazyCallReq, call RelayCall) (*Connection, b
 		return nil, false, errBadRelayHost
 	}
 
+	if r.admin != nil && r.admin.isDraining(peer.HostPort()) {
+		call.Failed(_relayPeerDraining)
+		r.conn.SendSystemError(f.Header.ID, f.Span(), NewSystemError(ErrCodeDeclined, "selected peer %v is draining", peer.HostPort()))
+		return nil, false, nil
+	}
+
 	remoteConn, err := peer.getConnectionRelay(f.TTL(), r.maxConnTimeout)
 	if err != nil {
 		r.logger.WithFields(
@@ -426,7 +460,14 @@ func (r *Relayer) handleCallReq(f *lazyCallReq) (shouldRelease bool, _ error) {
 		return _relayNoRelease, nil
 	}
 
+	// Track time spent in the relay, so it can be deducted from the TTL.
+	relayStart := r.conn.timeNow()
+
 	call, err := r.relayHost.Start(f, r.relayConn)
+	var relaySpan opentracing.Span
+	if call != nil {
+		call, relaySpan = r.instrumentCall(f, call)
+	}
 	if err != nil {
 		// If we have a RateLimitDropError we record the statistic, but
 		// we *don't* send an error frame back to the client.
@@ -464,6 +505,10 @@ func (r *Relayer) handleCallReq(f *lazyCallReq) (shouldRelease bool, _ error) {
 
 	// Get a remote connection and check whether it can handle this call.
 	remoteConn, ok, err := r.getDestination(f, call)
+	var ttl time.Duration
+	if err == nil && ok {
+		ttl, ok = r.remainingTTL(f, call, relayStart)
+	}
 	if err == nil && ok {
 		if canHandle, state := remoteConn.relay.canHandleNewCall(); !canHandle {
 			err = NewWrappedSystemError(ErrCodeNetwork, errConnNotActive{"selected remote", state})
@@ -482,12 +527,13 @@ func (r *Relayer) handleCallReq(f *lazyCallReq) (shouldRelease bool, _ error) {
 
 	origID := f.Header.ID
 	destinationID := remoteConn.NextMessageID()
-	ttl := f.TTL()
-	if ttl > r.maxTimeout {
-		ttl = r.maxTimeout
-		f.SetTTL(r.maxTimeout)
-	}
+	f.SetTTL(ttl)
 	span := f.Span()
+	if relaySpan != nil {
+		// The original span is kept in the relay items for any errors sent back
+		// to the caller, but the destination should see the relay span as its parent.
+		setRelaySpan(f, relaySpan)
+	}
 
 	var mutatedChecksum Checksum
 	if len(f.arg2Appends) > 0 {
@@ -529,6 +575,41 @@ func (r *Relayer) handleCallReq(f *lazyCallReq) (shouldRelease bool, _ error) {
 	return _relayNoRelease, nil
 }
 
+// remainingTTL returns the TTL to forward for a call, which is the caller's
+// TTL clamped to the max timeout, less the time spent in the relay since
+// relayStart. If the remaining TTL is below the minimum, the call is failed
+// with a timeout and remainingTTL returns false.
+func (r *Relayer) remainingTTL(f *lazyCallReq, call RelayCall, relayStart time.Time) (time.Duration, bool) {
+	ttl := f.TTL()
+	if ttl > r.maxTimeout {
+		ttl = r.maxTimeout
+	}
+
+	elapsed := r.conn.timeNow().Sub(relayStart)
+	recordLatency(r.conn.statsReporter, "relay.calls.added-latency", r.conn.commonStatsTags, elapsed)
+
+	ttl -= elapsed
+	minTimeout := r.minTimeout
+	if minTimeout < _relayMinTimeout {
+		minTimeout = _relayMinTimeout
+	}
+	if ttl >= minTimeout {
+		return ttl, true
+	}
+
+	r.logger.WithFields(
+		LogField{"id", f.Header.ID},
+		LogField{"source", string(f.Caller())},
+		LogField{"dest", string(f.Service())},
+		LogField{"method", string(f.Method())},
+		LogField{"remainingTTL", ttl},
+		LogField{"relayLatency", elapsed},
+	).Info("Remaining TTL is too low to relay call.")
+	call.Failed(ErrCodeTimeout.relayMetricsKey())
+	r.conn.SendSystemError(f.Header.ID, f.Span(), ErrTimeout)
+	return 0, false
+}
+
 // Handle all frames except messageTypeCallReq.
 func (r *Relayer) handleNonCallReq(f *Frame) error {
 	frameType := frameTypeFor(f)
@@ -695,9 +776,24 @@ func (r *Relayer) receiverItems(fType frameType) *relayItems {
 	return r.outbound
 }
 
+// isLocalCall returns whether the call should be handled by the local channel,
+// either because its service or its method is handled locally.
+func (r *Relayer) isLocalCall(cr *lazyCallReq) bool {
+	if _, ok := r.localHandler[string(cr.Service())]; ok {
+		return true
+	}
+
+	methods, ok := r.localHandlerMethods[string(cr.Service())]
+	if !ok {
+		return false
+	}
+	_, ok = methods[string(cr.Method())]
+	return ok
+}
+
 func (r *Relayer) handleLocalCallReq(cr *lazyCallReq) (shouldRelease bool) {
-	// Check whether this is a service we want to handle locally.
-	if _, ok := r.localHandler[string(cr.Service())]; !ok {
+	// Check whether this is a service or method we want to handle locally.
+	if !r.isLocalCall(cr) {
 		return _relayNoRelease
 	}
 
@@ -723,6 +819,32 @@ func (r *Relayer) handleLocalCallReq(cr *lazyCallReq) (shouldRelease bool) {
 	return _relayShouldRelease
 }
 
+// instrumentCall wraps the RelayCall returned by the RelayHost to record
+// per-edge stats for the admin endpoints (if they are enabled) and histograms
+// (if the StatsReporter supports them), and to create a tracing span or log
+// the call, if either is enabled. It returns the relay span, which is nil if tracing is disabled or
+// the call has no parent span.
+func (r *Relayer) instrumentCall(f *lazyCallReq, call RelayCall) (RelayCall, opentracing.Span) {
+	retries, _ := call.(RelayRetryReporter)
+	if r.admin != nil {
+		call = newRelayAdminCall(call, r.admin, f)
+	}
+	if hr, ok := r.conn.statsReporter.(HistogramReporter); ok {
+		call = newRelayHistogramCall(call, hr, f, r.conn.commonStatsTags, r.conn.timeNow)
+	}
+
+	var span opentracing.Span
+	if r.tracing {
+		if span = r.startRelaySpan(f); span != nil {
+			call = &relayTracingCall{RelayCall: call, retries: retries, span: span, timeNow: r.conn.timeNow}
+		}
+	}
+	if r.conn.accessLogger != nil {
+		call = newAccessLogRelayCall(call, r.conn.accessLogger, f, r.relayConn.RemoteAddr, r.conn.timeNow)
+	}
+	return call, span
+}
+
 func (r *Relayer) fragmentingSend(call RelayCall, f *lazyCallReq, relayToDest relayItem, origID uint32) error {
 	if f.isArg2Fragmented {
 		return errFragmentedArg2WithAppend
@@ -0,0 +1,118 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// relayadmin calls the relay admin endpoints of a TChannel relay, which must
+// be created with RelayAdminEndpoints set.
+//
+// Usage:
+//
+//	relayadmin -relay host:port routes
+//	relayadmin -relay host:port stats
+//	relayadmin -relay host:port connections
+//	relayadmin -relay host:port drain <peer host:port>
+//	relayadmin -relay host:port undrain <peer host:port>
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/temporalio/tchannel-go"
+	tjson "github.com/temporalio/tchannel-go/json"
+)
+
+var (
+	relayHostPort = flag.String("relay", "", "The host:port of the relay")
+	timeout       = flag.Duration("timeout", tchannel.DefaultConnectTimeout, "Timeout for each request")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s -relay host:port routes|stats|connections|drain <peer>|undrain <peer>\n", os.Args[0])
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *relayHostPort == "" || flag.NArg() == 0 {
+		usage()
+	}
+
+	method, arg := parseCommand(flag.Args())
+	if err := run(method, arg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run calls the admin endpoint and prints the response. Errors are returned
+// rather than exiting, so the channel is closed first.
+func run(method string, arg interface{}) error {
+	ch, err := tchannel.NewChannel("relayadmin", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create channel: %v", err)
+	}
+	defer ch.Close()
+
+	ctx, cancel := tjson.NewContext(*timeout)
+	defer cancel()
+
+	client := tjson.NewClient(ch, "tchannel", &tjson.ClientOptions{HostPort: *relayHostPort})
+
+	var resp interface{}
+	if err := client.Call(ctx, method, arg, &resp); err != nil {
+		return fmt.Errorf("%v failed: %v", method, err)
+	}
+
+	out, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %v", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// parseCommand returns the admin endpoint and argument for the command line.
+func parseCommand(args []string) (method string, arg interface{}) {
+	switch cmd := args[0]; cmd {
+	case "routes":
+		return "_relay_routes", nil
+	case "stats":
+		return "_relay_stats", nil
+	case "connections":
+		return "_relay_connections", nil
+	case "drain", "undrain":
+		if len(args) != 2 {
+			usage()
+		}
+		return "_relay_drain", tchannel.RelayDrainOptions{
+			HostPort: args[1],
+			Undrain:  cmd == "undrain",
+		}
+	default:
+		log.Printf("unknown command %q", cmd)
+		usage()
+	}
+	return "", nil
+}
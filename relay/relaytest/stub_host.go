@@ -21,6 +21,8 @@
 package relaytest
 
 import (
+	"sort"
+
 	"github.com/temporalio/tchannel-go"
 	"github.com/temporalio/tchannel-go/relay"
 )
@@ -28,6 +30,7 @@ import (
 // Ensure that the StubRelayHost implements tchannel.RelayHost and stubCall implements
 // tchannel.RelayCall
 var _ tchannel.RelayHost = (*StubRelayHost)(nil)
+var _ tchannel.RelayHostIntrospector = (*StubRelayHost)(nil)
 var _ tchannel.RelayCall = (*stubCall)(nil)
 
 // StubRelayHost is a stub RelayHost for tests that backs peer selection to an
@@ -90,6 +93,18 @@ func (rh *StubRelayHost) Add(service, hostPort string) {
 	rh.ch.GetSubChannel(service, tchannel.Isolated).Peers().GetOrAdd(hostPort)
 }
 
+// IntrospectState returns the host:ports added for each service.
+func (rh *StubRelayHost) IntrospectState(opts *tchannel.IntrospectionOptions) interface{} {
+	routes := make(map[string][]string)
+	for svc, sc := range rh.ch.IntrospectState(opts).SubChannels {
+		for _, p := range sc.IsolatedPeers {
+			routes[svc] = append(routes[svc], p.HostPort)
+		}
+		sort.Strings(routes[svc])
+	}
+	return routes
+}
+
 // Stats returns the *MockStats tracked for this channel.
 func (rh *StubRelayHost) Stats() *MockStats {
 	return rh.stats
@@ -0,0 +1,410 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// The relay admin endpoints are registered under the "tchannel" service on
+// channels with a RelayHost and RelayAdminEndpoints set, and are always
+// handled by the relay itself.
+const (
+	_relayAdminRoutes      = "_relay_routes"
+	_relayAdminStats       = "_relay_stats"
+	_relayAdminConnections = "_relay_connections"
+	_relayAdminDrain       = "_relay_drain"
+)
+
+// _relayAdminMaxEdges is the maximum number of edges tracked by the relay.
+// Calls for any further edges are counted under _relayAdminOtherEdge.
+const _relayAdminMaxEdges = 10000
+
+// _relayAdminOtherEdge is used as the caller, service and destination of the
+// edge that counts calls once _relayAdminMaxEdges is reached.
+const _relayAdminOtherEdge = "_other"
+
+// _relayPeerDraining is the failure reason reported to the RelayCall when
+// the selected peer has been drained.
+const _relayPeerDraining = "relay-peer-draining"
+
+// RelayRoutesState is the routing state of the relay.
+type RelayRoutesState struct {
+	// Host is the state returned by the RelayHost, if it implements
+	// RelayHostIntrospector.
+	Host interface{} `json:"host,omitempty"`
+
+	// Peers is the list of peers known to the relay channel.
+	Peers []string `json:"peers"`
+
+	// Draining is the list of peers that have been drained.
+	Draining []string `json:"draining"`
+}
+
+// RelayEdgeStats are the call counts for calls between a caller and a
+// service that were forwarded to a single destination.
+type RelayEdgeStats struct {
+	Caller      string            `json:"caller"`
+	Service     string            `json:"service"`
+	Destination string            `json:"destination"`
+	Calls       uint64            `json:"calls"`
+	Succeeded   uint64            `json:"succeeded"`
+	Failed      uint64            `json:"failed"`
+	Failures    map[string]uint64 `json:"failures,omitempty"`
+}
+
+// RelayConnectionState is the relay state for a single connection.
+type RelayConnectionState struct {
+	ID             uint32             `json:"id"`
+	RemoteHostPort string             `json:"remoteHostPort"`
+	RemotePeer     PeerInfo           `json:"remotePeer"`
+	Inbound        RelayItemSetCounts `json:"inbound"`
+	Outbound       RelayItemSetCounts `json:"outbound"`
+}
+
+// RelayItemSetCounts are the number of active and tombstoned relay items.
+type RelayItemSetCounts struct {
+	Items      int    `json:"items"`
+	Tombstones uint64 `json:"tombstones"`
+}
+
+// RelayDrainOptions are the arguments to the drain endpoint.
+type RelayDrainOptions struct {
+	// HostPort is the destination peer to drain.
+	HostPort string `json:"hostPort"`
+
+	// Undrain allows new calls to be relayed to a previously drained peer.
+	Undrain bool `json:"undrain"`
+}
+
+// RelayDrainResult is the result of the drain endpoint.
+type RelayDrainResult struct {
+	HostPort string `json:"hostPort"`
+	Draining bool   `json:"draining"`
+}
+
+type relayEdge struct {
+	caller      string
+	service     string
+	destination string
+}
+
+// relayEdgeCounters are the call counts for a single edge. The counters are
+// atomic so that recording a call only needs a read lock on the edges map.
+type relayEdgeCounters struct {
+	calls     atomic.Uint64
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+
+	failuresMu sync.Mutex
+	failures   map[string]uint64
+}
+
+// relayAdmin tracks the relay state that is exposed over the admin endpoints.
+// It's shared by all relayers of a channel.
+type relayAdmin struct {
+	maxEdges int
+
+	edgesMu sync.RWMutex
+	edges   map[relayEdge]*relayEdgeCounters
+
+	drainingMu sync.RWMutex
+	draining   map[string]struct{}
+}
+
+func newRelayAdmin(maxEdges int) *relayAdmin {
+	return &relayAdmin{
+		maxEdges: maxEdges,
+		edges:    make(map[relayEdge]*relayEdgeCounters),
+		draining: make(map[string]struct{}),
+	}
+}
+
+func (a *relayAdmin) isDraining(hostPort string) bool {
+	a.drainingMu.RLock()
+	_, ok := a.draining[hostPort]
+	a.drainingMu.RUnlock()
+	return ok
+}
+
+func (a *relayAdmin) setDraining(hostPort string, draining bool) {
+	a.drainingMu.Lock()
+	if draining {
+		a.draining[hostPort] = struct{}{}
+	} else {
+		delete(a.draining, hostPort)
+	}
+	a.drainingMu.Unlock()
+}
+
+func (a *relayAdmin) drainingPeers() []string {
+	a.drainingMu.RLock()
+	peers := make([]string, 0, len(a.draining))
+	for hostPort := range a.draining {
+		peers = append(peers, hostPort)
+	}
+	a.drainingMu.RUnlock()
+
+	sort.Strings(peers)
+	return peers
+}
+
+// edgeCounters returns the counters for the given edge, creating them if
+// needed. Once maxEdges edges are tracked, new edges share a single edge.
+func (a *relayAdmin) edgeCounters(edge relayEdge) *relayEdgeCounters {
+	a.edgesMu.RLock()
+	counters, ok := a.edges[edge]
+	a.edgesMu.RUnlock()
+	if ok {
+		return counters
+	}
+
+	a.edgesMu.Lock()
+	defer a.edgesMu.Unlock()
+
+	if counters, ok := a.edges[edge]; ok {
+		return counters
+	}
+	if len(a.edges) >= a.maxEdges {
+		edge = relayEdge{
+			caller:      _relayAdminOtherEdge,
+			service:     _relayAdminOtherEdge,
+			destination: _relayAdminOtherEdge,
+		}
+		if counters, ok := a.edges[edge]; ok {
+			return counters
+		}
+	}
+
+	counters = &relayEdgeCounters{}
+	a.edges[edge] = counters
+	return counters
+}
+
+func (a *relayAdmin) record(edge relayEdge, succeeded bool, failure string) {
+	counters := a.edgeCounters(edge)
+	counters.calls.Inc()
+	if succeeded {
+		counters.succeeded.Inc()
+	}
+	if failure != "" {
+		counters.failed.Inc()
+
+		counters.failuresMu.Lock()
+		if counters.failures == nil {
+			counters.failures = make(map[string]uint64)
+		}
+		counters.failures[failure]++
+		counters.failuresMu.Unlock()
+	}
+}
+
+func (a *relayAdmin) edgeStats() []RelayEdgeStats {
+	a.edgesMu.RLock()
+	edges := make([]RelayEdgeStats, 0, len(a.edges))
+	for edge, counters := range a.edges {
+		s := RelayEdgeStats{
+			Caller:      edge.caller,
+			Service:     edge.service,
+			Destination: edge.destination,
+			Calls:       counters.calls.Load(),
+			Succeeded:   counters.succeeded.Load(),
+			Failed:      counters.failed.Load(),
+		}
+
+		counters.failuresMu.Lock()
+		if len(counters.failures) > 0 {
+			s.Failures = make(map[string]uint64, len(counters.failures))
+			for k, v := range counters.failures {
+				s.Failures[k] = v
+			}
+		}
+		counters.failuresMu.Unlock()
+
+		edges = append(edges, s)
+	}
+	a.edgesMu.RUnlock()
+
+	sort.Slice(edges, func(i, j int) bool {
+		ei, ej := edges[i], edges[j]
+		if ei.Caller != ej.Caller {
+			return ei.Caller < ej.Caller
+		}
+		if ei.Service != ej.Service {
+			return ei.Service < ej.Service
+		}
+		return ei.Destination < ej.Destination
+	})
+	return edges
+}
+
+// relayAdminCall wraps a RelayCall to record per-edge call counts.
+type relayAdminCall struct {
+	RelayCall
+
+	admin *relayAdmin
+
+	// The call's methods are called from different relay goroutines, so the
+	// edge and outcome are protected by mut.
+	mut       sync.Mutex
+	edge      relayEdge
+	succeeded bool
+	failure   string
+}
+
+func newRelayAdminCall(call RelayCall, admin *relayAdmin, f *lazyCallReq) *relayAdminCall {
+	return &relayAdminCall{
+		RelayCall: call,
+		admin:     admin,
+		edge: relayEdge{
+			caller:  string(f.Caller()),
+			service: string(f.Service()),
+		},
+	}
+}
+
+func (c *relayAdminCall) Destination() (*Peer, bool) {
+	peer, ok := c.RelayCall.Destination()
+	if ok && peer != nil {
+		c.mut.Lock()
+		c.edge.destination = peer.HostPort()
+		c.mut.Unlock()
+	}
+	return peer, ok
+}
+
+func (c *relayAdminCall) Succeeded() {
+	c.mut.Lock()
+	c.succeeded = true
+	c.mut.Unlock()
+	c.RelayCall.Succeeded()
+}
+
+func (c *relayAdminCall) Failed(reason string) {
+	c.mut.Lock()
+	if c.failure == "" {
+		c.failure = reason
+	}
+	c.mut.Unlock()
+	c.RelayCall.Failed(reason)
+}
+
+func (c *relayAdminCall) End() {
+	c.RelayCall.End()
+
+	c.mut.Lock()
+	edge, succeeded, failure := c.edge, c.succeeded && c.failure == "", c.failure
+	c.mut.Unlock()
+	c.admin.record(edge, succeeded, failure)
+}
+
+// relayAdminLocalMethods returns the admin endpoints in the service::method
+// format used by RelayLocalHandlerMethods.
+func relayAdminLocalMethods() []string {
+	return []string{
+		"tchannel::" + _relayAdminRoutes,
+		"tchannel::" + _relayAdminStats,
+		"tchannel::" + _relayAdminConnections,
+		"tchannel::" + _relayAdminDrain,
+	}
+}
+
+func (ch *Channel) relayAdminEndpoints() map[string]internalHandler {
+	return map[string]internalHandler{
+		_relayAdminRoutes:      infallible(ch.handleRelayRoutes),
+		_relayAdminStats:       infallible(ch.handleRelayStats),
+		_relayAdminConnections: infallible(ch.handleRelayConnections),
+		_relayAdminDrain:       ch.handleRelayDrain,
+	}
+}
+
+func (ch *Channel) handleRelayRoutes(arg3 []byte) interface{} {
+	var opts IntrospectionOptions
+	json.Unmarshal(arg3, &opts)
+
+	var state RelayRoutesState
+	if introspector, ok := ch.relayHost.(RelayHostIntrospector); ok {
+		state.Host = introspector.IntrospectState(&opts)
+	}
+
+	for hostPort := range ch.RootPeers().Copy() {
+		state.Peers = append(state.Peers, hostPort)
+	}
+	sort.Strings(state.Peers)
+	state.Draining = ch.relayAdmin.drainingPeers()
+	return state
+}
+
+func (ch *Channel) handleRelayStats(arg3 []byte) interface{} {
+	return ch.relayAdmin.edgeStats()
+}
+
+func (ch *Channel) handleRelayConnections(arg3 []byte) interface{} {
+	ch.mutable.RLock()
+	conns := make([]*Connection, 0, len(ch.mutable.conns))
+	for _, conn := range ch.mutable.conns {
+		conns = append(conns, conn)
+	}
+	ch.mutable.RUnlock()
+
+	states := make([]RelayConnectionState, 0, len(conns))
+	for _, conn := range conns {
+		if conn.relay == nil {
+			continue
+		}
+		states = append(states, RelayConnectionState{
+			ID:             conn.connID,
+			RemoteHostPort: conn.conn.RemoteAddr().String(),
+			RemotePeer:     conn.RemotePeerInfo(),
+			Inbound:        conn.relay.inbound.counts(),
+			Outbound:       conn.relay.outbound.counts(),
+		})
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	return states
+}
+
+func (ch *Channel) handleRelayDrain(arg3 []byte) (interface{}, error) {
+	var opts RelayDrainOptions
+	if err := json.Unmarshal(arg3, &opts); err != nil {
+		return nil, NewSystemError(ErrCodeBadRequest, "failed to parse drain options: %v", err)
+	}
+	if opts.HostPort == "" {
+		return nil, NewSystemError(ErrCodeBadRequest, `"hostPort" must be specified`)
+	}
+
+	draining := !opts.Undrain
+	ch.relayAdmin.setDraining(opts.HostPort, draining)
+	if drainer, ok := ch.relayHost.(RelayHostDrainer); ok {
+		drainer.SetDraining(opts.HostPort, draining)
+	}
+
+	ch.Logger().WithFields(
+		LogField{"hostPort", opts.HostPort},
+		LogField{"draining", draining},
+	).Info("Relay peer drain state updated.")
+	return RelayDrainResult{HostPort: opts.HostPort, Draining: draining}, nil
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/temporalio/tchannel-go"
+
+	"github.com/temporalio/tchannel-go/json"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/relay/relaytest"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callRelayAdmin(t testing.TB, client *Channel, hostPort, method string, arg, resp interface{}) {
+	ctx, cancel := json.NewContext(testutils.Timeout(time.Second))
+	defer cancel()
+
+	peer := client.Peers().GetOrAdd(hostPort)
+	require.NoError(t, json.CallPeer(ctx, peer, "tchannel", method, arg, resp), "%v failed", method)
+}
+
+func relayAdminOpts() *testutils.ChannelOpts {
+	opts := testutils.NewOpts().SetRelayOnly()
+	opts.RelayAdminEndpoints = true
+	return opts
+}
+
+func TestRelayAdmin(t *testing.T) {
+	opts := relayAdminOpts()
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		testutils.RegisterEcho(ts.Server(), nil)
+		client := ts.NewClient(nil)
+
+		callEcho := func() error {
+			ctx, cancel := NewContext(testutils.Timeout(time.Second))
+			defer cancel()
+			_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+			return err
+		}
+		for i := 0; i < 3; i++ {
+			require.NoError(t, callEcho(), "echo failed")
+		}
+
+		var routes RelayRoutesState
+		callRelayAdmin(t, client, ts.HostPort(), "_relay_routes", nil, &routes)
+		assert.Contains(t, routes.Peers, ts.Server().PeerInfo().HostPort, "missing server in relay peers")
+		assert.Empty(t, routes.Draining, "no peers should be draining")
+		assert.Equal(t, map[string]interface{}{
+			ts.ServiceName(): []interface{}{ts.Server().PeerInfo().HostPort},
+		}, routes.Host, "unexpected RelayHost state")
+
+		var conns []RelayConnectionState
+		callRelayAdmin(t, client, ts.HostPort(), "_relay_connections", nil, &conns)
+		require.NotEmpty(t, conns, "expected relay connections")
+		for _, c := range conns {
+			assert.Zero(t, c.Inbound.Items+c.Outbound.Items, "no calls should be active on %v", c.RemoteHostPort)
+		}
+
+		var drained RelayDrainResult
+		callRelayAdmin(t, client, ts.HostPort(), "_relay_drain", RelayDrainOptions{
+			HostPort: ts.Server().PeerInfo().HostPort,
+		}, &drained)
+		assert.True(t, drained.Draining, "peer should be draining")
+
+		err := callEcho()
+		assert.Equal(t, ErrCodeDeclined, GetSystemErrorCode(err), "calls to a drained peer should be declined")
+
+		callRelayAdmin(t, client, ts.HostPort(), "_relay_routes", nil, &routes)
+		assert.Equal(t, []string{ts.Server().PeerInfo().HostPort}, routes.Draining, "unexpected draining peers")
+
+		callRelayAdmin(t, client, ts.HostPort(), "_relay_drain", RelayDrainOptions{
+			HostPort: ts.Server().PeerInfo().HostPort,
+			Undrain:  true,
+		}, &drained)
+		assert.False(t, drained.Draining, "peer should not be draining")
+		require.NoError(t, callEcho(), "echo failed after undrain")
+
+		var edges []RelayEdgeStats
+		callRelayAdmin(t, client, ts.HostPort(), "_relay_stats", nil, &edges)
+		assert.Equal(t, []RelayEdgeStats{{
+			Caller:      client.ServiceName(),
+			Service:     ts.ServiceName(),
+			Destination: ts.Server().PeerInfo().HostPort,
+			Calls:       5,
+			Succeeded:   4,
+			Failed:      1,
+			Failures:    map[string]uint64{"relay-peer-draining": 1},
+		}}, edges, "unexpected edge stats")
+
+		// Admin calls are handled by the relay, and are not relayed.
+		calls := relaytest.NewMockStats()
+		for i := 0; i < 3; i++ {
+			calls.Add(client.ServiceName(), ts.ServiceName(), "echo").Succeeded().End()
+		}
+		calls.Add(client.ServiceName(), ts.ServiceName(), "echo").Failed("relay-peer-draining").End()
+		calls.Add(client.ServiceName(), ts.ServiceName(), "echo").Succeeded().End()
+		ts.AssertRelayStats(calls)
+	})
+}
+
+func TestRelayAdminDrainBadRequest(t *testing.T) {
+	testutils.WithTestServer(t, relayAdminOpts(), func(t testing.TB, ts *testutils.TestServer) {
+		client := ts.NewClient(nil)
+
+		tests := []struct {
+			msg  string
+			arg3 []byte
+		}{
+			{"missing hostPort", []byte(`{}`)},
+			{"invalid JSON", []byte(`{`)},
+		}
+
+		for _, tt := range tests {
+			ctx, cancel := NewContext(testutils.Timeout(time.Second))
+			_, _, _, err := raw.Call(ctx, client, ts.HostPort(), "tchannel", "_relay_drain", nil, tt.arg3)
+			cancel()
+			assert.Equal(t, ErrCodeBadRequest, GetSystemErrorCode(err), "%v: expected bad request, got %v", tt.msg, err)
+		}
+
+		var routes RelayRoutesState
+		callRelayAdmin(t, client, ts.HostPort(), "_relay_routes", nil, &routes)
+		assert.Empty(t, routes.Draining, "no peers should be draining")
+	})
+}
+
+func TestRelayAdminDisabledByDefault(t *testing.T) {
+	opts := testutils.NewOpts().SetRelayOnly()
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		client := ts.NewClient(nil)
+
+		ctx, cancel := json.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		var drained RelayDrainResult
+		err := json.CallPeer(ctx, client.Peers().GetOrAdd(ts.HostPort()), "tchannel", "_relay_drain", RelayDrainOptions{
+			HostPort: ts.Server().PeerInfo().HostPort,
+		}, &drained)
+		assert.Error(t, err, "relay admin endpoints should not be registered by default")
+		assert.False(t, drained.Draining, "peer should not be drained")
+	})
+}
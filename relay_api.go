@@ -61,3 +61,25 @@ type RelayCall interface {
 	// End stats collection for this RPC. Will be called exactly once.
 	End()
 }
+
+// RelayRetryReporter is an optional interface that a RelayCall may implement
+// to report how many times the RelayHost retried peer selection for the call.
+// If implemented, the count is recorded on relay tracing spans.
+type RelayRetryReporter interface {
+	Retries() int
+}
+
+// RelayHostIntrospector is an optional interface that a RelayHost may implement
+// to expose its routing state over the "_relay_routes" admin endpoint.
+// The returned value must be serializable as JSON.
+type RelayHostIntrospector interface {
+	IntrospectState(opts *IntrospectionOptions) interface{}
+}
+
+// RelayHostDrainer is an optional interface that a RelayHost may implement to
+// be notified when a destination peer is drained or undrained using the
+// "_relay_drain" admin endpoint. While a peer is drained, the relay rejects new
+// calls to it, so RelayHosts should avoid selecting it.
+type RelayHostDrainer interface {
+	SetDraining(hostPort string, draining bool)
+}
@@ -93,3 +93,19 @@ func TestRelayTimerPoolMisuse(t *testing.T) {
 		}, tt.msg)
 	}
 }
+
+func TestRelayAdminMaxEdges(t *testing.T) {
+	admin := newRelayAdmin(2)
+	admin.record(relayEdge{"c1", "s", "d"}, true, "")
+	admin.record(relayEdge{"c2", "s", "d"}, false, "failed")
+	admin.record(relayEdge{"c3", "s", "d"}, true, "")
+	admin.record(relayEdge{"c4", "s", "d"}, false, "failed")
+	admin.record(relayEdge{"c1", "s", "d"}, true, "")
+
+	assert.Equal(t, []RelayEdgeStats{
+		{Caller: _relayAdminOtherEdge, Service: _relayAdminOtherEdge, Destination: _relayAdminOtherEdge,
+			Calls: 2, Succeeded: 1, Failed: 1, Failures: map[string]uint64{"failed": 1}},
+		{Caller: "c1", Service: "s", Destination: "d", Calls: 2, Succeeded: 2},
+		{Caller: "c2", Service: "s", Destination: "d", Calls: 1, Failed: 1, Failures: map[string]uint64{"failed": 1}},
+	}, admin.edgeStats(), "calls beyond the edge limit should be counted under the other edge")
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// relayHistogramCall wraps a RelayCall to record the latency and sizes of
+// relayed calls as histograms.
+type relayHistogramCall struct {
+	RelayCall
+
+	reporter      HistogramReporter
+	tags          map[string]string
+	start         time.Time
+	timeNow       func() time.Time
+	requestBytes  atomic.Uint64
+	responseBytes atomic.Uint64
+}
+
+func newRelayHistogramCall(call RelayCall, reporter HistogramReporter, f *lazyCallReq, commonTags map[string]string, timeNow func() time.Time) *relayHistogramCall {
+	tags := cloneTags(commonTags)
+	tags["calling-service"] = string(f.Caller())
+	tags["target-service"] = string(f.Service())
+	return &relayHistogramCall{
+		RelayCall: call,
+		reporter:  reporter,
+		tags:      tags,
+		start:     timeNow(),
+		timeNow:   timeNow,
+	}
+}
+
+func (c *relayHistogramCall) SentBytes(n uint16) {
+	c.requestBytes.Add(uint64(n))
+	c.RelayCall.SentBytes(n)
+}
+
+func (c *relayHistogramCall) ReceivedBytes(n uint16) {
+	c.responseBytes.Add(uint64(n))
+	c.RelayCall.ReceivedBytes(n)
+}
+
+func (c *relayHistogramCall) End() {
+	c.RelayCall.End()
+
+	c.reporter.RecordDurationHistogram("relay.calls.latency", c.tags, c.timeNow().Sub(c.start))
+	c.reporter.RecordValueHistogram("relay.calls.request-bytes", c.tags, float64(c.requestBytes.Load()))
+	c.reporter.RecordValueHistogram("relay.calls.response-bytes", c.tags, float64(c.responseBytes.Load()))
+}
@@ -395,6 +395,69 @@ func TestLargeTimeoutsAreClamped(t *testing.T) {
 	})
 }
 
+func TestRelayDeductsRelayLatencyFromTTL(t *testing.T) {
+	const (
+		relayDelay = 100 * time.Millisecond
+		minTimeout = 500 * time.Millisecond
+	)
+
+	var serverHP atomic.String
+	slowHost := func(relay.CallFrame, *relay.Conn) (string, error) {
+		time.Sleep(relayDelay)
+		return serverHP.Load(), nil
+	}
+
+	statsReporter := newRecordingStatsReporter()
+	opts := testutils.NewOpts().
+		SetRelayOnly().
+		SetRelayHost(relaytest.HostFunc(slowHost)).
+		SetStatsReporter(statsReporter)
+	opts.RelayMinTimeout = minTimeout
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		serverHP.Store(ts.Server().PeerInfo().HostPort)
+		statsReporter.Reset()
+
+		var gotTTL atomic.Duration
+		testutils.RegisterFunc(ts.Server(), "echo", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			deadline, _ := ctx.Deadline()
+			gotTTL.Store(time.Until(deadline))
+			return &raw.Res{Arg2: args.Arg2, Arg3: args.Arg3}, nil
+		})
+
+		client := ts.NewClient(nil)
+		call := func(ttl time.Duration) error {
+			ctx, cancel := tchannel.NewContext(ttl)
+			defer cancel()
+			_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+			return err
+		}
+
+		require.NoError(t, call(2*time.Second), "call with enough budget failed")
+		assert.True(t, gotTTL.Load() <= 2*time.Second-relayDelay,
+			"expected relay latency to be deducted from TTL, got %v", gotTTL.Load())
+
+		err := call(minTimeout + relayDelay/2)
+		assert.Equal(t, tchannel.ErrCodeTimeout, tchannel.GetSystemErrorCode(err),
+			"expected timeout for call with remaining TTL below the minimum")
+
+		var latencies []time.Duration
+		statsReporter.Lock()
+		for _, v := range statsReporter.Values["relay.calls.added-latency"] {
+			latencies = append(latencies, v.timers...)
+		}
+		statsReporter.Unlock()
+		require.Len(t, latencies, 2, "expected relay latency to be recorded for each call")
+		for _, l := range latencies {
+			assert.True(t, l >= relayDelay, "relay latency %v should include the RelayHost delay", l)
+		}
+
+		calls := relaytest.NewMockStats()
+		calls.Add(client.ServiceName(), ts.ServiceName(), "echo").Succeeded().End()
+		calls.Add(client.ServiceName(), ts.ServiceName(), "echo").Failed("relay-timeout").End()
+		ts.AssertRelayStats(calls)
+	})
+}
+
 // TestRelayConcurrentCalls makes many concurrent calls and ensures that
 // we don't try to reuse any frames once they've been released.
 func TestRelayConcurrentCalls(t *testing.T) {
@@ -474,6 +537,50 @@ func TestRelayHandleLocalCall(t *testing.T) {
 	})
 }
 
+func TestRelayHandleLocalMethodCall(t *testing.T) {
+	opts := testutils.NewOpts().SetRelayOnly().
+		SetRelayLocalMethods("s2::local")
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		s2 := ts.NewServer(serviceNameOpts("s2"))
+		testutils.RegisterFunc(s2, "local", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{Arg3: []byte("s2")}, nil
+		})
+		testutils.RegisterFunc(s2, "remote", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{Arg3: []byte("s2")}, nil
+		})
+		testutils.RegisterFunc(ts.Relay().GetSubChannel("s2"), "local", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{Arg3: []byte("relay")}, nil
+		})
+
+		client := ts.NewClient(nil)
+		for _, tt := range []struct {
+			method string
+			want   string
+		}{
+			{"local", "relay"},
+			{"remote", "s2"},
+		} {
+			ctx, cancel := tchannel.NewContext(testutils.Timeout(time.Second))
+			_, arg3, _, err := raw.Call(ctx, client, ts.HostPort(), "s2", tt.method, nil, nil)
+			cancel()
+			require.NoError(t, err, "call to %v failed", tt.method)
+			assert.Equal(t, tt.want, string(arg3), "unexpected handler for %v", tt.method)
+		}
+
+		// Local methods are handled by the relay channel, so they do not show up in relay stats.
+		calls := relaytest.NewMockStats()
+		calls.Add(client.ServiceName(), "s2", "remote").Succeeded().End()
+		ts.AssertRelayStats(calls)
+	})
+}
+
+func TestRelayLocalHandlerMethodsInvalid(t *testing.T) {
+	_, err := tchannel.NewChannel("relay", &tchannel.ChannelOptions{
+		RelayLocalHandlerMethods: []string{"notDelimitedByDoubleColons"},
+	})
+	assert.EqualError(t, err, `each "RelayLocalHandlerMethods" value should be of service::Method format but got "notDelimitedByDoubleColons"`)
+}
+
 func TestRelayHandleLargeLocalCall(t *testing.T) {
 	opts := testutils.NewOpts().SetRelayOnly().
 		SetRelayLocal("relay").
@@ -0,0 +1,123 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import (
+	"time"
+
+	"github.com/temporalio/tchannel-go/typed"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// Tags set on relay spans, in addition to the standard OpenTracing tags.
+const (
+	_relayTagSource        = "relay.source"
+	_relayTagDestination   = "relay.destination"
+	_relayTagRoutingKey    = "relay.routing-key"
+	_relayTagRetries       = "relay.retries"
+	_relayTagFailureReason = "relay.failure"
+)
+
+// startRelaySpan starts a span for the relay hop of a call, as a child of the
+// span in the call frame. It returns nil if the call is not traced, or if the
+// tracer does not support Zipkin-style trace IDs.
+func (r *Relayer) startRelaySpan(f *lazyCallReq) opentracing.Span {
+	parent := f.Span()
+	if parent.TraceID() == 0 {
+		return nil
+	}
+
+	tracer := r.conn.Tracer()
+	spanCtx, err := tracer.Extract(zipkinSpanFormat, &parent)
+	if err != nil {
+		if err != opentracing.ErrUnsupportedFormat && err != opentracing.ErrSpanContextNotFound {
+			r.logger.WithFields(ErrField(err)).Error("Failed to extract Zipkin-style span for relay.")
+		}
+		return nil
+	}
+	if spanCtx == nil {
+		return nil
+	}
+
+	span := tracer.StartSpan(
+		string(f.Method()),
+		opentracing.ChildOf(spanCtx),
+		opentracing.StartTime(r.conn.timeNow()),
+	)
+	ext.Component.Set(span, "tchannel-relay")
+	ext.PeerService.Set(span, string(f.Service()))
+	span.SetTag("as", string(f.as))
+	span.SetTag(_relayTagSource, r.relayConn.RemoteAddr)
+	if rk := f.RoutingKey(); len(rk) > 0 {
+		span.SetTag(_relayTagRoutingKey, string(rk))
+	}
+	return span
+}
+
+// setRelaySpan overwrites the tracing field of the call frame with the IDs of
+// the relay span, so spans created by the destination are nested under it.
+func setRelaySpan(f *lazyCallReq, span opentracing.Span) {
+	var injectable injectableSpan
+	if err := injectable.initFromOpenTracing(span); err != nil || injectable.spanID == 0 {
+		return
+	}
+
+	relaySpan := Span(injectable)
+	relaySpan.parentID = f.Span().SpanID()
+	relaySpan.write(typed.NewWriteBuffer(f.Payload[_spanIndex : _spanIndex+_spanLength]))
+}
+
+// relayTracingCall wraps a RelayCall to record the relay hop in a span,
+// which is finished when the call ends.
+type relayTracingCall struct {
+	RelayCall
+
+	// retries is the RelayHost's call, if it reports retries. It's captured
+	// before any other wrappers, which don't forward Retries.
+	retries RelayRetryReporter
+	span    opentracing.Span
+	timeNow func() time.Time
+}
+
+func (c *relayTracingCall) Destination() (*Peer, bool) {
+	peer, ok := c.RelayCall.Destination()
+	if ok && peer != nil {
+		c.span.SetTag(_relayTagDestination, peer.HostPort())
+	}
+	return peer, ok
+}
+
+func (c *relayTracingCall) Failed(reason string) {
+	ext.Error.Set(c.span, true)
+	c.span.SetTag(_relayTagFailureReason, reason)
+	c.RelayCall.Failed(reason)
+}
+
+func (c *relayTracingCall) End() {
+	c.RelayCall.End()
+
+	if c.retries != nil {
+		c.span.SetTag(_relayTagRetries, c.retries.Retries())
+	}
+	c.span.FinishWithOptions(opentracing.FinishOptions{FinishTime: c.timeNow()})
+}
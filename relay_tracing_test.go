@@ -0,0 +1,179 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/temporalio/tchannel-go"
+
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/relay"
+	"github.com/temporalio/tchannel-go/relay/relaytest"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+)
+
+func findJaegerSpan(spans []*jaeger.Span, kind ext.SpanKindEnum, service string) *jaeger.Span {
+	for _, s := range spans {
+		tags := s.Tags()
+		if tags["span.kind"] == kind && tags["peer.service"] == service {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestRelayTracingSpans(t *testing.T) {
+	reporter := jaeger.NewInMemoryReporter()
+	tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), reporter)
+	defer closer.Close()
+
+	opts := testutils.NewOpts().SetRelayOnly()
+	opts.Tracer = tracer
+	opts.RelayTracing = true
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		reporter.Reset()
+		testutils.RegisterEcho(ts.Server(), nil)
+
+		clientOpts := testutils.NewOpts()
+		clientOpts.Tracer = tracer
+		client := ts.NewClient(clientOpts)
+		ctx, cancel := NewContextBuilder(time.Second).SetRoutingKey("rk").Build()
+		defer cancel()
+
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+		require.NoError(t, err, "echo failed")
+		_, _, _, err = raw.Call(ctx, client, ts.HostPort(), "unknown", "echo", nil, nil)
+		require.Error(t, err, "call to unknown service should fail")
+
+		// Spans are finished asynchronously, so wait for the client, relay and
+		// server spans of the echo, and the client and relay spans of the failed call.
+		var spans []*jaeger.Span
+		require.True(t, testutils.WaitFor(time.Second, func() bool {
+			spans = spans[:0]
+			for _, s := range reporter.GetSpans() {
+				spans = append(spans, s.(*jaeger.Span))
+			}
+			return len(spans) == 5
+		}), "expected 5 spans, got %v", reporter.GetSpans())
+
+		clientSpan := findJaegerSpan(spans, ext.SpanKindRPCClientEnum, ts.ServiceName())
+		serverSpan := findJaegerSpan(spans, ext.SpanKindRPCServerEnum, client.ServiceName())
+		var relaySpan, failedRelaySpan *jaeger.Span
+		for _, s := range spans {
+			if s.Tags()["component"] != "tchannel-relay" {
+				continue
+			}
+			if s.Tags()["peer.service"] == "unknown" {
+				failedRelaySpan = s
+			} else {
+				relaySpan = s
+			}
+		}
+		require.NotNil(t, clientSpan, "missing client span")
+		require.NotNil(t, serverSpan, "missing server span")
+		require.NotNil(t, relaySpan, "missing relay span")
+		require.NotNil(t, failedRelaySpan, "missing relay span for failed call")
+
+		assert.Equal(t, clientSpan.SpanContext().SpanID(), relaySpan.SpanContext().ParentID(),
+			"relay span should be a child of the client span")
+		assert.Equal(t, relaySpan.SpanContext().SpanID(), serverSpan.SpanContext().ParentID(),
+			"server span should be a child of the relay span")
+		assert.Equal(t, clientSpan.SpanContext().TraceID(), serverSpan.SpanContext().TraceID(),
+			"trace ID should be propagated")
+
+		tags := relaySpan.Tags()
+		assert.Equal(t, "echo", relaySpan.OperationName(), "operation name mismatch")
+		assert.Equal(t, ts.Server().PeerInfo().HostPort, tags["relay.destination"], "destination tag mismatch")
+		assert.NotEmpty(t, tags["relay.source"], "missing source tag")
+		assert.Equal(t, "rk", tags["relay.routing-key"], "routing key tag mismatch")
+		assert.Nil(t, tags["error"], "successful relay span should not be an error")
+
+		failedTags := failedRelaySpan.Tags()
+		assert.Equal(t, true, failedTags["error"], "failed relay span should be an error")
+		assert.Equal(t, "relay-declined", failedTags["relay.failure"], "failure reason mismatch")
+	})
+}
+
+// retryingRelayHost is a RelayHost whose calls report a fixed number of retries.
+type retryingRelayHost struct {
+	*relaytest.StubRelayHost
+	retries int
+}
+
+type retryingRelayCall struct {
+	RelayCall
+	retries int
+}
+
+func (c retryingRelayCall) Retries() int { return c.retries }
+
+func (rh retryingRelayHost) Start(cf relay.CallFrame, conn *relay.Conn) (RelayCall, error) {
+	call, err := rh.StubRelayHost.Start(cf, conn)
+	if call != nil {
+		call = retryingRelayCall{call, rh.retries}
+	}
+	return call, err
+}
+
+func TestRelayTracingRetries(t *testing.T) {
+	reporter := jaeger.NewInMemoryReporter()
+	tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), reporter)
+	defer closer.Close()
+
+	rh := retryingRelayHost{relaytest.NewStubRelayHost(), 2}
+	opts := testutils.NewOpts().SetRelayOnly().SetRelayHost(rh)
+	opts.Tracer = tracer
+	opts.RelayTracing = true
+	// The admin endpoints wrap the RelayHost's call before tracing does.
+	opts.RelayAdminEndpoints = true
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		reporter.Reset()
+		testutils.RegisterEcho(ts.Server(), nil)
+		rh.Add(ts.ServiceName(), ts.Server().PeerInfo().HostPort)
+
+		clientOpts := testutils.NewOpts()
+		clientOpts.Tracer = tracer
+		client := ts.NewClient(clientOpts)
+		ctx, cancel := NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+		require.NoError(t, err, "echo failed")
+
+		var relaySpan *jaeger.Span
+		require.True(t, testutils.WaitFor(time.Second, func() bool {
+			for _, s := range reporter.GetSpans() {
+				if span := s.(*jaeger.Span); span.Tags()["component"] == "tchannel-relay" {
+					relaySpan = span
+				}
+			}
+			return relaySpan != nil
+		}), "missing relay span")
+		assert.Equal(t, 2, relaySpan.Tags()["relay.retries"], "retries tag mismatch")
+	})
+}
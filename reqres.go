@@ -24,6 +24,8 @@ import (
 	"fmt"
 
 	"github.com/temporalio/tchannel-go/typed"
+
+	"go.uber.org/atomic"
 )
 
 type errReqResWriterStateMismatch struct {
@@ -73,6 +75,11 @@ type reqResWriter struct {
 	messageForFragment messageForFragment
 	log                Logger
 	err                error
+
+	// bytesWritten is the total size of all frames sent by this writer. It is
+	// atomic since the response may complete while the request is still being
+	// flushed.
+	bytesWritten atomic.Uint64
 }
 
 //go:generate stringer -type=reqResReaderState
@@ -144,6 +151,9 @@ func (w *reqResWriter) flushFragment(fragment *writableFragment) error {
 	frame := fragment.frame
 	frame.Header.SetPayloadSize(uint16(fragment.contents.BytesWritten()))
 
+	// The frame may be released once it's sent, so get the size beforehand.
+	frameSize := uint64(frame.Header.FrameSize())
+
 	if err := w.mex.checkError(); err != nil {
 		return w.failed(err)
 	}
@@ -153,6 +163,7 @@ func (w *reqResWriter) flushFragment(fragment *writableFragment) error {
 	case <-w.mex.errCh.c:
 		return w.failed(w.mex.errCh.err)
 	case w.conn.sendCh <- frame:
+		w.bytesWritten.Add(frameSize)
 		return nil
 	}
 }
@@ -189,6 +200,9 @@ type reqResReader struct {
 	previousFragment   *readableFragment
 	log                Logger
 	err                error
+
+	// bytesRead is the total size of all frames received by this reader.
+	bytesRead uint64
 }
 
 // arg1Reader returns an ArgReader to read arg1.
@@ -245,6 +259,8 @@ func (r *reqResReader) recvNextFragment(initial bool) (*readableFragment, error)
 		return nil, r.failed(err)
 	}
 
+	r.bytesRead += uint64(frame.Header.FrameSize())
+
 	// Parse the message and setup the fragment
 	fragment, err := parseInboundFragment(r.mex.framePool, frame, message)
 	if err != nil {
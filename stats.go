@@ -59,3 +59,56 @@ func (simpleStatsReporter) UpdateGauge(name string, tags map[string]string, valu
 func (simpleStatsReporter) RecordTimer(name string, tags map[string]string, d time.Duration) {
 	log.Printf("Stats: RecordTimer(%v, %v) = %v", name, tags, d)
 }
+
+// HistogramReporter is an optional interface that a StatsReporter may implement
+// to record distributions as histograms. If implemented, latencies are recorded
+// using RecordDurationHistogram instead of RecordTimer, and the sizes of calls
+// are recorded using RecordValueHistogram.
+type HistogramReporter interface {
+	// RecordDurationHistogram records a duration, such as a call's latency.
+	RecordDurationHistogram(name string, tags map[string]string, d time.Duration)
+
+	// RecordValueHistogram records a value, such as a call's size in bytes.
+	RecordValueHistogram(name string, tags map[string]string, value float64)
+}
+
+// DefaultDurationBuckets are the default bucket upper bounds used by
+// reporters for duration histograms.
+var DefaultDurationBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// DefaultSizeBuckets are the default bucket upper bounds, in bytes, used by
+// reporters for call size histograms.
+var DefaultSizeBuckets = []float64{
+	64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20,
+}
+
+// recordLatency records d as a histogram if the reporter supports histograms,
+// and as a timer otherwise.
+func recordLatency(r StatsReporter, name string, tags map[string]string, d time.Duration) {
+	if hr, ok := r.(HistogramReporter); ok {
+		hr.RecordDurationHistogram(name, tags, d)
+		return
+	}
+	r.RecordTimer(name, tags, d)
+}
+
+// recordSize records a size in bytes if the reporter supports histograms.
+func recordSize(r StatsReporter, name string, tags map[string]string, size uint64) {
+	if hr, ok := r.(HistogramReporter); ok {
+		hr.RecordValueHistogram(name, tags, float64(size))
+	}
+}
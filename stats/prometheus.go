@@ -0,0 +1,368 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package stats
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	_defaultPrometheusNamespace = "tchannel"
+	_defaultMaxLabelValues      = 256
+
+	// OverflowLabelValue is the label value used once a label has reached
+	// PrometheusOptions.MaxLabelValues distinct values.
+	OverflowLabelValue = "_overflow"
+)
+
+// DefaultPrometheusLabels is the list of TChannel tags that are reported as
+// labels by default. Tags that are constant for a process, such as "host" and
+// "app", are left out as they are typically added as target labels.
+var DefaultPrometheusLabels = []string{
+	"service",
+	"calling-service",
+	"endpoint",
+	"target-service",
+	"target-endpoint",
+	"retry-count",
+}
+
+// PrometheusOptions are used to configure a PrometheusReporter.
+type PrometheusOptions struct {
+	// Registerer is used to register metrics. If nil, a new registry is
+	// created and used as both the Registerer and Gatherer.
+	Registerer prometheus.Registerer
+
+	// Gatherer is used by Handler to expose metrics. If nil, the Registerer
+	// is used if it's also a Gatherer.
+	Gatherer prometheus.Gatherer
+
+	// Namespace is prefixed to all metric names, defaults to "tchannel".
+	Namespace string
+
+	// Labels is the list of tags that are reported as labels, defaults to
+	// DefaultPrometheusLabels. Tags that are not in this list are dropped.
+	Labels []string
+
+	// MaxLabelValues limits the number of distinct values reported for each
+	// label. Further values are reported as OverflowLabelValue. This guards
+	// against high-cardinality tags such as peer host:ports. Defaults to 256.
+	MaxLabelValues int
+
+	// Buckets are the histogram buckets in seconds used for timers and
+	// duration histograms, defaults to tchannel.DefaultDurationBuckets.
+	Buckets []float64
+
+	// SizeBuckets are the histogram buckets used for value histograms such
+	// as call sizes, defaults to tchannel.DefaultSizeBuckets.
+	SizeBuckets []float64
+}
+
+// PrometheusReporter is a StatsReporter that reports to Prometheus.
+// Counters, gauges and timers are reported as counters, gauges and histograms
+// respectively, with the configured tags as labels. It implements
+// tchannel.HistogramReporter.
+type PrometheusReporter struct {
+	registerer  prometheus.Registerer
+	gatherer    prometheus.Gatherer
+	namespace   string
+	labels      []string
+	labelNames  []string
+	buckets     []float64
+	sizeBuckets []float64
+
+	mut        sync.RWMutex
+	counters   map[string]*prometheus.CounterVec
+	gauges     map[string]*prometheus.GaugeVec
+	histograms map[string]*prometheus.HistogramVec
+	values     map[string]*prometheus.HistogramVec
+	guards     []*labelGuard
+}
+
+var (
+	_ tchannel.StatsReporter     = (*PrometheusReporter)(nil)
+	_ tchannel.HistogramReporter = (*PrometheusReporter)(nil)
+)
+
+// labelGuard limits the number of distinct values for a single label.
+type labelGuard struct {
+	sync.RWMutex
+
+	max    int
+	values map[string]struct{}
+}
+
+// NewPrometheusReporter returns a StatsReporter that reports to Prometheus.
+func NewPrometheusReporter(opts PrometheusOptions) *PrometheusReporter {
+	if opts.Registerer == nil {
+		registry := prometheus.NewRegistry()
+		opts.Registerer = registry
+		if opts.Gatherer == nil {
+			opts.Gatherer = registry
+		}
+	}
+	if opts.Gatherer == nil {
+		if g, ok := opts.Registerer.(prometheus.Gatherer); ok {
+			opts.Gatherer = g
+		}
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = _defaultPrometheusNamespace
+	}
+	if opts.Labels == nil {
+		opts.Labels = DefaultPrometheusLabels
+	}
+	if opts.MaxLabelValues <= 0 {
+		opts.MaxLabelValues = _defaultMaxLabelValues
+	}
+	if opts.Buckets == nil {
+		opts.Buckets = make([]float64, len(tchannel.DefaultDurationBuckets))
+		for i, d := range tchannel.DefaultDurationBuckets {
+			opts.Buckets[i] = d.Seconds()
+		}
+	}
+	if opts.SizeBuckets == nil {
+		opts.SizeBuckets = tchannel.DefaultSizeBuckets
+	}
+
+	r := &PrometheusReporter{
+		registerer:  opts.Registerer,
+		gatherer:    opts.Gatherer,
+		namespace:   opts.Namespace,
+		labels:      opts.Labels,
+		labelNames:  make([]string, len(opts.Labels)),
+		buckets:     opts.Buckets,
+		sizeBuckets: opts.SizeBuckets,
+		counters:    make(map[string]*prometheus.CounterVec),
+		gauges:      make(map[string]*prometheus.GaugeVec),
+		histograms:  make(map[string]*prometheus.HistogramVec),
+		values:      make(map[string]*prometheus.HistogramVec),
+		guards:      make([]*labelGuard, len(opts.Labels)),
+	}
+	for i, label := range opts.Labels {
+		r.labelNames[i] = prometheusName(label)
+		r.guards[i] = &labelGuard{
+			max:    opts.MaxLabelValues,
+			values: make(map[string]struct{}),
+		}
+	}
+	return r
+}
+
+// Handler returns an http.Handler that exposes the reported metrics in the
+// Prometheus exposition format. It can be served over plain HTTP, or over
+// TChannel using the http package.
+func (r *PrometheusReporter) Handler() http.Handler {
+	if r.gatherer == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(r.gatherer, promhttp.HandlerOpts{})
+}
+
+// IncCounter increments the counter with the given name and tags.
+func (r *PrometheusReporter) IncCounter(name string, tags map[string]string, value int64) {
+	if c := r.getCounter(name); c != nil {
+		c.WithLabelValues(r.labelValues(tags)...).Add(float64(value))
+	}
+}
+
+// UpdateGauge sets the gauge with the given name and tags.
+func (r *PrometheusReporter) UpdateGauge(name string, tags map[string]string, value int64) {
+	if g := r.getGauge(name); g != nil {
+		g.WithLabelValues(r.labelValues(tags)...).Set(float64(value))
+	}
+}
+
+// RecordTimer records the duration in the histogram with the given name and tags.
+func (r *PrometheusReporter) RecordTimer(name string, tags map[string]string, d time.Duration) {
+	r.RecordDurationHistogram(name, tags, d)
+}
+
+// RecordDurationHistogram records the duration in the histogram with the given name and tags.
+func (r *PrometheusReporter) RecordDurationHistogram(name string, tags map[string]string, d time.Duration) {
+	if h := r.getHistogram(r.histograms, name, prometheusName(name)+"_seconds", r.buckets); h != nil {
+		h.WithLabelValues(r.labelValues(tags)...).Observe(d.Seconds())
+	}
+}
+
+// RecordValueHistogram records the value in the histogram with the given name and tags.
+func (r *PrometheusReporter) RecordValueHistogram(name string, tags map[string]string, value float64) {
+	if h := r.getHistogram(r.values, name, prometheusName(name), r.sizeBuckets); h != nil {
+		h.WithLabelValues(r.labelValues(tags)...).Observe(value)
+	}
+}
+
+func (r *PrometheusReporter) labelValues(tags map[string]string) []string {
+	values := make([]string, len(r.labels))
+	for i, label := range r.labels {
+		values[i] = r.guards[i].value(tags[label])
+	}
+	return values
+}
+
+func (r *PrometheusReporter) getCounter(name string) *prometheus.CounterVec {
+	r.mut.RLock()
+	c, ok := r.counters[name]
+	r.mut.RUnlock()
+	if ok {
+		return c
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	// Always double-check under the write-lock, as registering twice fails.
+	if c, ok := r.counters[name]; ok {
+		return c
+	}
+
+	c = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: r.namespace,
+		Name:      prometheusName(name) + "_total",
+		Help:      "TChannel counter " + name,
+	}, r.labelNames)
+	if collector := r.register(c); collector != nil {
+		c, _ = collector.(*prometheus.CounterVec)
+	} else {
+		c = nil
+	}
+	r.counters[name] = c
+	return c
+}
+
+func (r *PrometheusReporter) getGauge(name string) *prometheus.GaugeVec {
+	r.mut.RLock()
+	g, ok := r.gauges[name]
+	r.mut.RUnlock()
+	if ok {
+		return g
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	// Always double-check under the write-lock, as registering twice fails.
+	if g, ok := r.gauges[name]; ok {
+		return g
+	}
+
+	g = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: r.namespace,
+		Name:      prometheusName(name),
+		Help:      "TChannel gauge " + name,
+	}, r.labelNames)
+	if collector := r.register(g); collector != nil {
+		g, _ = collector.(*prometheus.GaugeVec)
+	} else {
+		g = nil
+	}
+	r.gauges[name] = g
+	return g
+}
+
+func (r *PrometheusReporter) getHistogram(m map[string]*prometheus.HistogramVec, name, fullName string, buckets []float64) *prometheus.HistogramVec {
+	r.mut.RLock()
+	h, ok := m[name]
+	r.mut.RUnlock()
+	if ok {
+		return h
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	// Always double-check under the write-lock, as registering twice fails.
+	if h, ok := m[name]; ok {
+		return h
+	}
+
+	h = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: r.namespace,
+		Name:      fullName,
+		Help:      "TChannel histogram " + name,
+		Buckets:   buckets,
+	}, r.labelNames)
+	if collector := r.register(h); collector != nil {
+		h, _ = collector.(*prometheus.HistogramVec)
+	} else {
+		h = nil
+	}
+	m[name] = h
+	return h
+}
+
+// register registers the collector, and returns the collector to use, which
+// may be a previously registered collector. If the collector cannot be
+// registered (e.g. it conflicts with another metric), it returns nil and
+// updates for the metric are dropped.
+func (r *PrometheusReporter) register(c prometheus.Collector) prometheus.Collector {
+	if err := r.registerer.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		return nil
+	}
+	return c
+}
+
+// value returns the label value to report for v, which is OverflowLabelValue
+// once the label has reached the maximum number of distinct values.
+func (g *labelGuard) value(v string) string {
+	g.RLock()
+	_, ok := g.values[v]
+	g.RUnlock()
+	if ok {
+		return v
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if _, ok := g.values[v]; ok {
+		return v
+	}
+	if len(g.values) >= g.max {
+		return OverflowLabelValue
+	}
+	g.values[v] = struct{}{}
+	return v
+}
+
+// prometheusName converts a TChannel metric or tag name to a valid Prometheus
+// name by replacing any unsupported characters with underscores.
+func prometheusName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,154 @@
+package stats
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/tchannel-go/testutils"
+)
+
+func scrape(t *testing.T, r *PrometheusReporter) string {
+	server := httptest.NewServer(r.Handler())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err, "scrape failed")
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err, "failed to read scrape response")
+	return string(body)
+}
+
+func TestPrometheusName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"inbound.calls.recvd", "inbound_calls_recvd"},
+		{"calling-service", "calling_service"},
+		{"already_valid_1", "already_valid_1"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, prometheusName(tt.name), "unexpected name for %v", tt.name)
+	}
+}
+
+func TestPrometheusReporter(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r := NewPrometheusReporter(PrometheusOptions{Registerer: registry})
+
+	tags := map[string]string{
+		"service":         "foo",
+		"calling-service": "bar",
+		"endpoint":        "ep",
+
+		// ignored tag
+		"host": "localhost",
+	}
+	for i := 0; i < 3; i++ {
+		r.IncCounter("inbound.calls.recvd", tags, 2)
+		r.RecordTimer("inbound.calls.latency", tags, time.Second)
+	}
+	r.UpdateGauge("num-connections", map[string]string{"service": "foo"}, 5)
+
+	counter := r.getCounter("inbound.calls.recvd")
+	assert.Equal(t, 6.0, testutil.ToFloat64(counter.WithLabelValues("foo", "bar", "ep", "", "", "")))
+	gauge := r.getGauge("num-connections")
+	assert.Equal(t, 5.0, testutil.ToFloat64(gauge.WithLabelValues("foo", "", "", "", "", "")))
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `tchannel_inbound_calls_recvd_total{calling_service="bar",endpoint="ep",retry_count="",service="foo",target_endpoint="",target_service=""} 6`)
+	assert.Contains(t, body, `tchannel_inbound_calls_latency_seconds_count{calling_service="bar",endpoint="ep",retry_count="",service="foo",target_endpoint="",target_service=""} 3`)
+	assert.Contains(t, body, `tchannel_num_connections{calling_service="",endpoint="",retry_count="",service="foo",target_endpoint="",target_service=""} 5`)
+	assert.NotContains(t, body, "localhost", "tags not in Labels should be dropped")
+}
+
+func TestPrometheusReporterHistograms(t *testing.T) {
+	r := NewPrometheusReporter(PrometheusOptions{
+		Labels:      []string{"service"},
+		SizeBuckets: []float64{100, 1000},
+	})
+
+	tags := map[string]string{"service": "foo"}
+	r.RecordTimer("outbound.calls.latency", tags, 20*time.Millisecond)
+	r.RecordDurationHistogram("outbound.calls.latency", tags, 2*time.Second)
+	r.RecordValueHistogram("outbound.calls.request-bytes", tags, 500)
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `tchannel_outbound_calls_latency_seconds_bucket{service="foo",le="0.025"} 1`)
+	assert.Contains(t, body, `tchannel_outbound_calls_latency_seconds_count{service="foo"} 2`,
+		"timers and duration histograms should share a metric")
+	assert.Contains(t, body, `tchannel_outbound_calls_request_bytes_bucket{service="foo",le="100"} 0`)
+	assert.Contains(t, body, `tchannel_outbound_calls_request_bytes_bucket{service="foo",le="1000"} 1`)
+}
+
+func TestPrometheusReporterSharedRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r1 := NewPrometheusReporter(PrometheusOptions{Registerer: registry})
+	r2 := NewPrometheusReporter(PrometheusOptions{Registerer: registry})
+
+	tags := map[string]string{"service": "foo"}
+	r1.IncCounter("outbound.calls.send", tags, 1)
+	r2.IncCounter("outbound.calls.send", tags, 1)
+
+	counter := r1.getCounter("outbound.calls.send")
+	assert.Equal(t, 2.0, testutil.ToFloat64(counter.WithLabelValues("foo", "", "", "", "", "")),
+		"reporters sharing a registry should share metrics")
+}
+
+func TestPrometheusReporterConflictingMetric(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r := NewPrometheusReporter(PrometheusOptions{Registerer: registry})
+
+	// Register a metric with the same name but different labels.
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tchannel_outbound_calls_send_total",
+	}))
+
+	assert.NotPanics(t, func() {
+		r.IncCounter("outbound.calls.send", map[string]string{"service": "foo"}, 1)
+	}, "conflicting metrics should be dropped")
+}
+
+func TestPrometheusReporterCardinality(t *testing.T) {
+	r := NewPrometheusReporter(PrometheusOptions{
+		Labels:         []string{"target-service"},
+		MaxLabelValues: 2,
+	})
+
+	for _, svc := range []string{"s1", "s2", "s3", "s4", "s1"} {
+		r.IncCounter("outbound.calls.send", map[string]string{"target-service": svc}, 1)
+	}
+
+	counter := r.getCounter("outbound.calls.send")
+	assert.Equal(t, 2.0, testutil.ToFloat64(counter.WithLabelValues("s1")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(counter.WithLabelValues("s2")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(counter.WithLabelValues(OverflowLabelValue)))
+	assert.Equal(t, 3, testutil.CollectAndCount(counter), "unexpected number of series")
+}
+
+func TestPrometheusIntegration(t *testing.T) {
+	serverReporter := NewPrometheusReporter(PrometheusOptions{})
+
+	server := testutils.NewServer(t, testutils.NewOpts().SetStatsReporter(serverReporter))
+	defer server.Close()
+	testutils.RegisterEcho(server, nil)
+
+	client := testutils.NewClient(t, nil)
+	defer client.Close()
+
+	testutils.AssertEcho(t, client, server.PeerInfo().HostPort, server.ServiceName())
+
+	labels := `{calling_service="testService-client",endpoint="echo",retry_count="",service="testService",target_endpoint="",target_service=""}`
+	body := scrape(t, serverReporter)
+	assert.Contains(t, body, "tchannel_inbound_calls_recvd_total"+labels+" 1")
+	assert.Contains(t, body, "tchannel_inbound_calls_latency_seconds_count"+labels+" 1")
+}
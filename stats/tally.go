@@ -28,9 +28,26 @@ type taggedScope struct {
 
 	scope tally.Scope // already tagged with some set of tags
 
-	counters map[string]tally.Counter
-	gauges   map[string]tally.Gauge
-	timers   map[string]tally.Timer
+	counters   map[string]tally.Counter
+	gauges     map[string]tally.Gauge
+	timers     map[string]tally.Timer
+	histograms map[string]tally.Histogram
+}
+
+type histogramWrapper struct {
+	*wrapper
+
+	durationBuckets tally.Buckets
+	sizeBuckets     tally.Buckets
+}
+
+// TallyHistogramOptions are the bucket options used by NewTallyHistogramReporter.
+type TallyHistogramOptions struct {
+	// DurationBuckets defaults to tchannel.DefaultDurationBuckets.
+	DurationBuckets []time.Duration
+
+	// SizeBuckets defaults to tchannel.DefaultSizeBuckets.
+	SizeBuckets []float64
 }
 
 // NewTallyReporter takes a tally.Scope and wraps it so it ca be used as a
@@ -45,6 +62,37 @@ func NewTallyReporter(scope tally.Scope) tchannel.StatsReporter {
 	}
 }
 
+// NewTallyHistogramReporter is similar to NewTallyReporter, but the returned
+// StatsReporter also implements tchannel.HistogramReporter, so latencies and
+// call sizes are reported as tally histograms rather than timers.
+func NewTallyHistogramReporter(scope tally.Scope, opts TallyHistogramOptions) tchannel.StatsReporter {
+	if opts.DurationBuckets == nil {
+		opts.DurationBuckets = tchannel.DefaultDurationBuckets
+	}
+	if opts.SizeBuckets == nil {
+		opts.SizeBuckets = tchannel.DefaultSizeBuckets
+	}
+
+	return &histogramWrapper{
+		wrapper: &wrapper{
+			scope:  scope,
+			byTags: make(map[knownTags]*taggedScope),
+		},
+		durationBuckets: tally.DurationBuckets(opts.DurationBuckets),
+		sizeBuckets:     tally.ValueBuckets(opts.SizeBuckets),
+	}
+}
+
+func (w *histogramWrapper) RecordDurationHistogram(name string, tags map[string]string, d time.Duration) {
+	ts := w.getTaggedScope(tags)
+	ts.getHistogram(name, w.durationBuckets).RecordDuration(d)
+}
+
+func (w *histogramWrapper) RecordValueHistogram(name string, tags map[string]string, value float64) {
+	ts := w.getTaggedScope(tags)
+	ts.getHistogram(name, w.sizeBuckets).RecordValue(value)
+}
+
 func (w *wrapper) IncCounter(name string, tags map[string]string, value int64) {
 	ts := w.getTaggedScope(tags)
 	ts.getCounter(name).Inc(value)
@@ -79,10 +127,11 @@ func (w *wrapper) getTaggedScope(tags map[string]string) *taggedScope {
 	}
 
 	ts = &taggedScope{
-		scope:    w.scope.Tagged(kt.tallyTags()),
-		counters: make(map[string]tally.Counter),
-		gauges:   make(map[string]tally.Gauge),
-		timers:   make(map[string]tally.Timer),
+		scope:      w.scope.Tagged(kt.tallyTags()),
+		counters:   make(map[string]tally.Counter),
+		gauges:     make(map[string]tally.Gauge),
+		timers:     make(map[string]tally.Timer),
+		histograms: make(map[string]tally.Histogram),
 	}
 	w.byTags[kt] = ts
 	return ts
@@ -186,3 +235,21 @@ func (ts *taggedScope) getTimer(name string) tally.Timer {
 	ts.timers[name] = timer
 	return timer
 }
+
+func (ts *taggedScope) getHistogram(name string, buckets tally.Buckets) tally.Histogram {
+	ts.RLock()
+	histogram, ok := ts.histograms[name]
+	ts.RUnlock()
+	if ok {
+		return histogram
+	}
+
+	ts.Lock()
+	defer ts.Unlock()
+
+	// No double-check under the lock, as overwriting the histogram has
+	// no impact.
+	histogram = ts.scope.Histogram(name, buckets)
+	ts.histograms[name] = histogram
+	return histogram
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/tchannel-go"
 	"github.com/uber-go/tally"
 	"github.com/temporalio/tchannel-go/testutils"
 )
@@ -102,6 +104,37 @@ func TestNewTallyReporter(t *testing.T) {
 	assert.Equal(t, want.Snapshot(), scope.Snapshot())
 }
 
+func TestNewTallyHistogramReporter(t *testing.T) {
+	want := tally.NewTestScope("" /* prefix */, nil /* tags */)
+	scope := tally.NewTestScope("" /* prefix */, nil /* tags */)
+	wrapped := NewTallyHistogramReporter(scope, TallyHistogramOptions{})
+
+	hr, ok := wrapped.(tchannel.HistogramReporter)
+	require.True(t, ok, "reporter should implement HistogramReporter")
+
+	tags := map[string]string{
+		"service":         "foo",
+		"calling-service": "bar",
+		"endpoint":        "ep",
+	}
+	wantTags := map[string]string{
+		"dest":      "foo",
+		"source":    "bar",
+		"procedure": "ep",
+	}
+	for i := 0; i < 10; i++ {
+		hr.RecordDurationHistogram("inbound.calls.latency", tags, time.Second)
+		want.Tagged(wantTags).Histogram("inbound.calls.latency", tally.DurationBuckets(tchannel.DefaultDurationBuckets)).
+			RecordDuration(time.Second)
+
+		hr.RecordValueHistogram("inbound.calls.request-bytes", tags, 100)
+		want.Tagged(wantTags).Histogram("inbound.calls.request-bytes", tally.ValueBuckets(tchannel.DefaultSizeBuckets)).
+			RecordValue(100)
+	}
+
+	assert.Equal(t, want.Snapshot(), scope.Snapshot())
+}
+
 func TestTallyIntegration(t *testing.T) {
 	clientScope := tally.NewTestScope("" /* prefix */, nil /* tags */)
 	serverScope := tally.NewTestScope("" /* prefix */, nil /* tags */)
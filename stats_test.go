@@ -99,9 +99,12 @@ func TestStatsCalls(t *testing.T) {
 
 		clientStats := newRecordingStatsReporter()
 		serverStats := newRecordingStatsReporter()
+		// The relay shares the server's stats reporter, so relayed calls are
+		// covered separately by TestStatsRelayCalls.
 		serverOpts := testutils.NewOpts().
 			SetStatsReporter(serverStats).
-			SetTimeNow(serverClock.Now)
+			SetTimeNow(serverClock.Now).
+			NoRelay()
 		WithVerifiedServer(t, serverOpts, func(serverCh *tchannel.Channel, hostPort string) {
 			handler := raw.Wrap(handler)
 			serverCh.Register(handler, "echo")
@@ -143,6 +146,59 @@ func TestStatsCalls(t *testing.T) {
 	}
 }
 
+func TestStatsRelayCalls(t *testing.T) {
+	defer testutils.SetTimeout(t, 2*time.Second)()
+
+	initialTime := time.Date(2015, 2, 1, 10, 10, 0, 0, time.UTC)
+	clientClock := testutils.NewStubClock(initialTime)
+	serverClock := testutils.NewStubClock(initialTime)
+	handler := &statsHandler{
+		testHandler: newTestHandler(t),
+		clientClock: clientClock,
+		serverClock: serverClock,
+	}
+
+	clientStats := newRecordingStatsReporter()
+	serverStats := newRecordingStatsReporter()
+	serverOpts := testutils.NewOpts().
+		SetStatsReporter(serverStats).
+		SetTimeNow(serverClock.Now).
+		SetRelayOnly()
+	testutils.WithTestServer(t, serverOpts, func(t testing.TB, ts *testutils.TestServer) {
+		serverCh := ts.Server()
+		serverCh.Register(raw.Wrap(handler), "echo")
+
+		ch := testutils.NewClient(t, testutils.NewOpts().
+			SetStatsReporter(clientStats).
+			SetTimeNow(clientClock.Now))
+		defer ch.Close()
+
+		ctx, cancel := tchannel.NewContext(time.Second * 5)
+		defer cancel()
+
+		_, _, _, err := raw.Call(ctx, ch, ts.HostPort(), testutils.DefaultServerName, "echo", nil, nil)
+		require.NoError(t, err, "Call failed")
+
+		outboundTags := tagsForOutboundCall(serverCh, ch, "echo")
+		inboundTags := tagsForInboundCall(serverCh, ch, "echo")
+
+		clientStats.Expected.IncCounter("outbound.calls.send", outboundTags, 1)
+		clientStats.Expected.RecordTimer("outbound.calls.per-attempt.latency", outboundTags, 100*time.Millisecond)
+		clientStats.Expected.RecordTimer("outbound.calls.latency", outboundTags, 100*time.Millisecond)
+		clientStats.Expected.IncCounter("outbound.calls.success", outboundTags, 1)
+		serverStats.Expected.IncCounter("inbound.calls.recvd", inboundTags, 1)
+		serverStats.Expected.RecordTimer("inbound.calls.latency", inboundTags, 70*time.Millisecond)
+		serverStats.Expected.IncCounter("inbound.calls.success", inboundTags, 1)
+
+		// The relay shares the server's clock, which doesn't move while the
+		// relay is forwarding the call.
+		serverStats.Expected.RecordTimer("relay.calls.added-latency", ts.Relay().StatsTags(), 0)
+	})
+
+	clientStats.Validate(t)
+	serverStats.Validate(t)
+}
+
 func TestStatsWithRetries(t *testing.T) {
 	defer testutils.SetTimeout(t, 2*time.Second)()
 	a := testutils.DurationArray
@@ -246,3 +302,86 @@ func TestStatsWithRetries(t *testing.T) {
 		}
 	})
 }
+
+func TestStatsHistograms(t *testing.T) {
+	initialTime := time.Date(2015, 2, 1, 10, 10, 0, 0, time.UTC)
+	clientClock := testutils.NewStubClock(initialTime)
+	serverClock := testutils.NewStubClock(initialTime)
+	handler := &statsHandler{
+		testHandler: newTestHandler(t),
+		clientClock: clientClock,
+		serverClock: serverClock,
+	}
+
+	clientStats := newRecordingHistogramReporter()
+	serverStats := newRecordingHistogramReporter()
+	serverOpts := testutils.NewOpts().
+		SetStatsReporter(serverStats).
+		SetTimeNow(serverClock.Now)
+	testutils.WithTestServer(t, serverOpts, func(t testing.TB, ts *testutils.TestServer) {
+		clientStats.Reset()
+		serverStats.Reset()
+
+		serverCh, hostPort := ts.Server(), ts.HostPort()
+		serverCh.Register(raw.Wrap(handler), "echo")
+
+		ch := testutils.NewClient(t, testutils.NewOpts().
+			SetStatsReporter(clientStats).
+			SetTimeNow(clientClock.Now))
+		defer ch.Close()
+
+		ctx, cancel := tchannel.NewContext(time.Second)
+		defer cancel()
+
+		_, _, _, err := raw.Call(ctx, ch, hostPort, testutils.DefaultServerName, "echo", []byte("arg2"), []byte("arg3"))
+		require.NoError(t, err, "Call failed")
+
+		outboundTags := tagsForOutboundCall(serverCh, ch, "echo")
+		inboundTags := tagsForInboundCall(serverCh, ch, "echo")
+
+		durations := func(stats recordingHistogramReporter, name string, tags map[string]string) []time.Duration {
+			d, _ := stats.Histograms(name, tags)
+			return d
+		}
+		values := func(stats recordingHistogramReporter, name string, tags map[string]string) []float64 {
+			_, v := stats.Histograms(name, tags)
+			return v
+		}
+
+		// Latencies are recorded as histograms rather than timers.
+		assert.Equal(t, []time.Duration{100 * time.Millisecond}, durations(clientStats, "outbound.calls.latency", outboundTags),
+			"unexpected outbound latency")
+		assert.Equal(t, []time.Duration{70 * time.Millisecond}, durations(serverStats, "inbound.calls.latency", inboundTags),
+			"unexpected inbound latency")
+		assert.Empty(t, clientStats.getStat("outbound.calls.latency", outboundTags).timers, "latency should not be recorded as a timer")
+
+		// The bytes sent by the client should match the bytes received by the server.
+		clientReq := values(clientStats, "outbound.calls.request-bytes", outboundTags)
+		clientRes := values(clientStats, "outbound.calls.response-bytes", outboundTags)
+		serverReq := values(serverStats, "inbound.calls.request-bytes", inboundTags)
+		serverRes := values(serverStats, "inbound.calls.response-bytes", inboundTags)
+		require.Len(t, clientReq, 1, "missing outbound request size")
+		require.Len(t, serverRes, 1, "missing inbound response size")
+		assert.NotZero(t, clientReq[0], "unexpected outbound request size")
+		assert.NotZero(t, serverRes[0], "unexpected inbound response size")
+		if !ts.HasRelay() {
+			assert.Equal(t, clientReq, serverReq, "request sizes mismatch")
+			assert.Equal(t, serverRes, clientRes, "response sizes mismatch")
+			return
+		}
+
+		// The relay shares the server's stats reporter, and forwards the same frames.
+		// The relayed call ends after the response is forwarded, so wait for it.
+		relayTags := ts.Relay().StatsTags()
+		relayTags["calling-service"] = ch.ServiceName()
+		relayTags["target-service"] = serverCh.ServiceName()
+		require.True(t, testutils.WaitFor(time.Second, func() bool {
+			return len(values(serverStats, "relay.calls.response-bytes", relayTags)) == 1
+		}), "missing relay response size")
+		assert.Equal(t, clientRes, values(serverStats, "relay.calls.response-bytes", relayTags), "relay response size mismatch")
+		assert.Equal(t, serverReq, values(serverStats, "relay.calls.request-bytes", relayTags), "relay request size mismatch")
+		assert.Len(t, durations(serverStats, "relay.calls.latency", relayTags), 1, "missing relay latency")
+		assert.Equal(t, []time.Duration{0}, durations(serverStats, "relay.calls.added-latency", ts.Relay().StatsTags()),
+			"unexpected relay added latency")
+	})
+}
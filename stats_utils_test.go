@@ -41,6 +41,12 @@ type statsValue struct {
 
 	// timers is the list of timer values if this metrics is a timer.
 	timers []time.Duration
+
+	// durations is the list of values if this metric is a duration histogram.
+	durations []time.Duration
+
+	// values is the list of values if this metric is a value histogram.
+	values []float64
 }
 
 type recordingStatsReporter struct {
@@ -146,3 +152,35 @@ func (r *recordingStatsReporter) Validate(t *testing.T) {
 }
 
 func (r *recordingStatsReporter) UpdateGauge(name string, tags map[string]string, value int64) {}
+
+// recordingHistogramReporter is a recordingStatsReporter that also implements
+// HistogramReporter.
+type recordingHistogramReporter struct {
+	*recordingStatsReporter
+}
+
+func newRecordingHistogramReporter() recordingHistogramReporter {
+	return recordingHistogramReporter{newRecordingStatsReporter()}
+}
+
+func (r recordingHistogramReporter) RecordDurationHistogram(name string, tags map[string]string, d time.Duration) {
+	statVal := r.getStat(name, tags)
+	r.Lock()
+	statVal.durations = append(statVal.durations, d)
+	r.Unlock()
+}
+
+func (r recordingHistogramReporter) RecordValueHistogram(name string, tags map[string]string, value float64) {
+	statVal := r.getStat(name, tags)
+	r.Lock()
+	statVal.values = append(statVal.values, value)
+	r.Unlock()
+}
+
+// Histograms returns a copy of the duration and value histograms with the given name and tags.
+func (r recordingHistogramReporter) Histograms(name string, tags map[string]string) ([]time.Duration, []float64) {
+	statVal := r.getStat(name, tags)
+	r.Lock()
+	defer r.Unlock()
+	return append([]time.Duration(nil), statVal.durations...), append([]float64(nil), statVal.values...)
+}
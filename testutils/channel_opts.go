@@ -119,6 +119,12 @@ func (o *ChannelOpts) SetStatsReporter(statsReporter tchannel.StatsReporter) *Ch
 	return o
 }
 
+// SetAccessLogger sets the AccessLogger in ChannelOptions.
+func (o *ChannelOpts) SetAccessLogger(accessLogger tchannel.AccessLogger) *ChannelOpts {
+	o.AccessLogger = accessLogger
+	return o
+}
+
 // SetFramePool sets FramePool in DefaultConnectionOptions.
 func (o *ChannelOpts) SetFramePool(framePool tchannel.FramePool) *ChannelOpts {
 	o.DefaultConnectionOptions.FramePool = framePool
@@ -231,6 +237,13 @@ func (o *ChannelOpts) SetRelayLocal(relayLocal ...string) *ChannelOpts {
 	return o
 }
 
+// SetRelayLocalMethods sets the channel's relay local handlers for methods
+// (in Service::Method format) that should be handled by the relay channel itself.
+func (o *ChannelOpts) SetRelayLocalMethods(relayLocalMethods ...string) *ChannelOpts {
+	o.ChannelOptions.RelayLocalHandlerMethods = relayLocalMethods
+	return o
+}
+
 // SetRelayMaxTimeout sets the maximum allowable timeout for relayed calls.
 func (o *ChannelOpts) SetRelayMaxTimeout(d time.Duration) *ChannelOpts {
 	o.ChannelOptions.RelayMaxTimeout = d
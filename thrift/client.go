@@ -41,6 +41,10 @@ type client struct {
 type ClientOptions struct {
 	// HostPort specifies a specific server to hit.
 	HostPort string
+
+	// Protocol is the Thrift protocol used to encode calls, which defaults to
+	// BinaryProtocol. The server must support the protocol.
+	Protocol Protocol
 }
 
 // NewClient returns a Client that makes calls over the given tchannel to the given Hyperbahn service.
@@ -63,7 +67,7 @@ func (c *client) startCall(ctx context.Context, method string, callOptions *tcha
 	return c.sc.BeginCall(ctx, method, callOptions)
 }
 
-func writeArgs(ctx context.Context, call *tchannel.OutboundCall, headers map[string]string, req thrift.TStruct) error {
+func writeArgs(ctx context.Context, call *tchannel.OutboundCall, protocol Protocol, headers map[string]string, req thrift.TStruct) error {
 	writer, err := call.Arg2Writer()
 	if err != nil {
 		return err
@@ -81,7 +85,7 @@ func writeArgs(ctx context.Context, call *tchannel.OutboundCall, headers map[str
 		return err
 	}
 
-	if err := WriteStruct(ctx, writer, req); err != nil {
+	if err := writeStruct(ctx, protocol, writer, req); err != nil {
 		return err
 	}
 
@@ -90,7 +94,8 @@ func writeArgs(ctx context.Context, call *tchannel.OutboundCall, headers map[str
 
 // readResponse reads the response struct into resp, and returns:
 // (response headers, whether there was an application error, unexpected error).
-func readResponse(ctx context.Context, response *tchannel.OutboundCallResponse, resp thrift.TStruct) (map[string]string, bool, error) {
+// If resp is nil, the response body must be empty.
+func readResponse(ctx context.Context, response *tchannel.OutboundCallResponse, protocol Protocol, resp thrift.TStruct) (map[string]string, bool, error) {
 	reader, err := response.Arg2Reader()
 	if err != nil {
 		return nil, false, err
@@ -115,8 +120,10 @@ func readResponse(ctx context.Context, response *tchannel.OutboundCallResponse,
 		return headers, success, err
 	}
 
-	if err := ReadStruct(ctx, reader, resp); err != nil {
-		return headers, success, err
+	if resp != nil {
+		if err := readStruct(ctx, protocol, reader, resp); err != nil {
+			return headers, success, err
+		}
 	}
 
 	if err := argreader.EnsureEmpty(reader, "reading response body"); err != nil {
@@ -134,22 +141,28 @@ func (c *client) Call(ctx Context, thriftService, methodName string, req, resp t
 		isOK        bool
 	)
 
-	err := c.ch.RunWithRetry(ctx, func(ctx context.Context, rs *tchannel.RequestState) error {
+	protocol, err := parseProtocol(string(c.opts.Protocol))
+	if err != nil {
+		return false, err
+	}
+
+	err = c.ch.RunWithRetry(ctx, func(ctx context.Context, rs *tchannel.RequestState) error {
 		respHeaders, isOK = nil, false
 
 		call, err := c.startCall(ctx, thriftService+"::"+methodName, &tchannel.CallOptions{
-			Format:       tchannel.Thrift,
-			RequestState: rs,
+			Format:         tchannel.Thrift,
+			RequestState:   rs,
+			ThriftProtocol: protocol.header(),
 		})
 		if err != nil {
 			return err
 		}
 
-		if err := writeArgs(ctx, call, headers, req); err != nil {
+		if err := writeArgs(ctx, call, protocol, headers, req); err != nil {
 			return err
 		}
 
-		respHeaders, isOK, err = readResponse(ctx, call.Response(), resp)
+		respHeaders, isOK, err = readResponse(ctx, call.Response(), protocol, resp)
 		return err
 	})
 	if err != nil {
@@ -39,6 +39,10 @@ To use a Thrift client use the generated TChan client:
 This client can be used similar to a standard Thrift client, except a Context
 is passed with options (such as timeout).
 
+Calls use the Thrift binary protocol by default. Clients can use the compact
+or JSON protocols by setting ClientOptions.Protocol, and servers accept any
+protocol unless restricted using OptProtocols.
+
 TODO(prashant): Add and document header support.
 */
 package thrift
@@ -0,0 +1,95 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamic
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/temporalio/tchannel-go/thrift"
+)
+
+// Client makes calls to the methods of a service defined in an IDL.
+type Client struct {
+	client thrift.TChanClient
+	svc    *Service
+}
+
+// NewClient returns a Client that makes calls to the given service using client.
+func NewClient(client thrift.TChanClient, svc *Service) *Client {
+	return &Client{client: client, svc: svc}
+}
+
+// Call calls the given method with arguments keyed by name, and returns the
+// method's result, or nil for void methods. If the method returns one of its
+// declared exceptions, the error is an *Exception. Calls to oneway methods
+// return once the server has received the call.
+func (c *Client) Call(ctx thrift.Context, method string, args map[string]interface{}) (interface{}, error) {
+	m, err := c.svc.Method(method)
+	if err != nil {
+		return nil, err
+	}
+	return c.call(ctx, m, m.NewArgs(args))
+}
+
+// CallJSON is similar to Call, but the arguments are a JSON object keyed by
+// argument name, and the result is returned as JSON.
+func (c *Client) CallJSON(ctx thrift.Context, method string, args []byte) ([]byte, error) {
+	m, err := c.svc.Method(method)
+	if err != nil {
+		return nil, err
+	}
+	argsStruct, err := m.ArgsFromJSON(args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.call(ctx, m, argsStruct)
+	if err != nil {
+		return nil, err
+	}
+	return ToJSON(result)
+}
+
+func (c *Client) call(ctx thrift.Context, m *Method, args *Struct) (interface{}, error) {
+	// Encode the arguments before making the call, since invalid arguments
+	// would otherwise only fail once the call has started.
+	if err := thrift.WriteStruct(ctx, ioutil.Discard, args); err != nil {
+		return nil, err
+	}
+
+	if m.oneway {
+		_, err := c.client.Call(ctx, c.svc.name, m.name, args, nil)
+		return nil, err
+	}
+
+	result := m.NewResult()
+	success, err := c.client.Call(ctx, c.svc.name, m.name, args, result)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := m.Result(result)
+	if err == nil && !success {
+		err = fmt.Errorf("received no result or unknown exception for %v", m.name)
+	}
+	return r, err
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+/*
+Package dynamic makes and handles Thrift calls over TChannel using an IDL
+file parsed at runtime, rather than code generated by thrift-gen.
+
+To call a service, parse the IDL and wrap a thrift.TChanClient:
+
+	idl, err := dynamic.Parse("service.thrift")
+	svc, err := idl.Service("KeyValue")
+	client := dynamic.NewClient(thrift.NewClient(ch, "keyvalue", nil), svc)
+	result, err := client.Call(ctx, "get", map[string]interface{}{"key": "foo"})
+
+To handle calls, register a dynamic server with a thrift.Server:
+
+	server, err := dynamic.NewServer(svc, dynamic.Handlers{
+		"get": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			return values[args["key"].(string)], nil
+		},
+	})
+	thrift.NewServer(ch).Register(server)
+
+Values are represented using the following Go types:
+
+	bool            bool
+	byte, i8        int8
+	i16             int16
+	i32             int32
+	i64             int64
+	double          float64
+	string          string
+	binary          []byte
+	enum            the name of the value as a string, or an int32 if the value is unknown
+	struct          map[string]interface{} keyed by field name
+	list, set       []interface{}
+	map             map[string]interface{} for string keys, map[interface{}]interface{} otherwise
+
+Any integer or float type, or a json.Number, is accepted for numeric fields, and
+strings are accepted for enums and for the keys of maps. Exceptions declared by
+a method are returned as an *Exception.
+
+Values can also be converted from and to JSON, where binary fields are base64
+encoded, as with encoding/json.
+*/
+package dynamic
@@ -0,0 +1,406 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamic_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go/testutils"
+	"github.com/temporalio/tchannel-go/thrift"
+	"github.com/temporalio/tchannel-go/thrift/dynamic"
+	gen "github.com/temporalio/tchannel-go/thrift/gen-go/test"
+
+	athrift "github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseService(t *testing.T, file, service string) *dynamic.Service {
+	idl, err := dynamic.Parse(file)
+	require.NoError(t, err, "Failed to parse %v", file)
+	svc, err := idl.Service(service)
+	require.NoError(t, err, "Failed to get service %v", service)
+	return svc
+}
+
+// withStore runs f with a client for a dynamic server for the Store service.
+func withStore(t *testing.T, handlers dynamic.Handlers, f func(ctx thrift.Context, client *dynamic.Client)) {
+	svc := parseService(t, "test_files/store.thrift", "Store")
+	server, err := dynamic.NewServer(svc, handlers)
+	require.NoError(t, err, "NewServer failed")
+
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		thrift.NewServer(ts.Server()).Register(server)
+
+		ctx, cancel := thrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		tClient := thrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &thrift.ClientOptions{HostPort: ts.HostPort()})
+		f(ctx, dynamic.NewClient(tClient, svc))
+	})
+}
+
+func TestParse(t *testing.T) {
+	idl, err := dynamic.Parse("test_files/store.thrift")
+	require.NoError(t, err, "Parse failed")
+	assert.Equal(t, []string{"Store"}, idl.Services())
+
+	svc, err := idl.Service("Store")
+	require.NoError(t, err, "Service failed")
+	assert.Equal(t, "Store", svc.Name())
+	assert.Equal(t, []string{"echo", "get", "notify", "ping", "put"}, svc.Methods(), "Methods should include inherited methods")
+
+	_, err = svc.Method("unknown")
+	assert.Error(t, err, "Expected error for unknown method")
+	_, err = idl.Service("Base")
+	assert.Error(t, err, "Services in included files should not be returned")
+
+	_, err = dynamic.Parse("test_files/missing.thrift")
+	assert.Error(t, err, "Expected error for missing file")
+}
+
+func TestRoundTrip(t *testing.T) {
+	container := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "a", "color": "GREEN"},
+			map[string]interface{}{"name": "b", "color": 1},
+			map[string]interface{}{"name": "c"},
+		},
+		"counts": map[string]interface{}{"x": 1, "y": int64(2)},
+		"names":  map[int]string{1: "one", 2: "two"},
+		"tags":   []string{"t1", "t2"},
+		"data":   []byte{0, 1, 2, 255},
+		"ts":     uint32(123456789),
+		"ratio":  float32(0.5),
+		"flag":   true,
+		"b":      int8(-1),
+		"small":  1000,
+		"child":  map[string]interface{}{"flag": false},
+	}
+	want := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "a", "color": "GREEN"},
+			map[string]interface{}{"name": "b", "color": "RED"},
+			map[string]interface{}{"name": "c"},
+		},
+		"counts": map[string]interface{}{"x": int32(1), "y": int32(2)},
+		"names":  map[interface{}]interface{}{int32(1): "one", int32(2): "two"},
+		"tags":   []interface{}{"t1", "t2"},
+		"data":   []byte{0, 1, 2, 255},
+		"ts":     int64(123456789),
+		"ratio":  float64(0.5),
+		"flag":   true,
+		"b":      int8(-1),
+		"small":  int16(1000),
+		"child":  map[string]interface{}{"flag": false},
+	}
+
+	withStore(t, dynamic.Handlers{
+		"echo": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			assert.Equal(t, want, args["c"], "Unexpected args")
+			return args["c"], nil
+		},
+	}, func(ctx thrift.Context, client *dynamic.Client) {
+		res, err := client.Call(ctx, "echo", map[string]interface{}{"c": container})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, want, res, "Unexpected result")
+	})
+}
+
+func TestCallJSON(t *testing.T) {
+	withStore(t, dynamic.Handlers{
+		"echo": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			c := args["c"].(map[string]interface{})
+			assert.Equal(t, []byte("hello"), c["data"], "Binary should be base64 decoded")
+			return args["c"], nil
+		},
+		"get": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			return json.RawMessage(`{"name": "` + args["key"].(string) + `", "color": "RED"}`), nil
+		},
+	}, func(ctx thrift.Context, client *dynamic.Client) {
+		req := `{"c": {"items": [{"name": "a", "color": "GREEN"}], "names": {"1": "one"}, "data": "aGVsbG8=", "ratio": 1.5, "ts": 1}}`
+		res, err := client.CallJSON(ctx, "echo", []byte(req))
+		require.NoError(t, err, "CallJSON failed")
+		assert.JSONEq(t, `{"items": [{"name": "a", "color": "GREEN"}], "names": {"1": "one"}, "data": "aGVsbG8=", "ratio": 1.5, "ts": 1}`, string(res))
+
+		res, err = client.CallJSON(ctx, "get", []byte(`{"key": "k"}`))
+		require.NoError(t, err, "CallJSON failed")
+		assert.JSONEq(t, `{"name": "k", "color": "RED"}`, string(res))
+
+		_, err = client.CallJSON(ctx, "echo", []byte(`{"c": {"unknown": 1}}`))
+		assert.Error(t, err, "Expected error for unknown field")
+		_, err = client.CallJSON(ctx, "echo", []byte(`{"c": {"small": 100000}}`))
+		assert.Error(t, err, "Expected error for i16 overflow")
+	})
+}
+
+func TestExceptions(t *testing.T) {
+	withStore(t, dynamic.Handlers{
+		"get": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			switch key := args["key"].(string); key {
+			case "by-type":
+				return nil, &dynamic.Exception{Type: "NotFound", Values: map[string]interface{}{"key": key}}
+			case "by-name":
+				return nil, &dynamic.Exception{Name: "notFound", Values: map[string]interface{}{"key": key}}
+			case "undeclared":
+				return nil, &dynamic.Exception{Type: "Other"}
+			case "no-result":
+				return nil, nil
+			default:
+				return nil, errors.New("unexpected error")
+			}
+		},
+		"put": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, nil
+		},
+	}, func(ctx thrift.Context, client *dynamic.Client) {
+		for _, key := range []string{"by-type", "by-name"} {
+			_, err := client.Call(ctx, "get", map[string]interface{}{"key": key})
+			require.Error(t, err, "Expected exception")
+			assert.Equal(t, &dynamic.Exception{
+				Name:   "notFound",
+				Type:   "NotFound",
+				Values: map[string]interface{}{"key": key},
+			}, err)
+		}
+
+		for _, key := range []string{"undeclared", "no-result", "other"} {
+			_, err := client.Call(ctx, "get", map[string]interface{}{"key": key})
+			require.Error(t, err, "Expected error for %v", key)
+			_, isException := err.(*dynamic.Exception)
+			assert.False(t, isException, "Expected system error for %v, got %v", key, err)
+		}
+
+		res, err := client.Call(ctx, "put", map[string]interface{}{
+			"key":  "k",
+			"item": map[string]interface{}{"name": "v"},
+		})
+		assert.NoError(t, err, "Void call failed")
+		assert.Nil(t, res, "Void call should return nil")
+	})
+}
+
+func TestInheritedMethod(t *testing.T) {
+	withStore(t, dynamic.Handlers{
+		"ping": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			return "pong", nil
+		},
+	}, func(ctx thrift.Context, client *dynamic.Client) {
+		res, err := client.Call(ctx, "ping", nil)
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, "pong", res)
+	})
+}
+
+func TestOneway(t *testing.T) {
+	received := make(chan interface{}, 1)
+	withStore(t, dynamic.Handlers{
+		"notify": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			received <- args["message"]
+			return nil, nil
+		},
+	}, func(ctx thrift.Context, client *dynamic.Client) {
+		res, err := client.Call(ctx, "notify", map[string]interface{}{"message": "hello"})
+		require.NoError(t, err, "Call failed")
+		assert.Nil(t, res, "Oneway call should return nil")
+
+		select {
+		case message := <-received:
+			assert.Equal(t, "hello", message, "Unexpected message")
+		case <-time.After(testutils.Timeout(time.Second)):
+			t.Fatal("Timed out waiting for oneway call to be handled")
+		}
+	})
+}
+
+func TestInterceptor(t *testing.T) {
+	svc := parseService(t, "test_files/store.thrift", "Store")
+	server, err := dynamic.NewServer(svc, dynamic.Handlers{
+		"get": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"name": args["key"]}, nil
+		},
+	})
+	require.NoError(t, err, "NewServer failed")
+
+	denyEmpty := func(ctx thrift.Context, method string, args athrift.TStruct, next thrift.ArgsHandler) (bool, athrift.TStruct, error) {
+		if args.(*dynamic.Struct).Values["key"] == "" {
+			return false, nil, &dynamic.Exception{Type: "NotFound", Values: map[string]interface{}{"key": ""}}
+		}
+		return next(ctx, method, args)
+	}
+
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		thrift.NewServer(ts.Server()).Register(server, thrift.OptInterceptors(denyEmpty))
+
+		ctx, cancel := thrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		tClient := thrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &thrift.ClientOptions{HostPort: ts.HostPort()})
+		client := dynamic.NewClient(tClient, svc)
+
+		res, err := client.Call(ctx, "get", map[string]interface{}{"key": "k"})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, map[string]interface{}{"name": "k"}, res)
+
+		_, err = client.Call(ctx, "get", map[string]interface{}{"key": ""})
+		assert.Equal(t, &dynamic.Exception{
+			Name:   "notFound",
+			Type:   "NotFound",
+			Values: map[string]interface{}{"key": ""},
+		}, err, "Expected exception from interceptor")
+	})
+}
+
+func TestNewServerUnknownMethod(t *testing.T) {
+	svc := parseService(t, "test_files/store.thrift", "Store")
+	_, err := dynamic.NewServer(svc, dynamic.Handlers{"unknown": nil})
+	assert.Error(t, err, "Expected error for unknown method")
+}
+
+type simpleHandler struct{}
+
+func (simpleHandler) Call(ctx thrift.Context, arg *gen.Data) (*gen.Data, error) {
+	return &gen.Data{B1: !arg.B1, S2: arg.S2 + "!", I3: arg.I3 + 1}, nil
+}
+
+func (simpleHandler) Simple(ctx thrift.Context) error {
+	return &gen.SimpleErr{Message: "simple"}
+}
+
+func (simpleHandler) SimpleFuture(ctx thrift.Context) error {
+	return nil
+}
+
+func TestGeneratedServer(t *testing.T) {
+	svc := parseService(t, "../test.thrift", "SimpleService")
+
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		thrift.NewServer(ts.Server()).Register(gen.NewTChanSimpleServiceServer(simpleHandler{}))
+
+		ctx, cancel := thrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		tClient := thrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &thrift.ClientOptions{HostPort: ts.HostPort()})
+		client := dynamic.NewClient(tClient, svc)
+
+		res, err := client.Call(ctx, "Call", map[string]interface{}{
+			"arg": map[string]interface{}{"b1": true, "s2": "s", "i3": 1},
+		})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, map[string]interface{}{"b1": false, "s2": "s!", "i3": int32(2)}, res)
+
+		_, err = client.Call(ctx, "Simple", nil)
+		assert.Equal(t, &dynamic.Exception{
+			Name:   "simpleErr",
+			Type:   "SimpleErr",
+			Values: map[string]interface{}{"message": "simple"},
+		}, err)
+
+		_, err = client.Call(ctx, "SimpleFuture", nil)
+		assert.NoError(t, err, "SimpleFuture failed")
+	})
+}
+
+func TestParseIDL(t *testing.T) {
+	idl, err := dynamic.ParseIDL(&thrift.IDL{
+		EntryPoint: "svc/store.thrift",
+		Files: map[string]string{
+			"svc/store.thrift":  "include \"../shared.thrift\"\nservice Store { shared.Item get(1: string key) }",
+			"shared.thrift":     "struct Item { 1: string value }",
+			"svc/unused.thrift": "invalid",
+		},
+	})
+	require.NoError(t, err, "ParseIDL failed")
+	assert.Equal(t, []string{"Store"}, idl.Services())
+
+	_, err = dynamic.ParseIDL(&thrift.IDL{
+		EntryPoint: "store.thrift",
+		Files:      map[string]string{"store.thrift": "include \"shared.thrift\""},
+	})
+	assert.Error(t, err, "ParseIDL should fail with a missing include")
+}
+
+func TestGetThriftIDL(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		thrift.NewServer(ts.Server()).Register(gen.NewTChanSimpleServiceServer(simpleHandler{}))
+
+		ctx, cancel := thrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		tClient := thrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &thrift.ClientOptions{HostPort: ts.HostPort()})
+		remoteIDL, err := thrift.GetThriftIDL(ctx, tClient)
+		require.NoError(t, err, "GetThriftIDL failed")
+
+		contents, err := ioutil.ReadFile("../test.thrift")
+		require.NoError(t, err, "ReadFile failed")
+		assert.Equal(t, &thrift.IDL{
+			EntryPoint: "test.thrift",
+			Files:      map[string]string{"test.thrift": string(contents)},
+		}, remoteIDL, "Unexpected IDL")
+
+		// The fetched IDL can be used to make calls without the IDL files.
+		idl, err := dynamic.ParseIDL(remoteIDL)
+		require.NoError(t, err, "ParseIDL failed")
+		svc, err := idl.Service("SimpleService")
+		require.NoError(t, err, "Failed to get service")
+
+		res, err := dynamic.NewClient(tClient, svc).Call(ctx, "Call", map[string]interface{}{
+			"arg": map[string]interface{}{"b1": true, "s2": "s", "i3": 1},
+		})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, map[string]interface{}{"b1": false, "s2": "s!", "i3": int32(2)}, res)
+	})
+}
+
+func TestGeneratedClient(t *testing.T) {
+	svc := parseService(t, "../test.thrift", "SimpleService")
+	server, err := dynamic.NewServer(svc, dynamic.Handlers{
+		"Call": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			arg := args["arg"].(map[string]interface{})
+			return map[string]interface{}{"b1": arg["b1"], "s2": arg["s2"].(string) + "!", "i3": arg["i3"]}, nil
+		},
+		"Simple": func(ctx thrift.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, &dynamic.Exception{Type: "SimpleErr", Values: map[string]interface{}{"message": "simple"}}
+		},
+	})
+	require.NoError(t, err, "NewServer failed")
+
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		thrift.NewServer(ts.Server()).Register(server)
+
+		ctx, cancel := thrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		tClient := thrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &thrift.ClientOptions{HostPort: ts.HostPort()})
+		client := gen.NewTChanSimpleServiceClient(tClient)
+
+		res, err := client.Call(ctx, &gen.Data{B1: true, S2: "s", I3: 3})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, &gen.Data{B1: true, S2: "s!", I3: 3}, res)
+
+		err = client.Simple(ctx)
+		assert.Equal(t, &gen.SimpleErr{Message: "simple"}, err)
+	})
+}
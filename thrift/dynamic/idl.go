@@ -0,0 +1,407 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamic
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tcthrift "github.com/temporalio/tchannel-go/thrift"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/samuel/go-thrift/parser"
+)
+
+// IDL is a parsed Thrift IDL file, along with the files it includes.
+type IDL struct {
+	files    map[string]*parser.Thrift
+	main     string
+	structs  map[string]*structType
+	services map[string]*Service
+}
+
+// Service is a Thrift service defined in an IDL.
+type Service struct {
+	name    string
+	methods map[string]*Method
+}
+
+// Method is a method of a Thrift service, including methods inherited
+// from the services it extends.
+type Method struct {
+	name       string
+	oneway     bool
+	returnType *typ
+	args       *structType
+	result     *structType
+}
+
+// typ is a Thrift type with typedefs and includes resolved.
+type typ struct {
+	name   string
+	ttype  thrift.TType
+	binary bool
+	enum   *parser.Enum
+	strct  *structType
+
+	// key and elem are the types of map keys, and of map, list and set values.
+	key, elem *typ
+}
+
+// structType describes the fields of a struct, exception, union, or the
+// arguments or result of a method.
+type structType struct {
+	name   string
+	fields []*field
+}
+
+type field struct {
+	id       int16
+	name     string
+	optional bool
+	typ      *typ
+}
+
+// Parse parses the given Thrift IDL file, and any files that it includes.
+func Parse(filename string) (*IDL, error) {
+	return parse(new(parser.Parser), filename)
+}
+
+// ParseIDL parses a Thrift IDL that was fetched from a remote service using
+// thrift.GetThriftIDL, without reading any files.
+func ParseIDL(idl *tcthrift.IDL) (*IDL, error) {
+	return parse(&parser.Parser{Filesystem: idlFilesystem(idl.Files)}, idl.EntryPoint)
+}
+
+// idlFilesystem is a parser.Filesystem that reads files from memory.
+type idlFilesystem map[string]string
+
+func (fs idlFilesystem) Open(filename string) (io.ReadCloser, error) {
+	contents, ok := fs[filename]
+	if !ok {
+		return nil, fmt.Errorf("file %v not found in IDL", filename)
+	}
+	return ioutil.NopCloser(strings.NewReader(contents)), nil
+}
+
+func (fs idlFilesystem) Abs(filename string) (string, error) {
+	return path.Clean(filepath.ToSlash(filename)), nil
+}
+
+func parse(p *parser.Parser, filename string) (*IDL, error) {
+	files, main, err := p.ParseFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", filename, err)
+	}
+
+	idl := &IDL{
+		files:    files,
+		main:     main,
+		structs:  make(map[string]*structType),
+		services: make(map[string]*Service),
+	}
+
+	// Create all struct types before resolving any fields, since fields can
+	// refer to structs defined later, or to the struct itself.
+	parsedStructs := make(map[string]*parser.Struct)
+	for file, t := range files {
+		for _, structs := range []map[string]*parser.Struct{t.Structs, t.Exceptions, t.Unions} {
+			for name, s := range structs {
+				key := structKey(file, name)
+				parsedStructs[key] = s
+				idl.structs[key] = &structType{name: name}
+			}
+		}
+	}
+	for key, s := range parsedStructs {
+		file := strings.SplitN(key, "\x00", 2)[0]
+		if idl.structs[key].fields, err = idl.resolveFields(file, s.Fields); err != nil {
+			return nil, fmt.Errorf("struct %v: %v", s.Name, err)
+		}
+	}
+
+	for name := range files[main].Services {
+		svc, err := idl.newService(main, name)
+		if err != nil {
+			return nil, err
+		}
+		idl.services[name] = svc
+	}
+	return idl, nil
+}
+
+func structKey(file, name string) string {
+	return file + "\x00" + name
+}
+
+// Services returns the names of the services defined in the parsed file,
+// excluding services defined in included files.
+func (idl *IDL) Services() []string {
+	names := make([]string, 0, len(idl.services))
+	for name := range idl.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Service returns the service with the given name.
+func (idl *IDL) Service(name string) (*Service, error) {
+	svc, ok := idl.services[name]
+	if !ok {
+		return nil, fmt.Errorf("service %v not found", name)
+	}
+	return svc, nil
+}
+
+func (idl *IDL) newService(file, name string) (*Service, error) {
+	svc := &Service{name: name, methods: make(map[string]*Method)}
+	for file, name := file, name; ; {
+		parsed, ok := idl.files[file].Services[name]
+		if !ok {
+			return nil, fmt.Errorf("service %v not found", name)
+		}
+		for methodName, m := range parsed.Methods {
+			if _, ok := svc.methods[methodName]; ok {
+				// Methods defined by a service override inherited methods.
+				continue
+			}
+			method, err := idl.newMethod(file, m)
+			if err != nil {
+				return nil, fmt.Errorf("method %v::%v: %v", name, methodName, err)
+			}
+			svc.methods[methodName] = method
+		}
+		if parsed.Extends == "" {
+			return svc, nil
+		}
+		file, name = idl.resolveName(file, parsed.Extends)
+	}
+}
+
+func (idl *IDL) newMethod(file string, m *parser.Method) (*Method, error) {
+	method := &Method{name: m.Name, oneway: m.Oneway}
+
+	var err error
+	if m.ReturnType != nil {
+		if method.returnType, err = idl.resolve(file, m.ReturnType); err != nil {
+			return nil, err
+		}
+	}
+
+	args, err := idl.resolveFields(file, m.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	method.args = &structType{name: m.Name + "_args", fields: args}
+
+	results, err := idl.resolveFields(file, m.Exceptions)
+	if err != nil {
+		return nil, err
+	}
+	if method.returnType != nil {
+		success := &field{id: 0, name: "success", optional: true, typ: method.returnType}
+		results = append([]*field{success}, results...)
+	}
+	method.result = &structType{name: m.Name + "_result", fields: results}
+	return method, nil
+}
+
+func (idl *IDL) resolveFields(file string, parsed []*parser.Field) ([]*field, error) {
+	fields := make([]*field, len(parsed))
+	for i, f := range parsed {
+		t, err := idl.resolve(file, f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %v", f.Name, err)
+		}
+		fields[i] = &field{id: int16(f.ID), name: f.Name, optional: f.Optional, typ: t}
+	}
+	return fields, nil
+}
+
+// resolveName returns the file and unqualified name for a name that may
+// refer to an included file, such as "shared.Type".
+func (idl *IDL) resolveName(file, name string) (string, string) {
+	if dot := strings.Index(name, "."); dot > 0 {
+		if included, ok := idl.files[file].Includes[name[:dot]]; ok {
+			return included, name[dot+1:]
+		}
+	}
+	return file, name
+}
+
+func (idl *IDL) resolve(file string, t *parser.Type) (*typ, error) {
+	resolved := &typ{name: t.Name}
+	switch t.Name {
+	case "bool":
+		resolved.ttype = thrift.BOOL
+	case "byte", "i8":
+		resolved.ttype = thrift.BYTE
+	case "i16":
+		resolved.ttype = thrift.I16
+	case "i32":
+		resolved.ttype = thrift.I32
+	case "i64":
+		resolved.ttype = thrift.I64
+	case "double":
+		resolved.ttype = thrift.DOUBLE
+	case "string":
+		resolved.ttype = thrift.STRING
+	case "binary":
+		resolved.ttype = thrift.STRING
+		resolved.binary = true
+	case "list", "set", "map":
+		resolved.ttype = map[string]thrift.TType{"list": thrift.LIST, "set": thrift.SET, "map": thrift.MAP}[t.Name]
+		var err error
+		if t.KeyType != nil {
+			if resolved.key, err = idl.resolve(file, t.KeyType); err != nil {
+				return nil, err
+			}
+		}
+		if resolved.elem, err = idl.resolve(file, t.ValueType); err != nil {
+			return nil, err
+		}
+	default:
+		file, name := idl.resolveName(file, t.Name)
+		parsed, ok := idl.files[file]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %v", t.Name)
+		}
+		if td, ok := parsed.Typedefs[name]; ok {
+			return idl.resolve(file, td.Type)
+		}
+		if enum, ok := parsed.Enums[name]; ok {
+			resolved.ttype = thrift.I32
+			resolved.enum = enum
+			return resolved, nil
+		}
+		if s, ok := idl.structs[structKey(file, name)]; ok {
+			resolved.ttype = thrift.STRUCT
+			resolved.strct = s
+			return resolved, nil
+		}
+		return nil, fmt.Errorf("unknown type %v", t.Name)
+	}
+	return resolved, nil
+}
+
+// Name returns the name of the service.
+func (s *Service) Name() string {
+	return s.name
+}
+
+// Methods returns the names of the service's methods, including inherited methods.
+func (s *Service) Methods() []string {
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Method returns the method with the given name.
+func (s *Service) Method(name string) (*Method, error) {
+	m, ok := s.methods[name]
+	if !ok {
+		return nil, fmt.Errorf("method %v not found in service %v", name, s.name)
+	}
+	return m, nil
+}
+
+// Name returns the name of the method.
+func (m *Method) Name() string {
+	return m.name
+}
+
+// Oneway returns whether the method is oneway.
+func (m *Method) Oneway() bool {
+	return m.oneway
+}
+
+// NewArgs returns the arguments struct for the method, with the given
+// argument values keyed by name.
+func (m *Method) NewArgs(args map[string]interface{}) *Struct {
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+	return &Struct{typ: m.args, Values: args}
+}
+
+// ArgsFromJSON returns the arguments struct for the method, with argument
+// values from a JSON object keyed by argument name.
+func (m *Method) ArgsFromJSON(data []byte) (*Struct, error) {
+	args, err := structFromJSON(m.args, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments for %v: %v", m.name, err)
+	}
+	return args, nil
+}
+
+// NewResult returns an empty result struct for the method. The return value
+// is stored in the "success" field, and exceptions using their names in the
+// method's throws clause.
+func (m *Method) NewResult() *Struct {
+	return &Struct{typ: m.result, Values: make(map[string]interface{})}
+}
+
+// Result returns the return value stored in a result struct, or an
+// *Exception if the result holds one of the method's declared exceptions.
+func (m *Method) Result(result *Struct) (interface{}, error) {
+	for _, f := range m.result.fields {
+		if f.id == 0 {
+			continue
+		}
+		if v, ok := result.Values[f.name]; ok && v != nil {
+			values, _ := v.(map[string]interface{})
+			return nil, &Exception{Name: f.name, Type: f.typ.strct.name, Values: values}
+		}
+	}
+
+	if m.returnType == nil {
+		return nil, nil
+	}
+	if v, ok := result.Values["success"]; ok && v != nil {
+		return v, nil
+	}
+	return nil, fmt.Errorf("received no result or unknown exception for %v", m.name)
+}
+
+// Exception is an exception declared by a method.
+type Exception struct {
+	// Name is the name of the exception in the method's throws clause.
+	Name string
+
+	// Type is the name of the exception type.
+	Type string
+
+	// Values are the exception's fields, keyed by name.
+	Values map[string]interface{}
+}
+
+func (e *Exception) Error() string {
+	return fmt.Sprintf("%v(%v)", e.Type, e.Values)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// structFromJSON decodes a JSON object into a struct of the given type.
+func structFromJSON(st *structType, data []byte) (*Struct, error) {
+	values := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, err
+		}
+	}
+
+	converted, err := fromJSON(&typ{name: st.name, ttype: thrift.STRUCT, strct: st}, values)
+	if err != nil {
+		return nil, err
+	}
+	return &Struct{typ: st, Values: converted.(map[string]interface{})}, nil
+}
+
+// valueFromJSON decodes a JSON value of the given type.
+func valueFromJSON(t *typ, data []byte) (interface{}, error) {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return fromJSON(t, v)
+}
+
+// fromJSON converts a value decoded from JSON for the given type. JSON values
+// can mostly be written as-is, except for binary fields, which are base64 encoded.
+func fromJSON(t *typ, v interface{}) (interface{}, error) {
+	switch {
+	case t.binary:
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+		return base64.StdEncoding.DecodeString(s)
+	case t.strct != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+		converted := make(map[string]interface{}, len(m))
+		for name, fv := range m {
+			f := t.strct.field(name)
+			if f == nil {
+				return nil, fmt.Errorf("%v has no field %v", t.strct.name, name)
+			}
+			var err error
+			if converted[name], err = fromJSON(f.typ, fv); err != nil {
+				return nil, fmt.Errorf("%v.%v: %v", t.strct.name, name, err)
+			}
+		}
+		return converted, nil
+	case t.key != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+		converted := make(map[string]interface{}, len(m))
+		for k, mv := range m {
+			var err error
+			if converted[k], err = fromJSON(t.elem, mv); err != nil {
+				return nil, err
+			}
+		}
+		return converted, nil
+	case t.elem != nil:
+		list, ok := v.([]interface{})
+		if !ok {
+			return v, nil
+		}
+		converted := make([]interface{}, len(list))
+		for i, lv := range list {
+			var err error
+			if converted[i], err = fromJSON(t.elem, lv); err != nil {
+				return nil, err
+			}
+		}
+		return converted, nil
+	}
+	return v, nil
+}
+
+// ToJSON encodes a value read by this package as JSON. Maps with non-string
+// keys are encoded as objects with the keys formatted as strings, and binary
+// values are base64 encoded.
+func ToJSON(v interface{}) ([]byte, error) {
+	return json.Marshal(toJSON(v))
+}
+
+func toJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for k, mv := range v {
+			converted[k] = toJSON(mv)
+		}
+		return converted
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for k, mv := range v {
+			converted[fmt.Sprint(k)] = toJSON(mv)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, lv := range v {
+			converted[i] = toJSON(lv)
+		}
+		return converted
+	case *Struct:
+		return toJSON(v.Values)
+	}
+	return v
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamic
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	athrift "github.com/apache/thrift/lib/go/thrift"
+	"github.com/temporalio/tchannel-go/thrift"
+)
+
+// Handler handles a call with the arguments keyed by name. It returns the
+// method's result, or nil for void and oneway methods. A json.RawMessage result is
+// decoded using the method's return type.
+//
+// To return one of the method's declared exceptions, return an *Exception
+// with either Name or Type set.
+type Handler func(ctx thrift.Context, args map[string]interface{}) (interface{}, error)
+
+// Handlers is a map from method name to the handler for that method.
+type Handlers map[string]Handler
+
+type server struct {
+	svc      *Service
+	handlers Handlers
+}
+
+// NewServer returns a thrift.TChanServer that handles the given methods of svc.
+// It can be registered with a thrift.Server, and implements thrift.TChanArgsServer
+// so interceptors see the arguments as a *Struct.
+func NewServer(svc *Service, handlers Handlers) (thrift.TChanServer, error) {
+	for method := range handlers {
+		if _, err := svc.Method(method); err != nil {
+			return nil, err
+		}
+	}
+	return &server{svc: svc, handlers: handlers}, nil
+}
+
+func (s *server) Service() string {
+	return s.svc.name
+}
+
+func (s *server) Methods() []string {
+	methods := make([]string, 0, len(s.handlers))
+	for method := range s.handlers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func (s *server) OnewayMethods() []string {
+	var methods []string
+	for _, method := range s.Methods() {
+		if s.svc.methods[method].oneway {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+func (s *server) Handle(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	args, err := s.ReadArgs(ctx, methodName, protocol)
+	if err != nil {
+		return false, nil, err
+	}
+	return s.HandleArgs(ctx, methodName, args)
+}
+
+func (s *server) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	if _, ok := s.handlers[methodName]; !ok {
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.svc.name)
+	}
+
+	args := s.svc.methods[methodName].NewArgs(nil)
+	if err := args.Read(ctx, protocol); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+func (s *server) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	handler, ok := s.handlers[methodName]
+	if !ok {
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.svc.name)
+	}
+	m := s.svc.methods[methodName]
+
+	r, err := handler(ctx, args.(*Struct).Values)
+	if m.oneway {
+		return err == nil, nil, err
+	}
+	if err != nil {
+		return s.HandleError(methodName, err)
+	}
+
+	res := m.NewResult()
+	if m.returnType != nil {
+		if raw, ok := r.(json.RawMessage); ok {
+			if r, err = valueFromJSON(m.returnType, raw); err != nil {
+				return false, nil, fmt.Errorf("handler for %v returned invalid JSON result: %v", methodName, err)
+			}
+		}
+		if r == nil {
+			return false, nil, fmt.Errorf("handler for %v returned no result", methodName)
+		}
+		res.Values["success"] = r
+	}
+	return true, res, nil
+}
+
+func (s *server) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	m, ok := s.svc.methods[methodName]
+	ex, isException := err.(*Exception)
+	if !ok || m.oneway || !isException {
+		return false, nil, err
+	}
+
+	f := m.exception(ex)
+	if f == nil {
+		return false, nil, fmt.Errorf("handler for %v returned undeclared exception %v", methodName, ex)
+	}
+	res := m.NewResult()
+	res.Values[f.name] = ex.Values
+	return false, res, nil
+}
+
+// exception returns the result field for the given exception.
+func (m *Method) exception(ex *Exception) *field {
+	for _, f := range m.result.fields {
+		if f.id == 0 {
+			continue
+		}
+		if f.name == ex.Name || (ex.Name == "" && f.typ.strct.name == ex.Type) {
+			return f
+		}
+	}
+	return nil
+}
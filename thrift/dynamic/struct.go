@@ -0,0 +1,415 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// Struct is a Thrift struct with field values keyed by field name. It
+// implements thrift.TStruct, so it can be passed to a thrift.TChanClient
+// or returned from a thrift.TChanServer.
+type Struct struct {
+	typ *structType
+
+	// Values are the field values, keyed by field name. Fields that are not
+	// set, or set to nil, are not written.
+	Values map[string]interface{}
+}
+
+var _ thrift.TStruct = (*Struct)(nil)
+
+// Write writes the struct to the given protocol.
+func (s *Struct) Write(ctx context.Context, p thrift.TProtocol) error {
+	return writeStruct(ctx, p, s.typ, s.Values)
+}
+
+// Read reads the struct from the given protocol, replacing any values.
+func (s *Struct) Read(ctx context.Context, p thrift.TProtocol) error {
+	values, err := readStruct(ctx, p, s.typ)
+	if err != nil {
+		return err
+	}
+	s.Values = values
+	return nil
+}
+
+// MarshalJSON encodes the struct's values as a JSON object.
+func (s *Struct) MarshalJSON() ([]byte, error) {
+	return ToJSON(s.Values)
+}
+
+func writeStruct(ctx context.Context, p thrift.TProtocol, st *structType, values map[string]interface{}) error {
+	for name := range values {
+		if st.field(name) == nil {
+			return fmt.Errorf("%v has no field %v", st.name, name)
+		}
+	}
+
+	if err := p.WriteStructBegin(ctx, st.name); err != nil {
+		return err
+	}
+	for _, f := range st.fields {
+		v := values[f.name]
+		if v == nil {
+			continue
+		}
+		if err := p.WriteFieldBegin(ctx, f.name, f.typ.ttype, f.id); err != nil {
+			return err
+		}
+		if err := writeValue(ctx, p, f.typ, v); err != nil {
+			return fmt.Errorf("%v.%v: %v", st.name, f.name, err)
+		}
+		if err := p.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return p.WriteStructEnd(ctx)
+}
+
+func readStruct(ctx context.Context, p thrift.TProtocol, st *structType) (map[string]interface{}, error) {
+	if _, err := p.ReadStructBegin(ctx); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	for {
+		_, ttype, id, err := p.ReadFieldBegin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ttype == thrift.STOP {
+			break
+		}
+
+		if f := st.fieldByID(id); f != nil && f.typ.ttype == ttype {
+			if values[f.name], err = readValue(ctx, p, f.typ); err != nil {
+				return nil, fmt.Errorf("%v.%v: %v", st.name, f.name, err)
+			}
+		} else if err := p.Skip(ctx, ttype); err != nil {
+			return nil, err
+		}
+
+		if err := p.ReadFieldEnd(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return values, p.ReadStructEnd(ctx)
+}
+
+func (st *structType) field(name string) *field {
+	for _, f := range st.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (st *structType) fieldByID(id int16) *field {
+	for _, f := range st.fields {
+		if f.id == id {
+			return f
+		}
+	}
+	return nil
+}
+
+func writeValue(ctx context.Context, p thrift.TProtocol, t *typ, v interface{}) error {
+	switch t.ttype {
+	case thrift.BOOL:
+		b, err := toBool(v)
+		if err != nil {
+			return err
+		}
+		return p.WriteBool(ctx, b)
+	case thrift.BYTE:
+		n, err := toInt(t, v, 8)
+		if err != nil {
+			return err
+		}
+		return p.WriteByte(ctx, int8(n))
+	case thrift.I16:
+		n, err := toInt(t, v, 16)
+		if err != nil {
+			return err
+		}
+		return p.WriteI16(ctx, int16(n))
+	case thrift.I32:
+		n, err := toInt(t, v, 32)
+		if err != nil {
+			return err
+		}
+		return p.WriteI32(ctx, int32(n))
+	case thrift.I64:
+		n, err := toInt(t, v, 64)
+		if err != nil {
+			return err
+		}
+		return p.WriteI64(ctx, n)
+	case thrift.DOUBLE:
+		f, err := toFloat(v)
+		if err != nil {
+			return err
+		}
+		return p.WriteDouble(ctx, f)
+	case thrift.STRING:
+		switch v := v.(type) {
+		case string:
+			if t.binary {
+				return p.WriteBinary(ctx, []byte(v))
+			}
+			return p.WriteString(ctx, v)
+		case []byte:
+			if t.binary {
+				return p.WriteBinary(ctx, v)
+			}
+			return p.WriteString(ctx, string(v))
+		}
+	case thrift.STRUCT:
+		switch v := v.(type) {
+		case map[string]interface{}:
+			return writeStruct(ctx, p, t.strct, v)
+		case *Struct:
+			return writeStruct(ctx, p, t.strct, v.Values)
+		}
+	case thrift.LIST, thrift.SET:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			break
+		}
+		var err error
+		if t.ttype == thrift.LIST {
+			err = p.WriteListBegin(ctx, t.elem.ttype, rv.Len())
+		} else {
+			err = p.WriteSetBegin(ctx, t.elem.ttype, rv.Len())
+		}
+		if err != nil {
+			return err
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := writeValue(ctx, p, t.elem, rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		if t.ttype == thrift.LIST {
+			return p.WriteListEnd(ctx)
+		}
+		return p.WriteSetEnd(ctx)
+	case thrift.MAP:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Map {
+			break
+		}
+		if err := p.WriteMapBegin(ctx, t.key.ttype, t.elem.ttype, rv.Len()); err != nil {
+			return err
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			if err := writeValue(ctx, p, t.key, iter.Key().Interface()); err != nil {
+				return err
+			}
+			if err := writeValue(ctx, p, t.elem, iter.Value().Interface()); err != nil {
+				return err
+			}
+		}
+		return p.WriteMapEnd(ctx)
+	}
+	return fmt.Errorf("cannot use %T as %v", v, t.name)
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	}
+	return false, fmt.Errorf("cannot use %T as bool", v)
+}
+
+// toInt converts v to an integer that fits in the given number of bits.
+// Strings are parsed as the name of an enum value, or as a number.
+func toInt(t *typ, v interface{}, bits int) (int64, error) {
+	var n int64
+	switch v := v.(type) {
+	case json.Number:
+		var err error
+		if n, err = strconv.ParseInt(string(v), 10, bits); err != nil {
+			return 0, err
+		}
+	case string:
+		if t.enum != nil {
+			if ev, ok := t.enum.Values[v]; ok {
+				return int64(ev.Value), nil
+			}
+		}
+		var err error
+		if n, err = strconv.ParseInt(v, 10, bits); err != nil {
+			return 0, fmt.Errorf("cannot use %q as %v", v, t.name)
+		}
+	case float32, float64:
+		f := reflect.ValueOf(v).Float()
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("cannot use %v as %v", f, t.name)
+		}
+		n = int64(f)
+	default:
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() > math.MaxInt64 {
+				return 0, fmt.Errorf("%v overflows %v", v, t.name)
+			}
+			n = int64(rv.Uint())
+		default:
+			return 0, fmt.Errorf("cannot use %T as %v", v, t.name)
+		}
+	}
+
+	if bits < 64 && (n < -1<<(bits-1) || n >= 1<<(bits-1)) {
+		return 0, fmt.Errorf("%v overflows %v", n, t.name)
+	}
+	return n, nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("cannot use %T as double", v)
+}
+
+func readValue(ctx context.Context, p thrift.TProtocol, t *typ) (interface{}, error) {
+	switch t.ttype {
+	case thrift.BOOL:
+		return p.ReadBool(ctx)
+	case thrift.BYTE:
+		return p.ReadByte(ctx)
+	case thrift.I16:
+		return p.ReadI16(ctx)
+	case thrift.I32:
+		n, err := p.ReadI32(ctx)
+		if err != nil || t.enum == nil {
+			return n, err
+		}
+		for name, ev := range t.enum.Values {
+			if ev.Value == int(n) {
+				return name, nil
+			}
+		}
+		return n, nil
+	case thrift.I64:
+		return p.ReadI64(ctx)
+	case thrift.DOUBLE:
+		return p.ReadDouble(ctx)
+	case thrift.STRING:
+		if t.binary {
+			return p.ReadBinary(ctx)
+		}
+		return p.ReadString(ctx)
+	case thrift.STRUCT:
+		return readStruct(ctx, p, t.strct)
+	case thrift.LIST, thrift.SET:
+		var (
+			size int
+			err  error
+		)
+		if t.ttype == thrift.LIST {
+			_, size, err = p.ReadListBegin(ctx)
+		} else {
+			_, size, err = p.ReadSetBegin(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			if list[i], err = readValue(ctx, p, t.elem); err != nil {
+				return nil, err
+			}
+		}
+		if t.ttype == thrift.LIST {
+			return list, p.ReadListEnd(ctx)
+		}
+		return list, p.ReadSetEnd(ctx)
+	case thrift.MAP:
+		_, _, size, err := p.ReadMapBegin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if t.key.ttype == thrift.STRING {
+			m := make(map[string]interface{}, size)
+			for i := 0; i < size; i++ {
+				k, err := p.ReadString(ctx)
+				if err != nil {
+					return nil, err
+				}
+				if m[k], err = readValue(ctx, p, t.elem); err != nil {
+					return nil, err
+				}
+			}
+			return m, p.ReadMapEnd(ctx)
+		}
+
+		switch t.key.ttype {
+		case thrift.STRUCT, thrift.MAP, thrift.SET, thrift.LIST:
+			return nil, fmt.Errorf("unsupported map key type %v", t.key.name)
+		}
+		m := make(map[interface{}]interface{}, size)
+		for i := 0; i < size; i++ {
+			k, err := readValue(ctx, p, t.key)
+			if err != nil {
+				return nil, err
+			}
+			if m[k], err = readValue(ctx, p, t.elem); err != nil {
+				return nil, err
+			}
+		}
+		return m, p.ReadMapEnd(ctx)
+	}
+	return nil, fmt.Errorf("unsupported type %v", t.name)
+}
@@ -0,0 +1,19 @@
+typedef i64 Timestamp
+
+enum Color {
+  RED = 1,
+  GREEN = 2
+}
+
+struct Item {
+  1: string name
+  2: optional Color color
+}
+
+exception NotFound {
+  1: string key
+}
+
+service Base {
+  string ping()
+}
@@ -0,0 +1,24 @@
+include "shared.thrift"
+
+typedef shared.Item Item
+
+struct Container {
+  1: optional list<Item> items
+  2: optional map<string, i32> counts
+  3: optional map<i32, string> names
+  4: optional set<string> tags
+  5: optional binary data
+  6: optional shared.Timestamp ts
+  7: optional double ratio
+  8: optional bool flag
+  9: optional byte b
+  10: optional i16 small
+  11: optional Container child
+}
+
+service Store extends shared.Base {
+  Container echo(1: Container c)
+  Item get(1: string key) throws (1: shared.NotFound notFound)
+  void put(1: string key, 2: Item item)
+  oneway void notify(1: string message)
+}
@@ -10,6 +10,46 @@ import (
 	"github.com/temporalio/tchannel-go/thrift"
 )
 
+// thriftIDL is the Thrift IDL that this package was generated from.
+var thriftIDL = thrift.IDL{
+	EntryPoint: "test.thrift",
+	Files: map[string]string{
+		"test.thrift": "struct Data {\n" +
+			"  1: required bool b1,\n" +
+			"  2: required string s2,\n" +
+			"  3: required i32 i3\n" +
+			"}\n" +
+			"\n" +
+			"exception SimpleErr {\n" +
+			"  1: string message\n" +
+			"}\n" +
+			"\n" +
+			"exception NewErr {\n" +
+			"  1: string message\n" +
+			"}\n" +
+			"\n" +
+			"service SimpleService {\n" +
+			"  Data Call(1: Data arg)\n" +
+			"  void Simple() throws (1: SimpleErr simpleErr)\n" +
+			"  void SimpleFuture() throws (1: SimpleErr simpleErr, 2: NewErr newErr)\n" +
+			"}\n" +
+			"\n" +
+			"service SecondService {\n" +
+			"  string Echo(1: string arg)\n" +
+			"}\n" +
+			"\n" +
+			"struct HealthStatus {\n" +
+			"    1: required bool ok\n" +
+			"    2: optional string message\n" +
+			"}\n" +
+			"\n" +
+			"// Meta contains the old health endpoint without arguments.\n" +
+			"service Meta {\n" +
+			"    HealthStatus health()\n" +
+			"}",
+	},
+}
+
 // Interfaces for the service and client for the services defined in the IDL.
 
 // TChanMeta is the interface that defines the server handler and client interface.
@@ -78,6 +118,11 @@ func (s *tchanMetaServer) Service() string {
 	return "Meta"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanMetaServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanMetaServer) Methods() []string {
 	return []string{
 		"health",
@@ -94,24 +139,59 @@ func (s *tchanMetaServer) Handle(ctx thrift.Context, methodName string, protocol
 	}
 }
 
+func (s *tchanMetaServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "health":
+		var req MetaHealthArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanMetaServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "health":
+		return s.handleHealthArgs(ctx, args.(*MetaHealthArgs))
+
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanMetaServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanMetaServer) handleHealth(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req MetaHealthArgs
-	var res MetaHealthResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleHealthArgs(ctx, &req)
+}
+
+func (s *tchanMetaServer) handleHealthArgs(ctx thrift.Context, req *MetaHealthArgs) (bool, athrift.TStruct, error) {
+	var res MetaHealthResult
+
 	r, err :=
 		s.handler.Health(ctx)
 
 	if err != nil {
 		return false, nil, err
-	} else {
-		res.Success = r
 	}
 
-	return err == nil, &res, nil
+	res.Success = r
+	return true, &res, nil
 }
 
 type tchanSecondServiceClient struct {
@@ -163,6 +243,11 @@ func (s *tchanSecondServiceServer) Service() string {
 	return "SecondService"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanSecondServiceServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanSecondServiceServer) Methods() []string {
 	return []string{
 		"Echo",
@@ -179,24 +264,59 @@ func (s *tchanSecondServiceServer) Handle(ctx thrift.Context, methodName string,
 	}
 }
 
+func (s *tchanSecondServiceServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "Echo":
+		var req SecondServiceEchoArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanSecondServiceServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "Echo":
+		return s.handleEchoArgs(ctx, args.(*SecondServiceEchoArgs))
+
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanSecondServiceServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanSecondServiceServer) handleEcho(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req SecondServiceEchoArgs
-	var res SecondServiceEchoResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleEchoArgs(ctx, &req)
+}
+
+func (s *tchanSecondServiceServer) handleEchoArgs(ctx thrift.Context, req *SecondServiceEchoArgs) (bool, athrift.TStruct, error) {
+	var res SecondServiceEchoResult
+
 	r, err :=
 		s.handler.Echo(ctx, req.Arg)
 
 	if err != nil {
 		return false, nil, err
-	} else {
-		res.Success = &r
 	}
 
-	return err == nil, &res, nil
+	res.Success = &r
+	return true, &res, nil
 }
 
 type tchanSimpleServiceClient struct {
@@ -282,6 +402,11 @@ func (s *tchanSimpleServiceServer) Service() string {
 	return "SimpleService"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *tchanSimpleServiceServer) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *tchanSimpleServiceServer) Methods() []string {
 	return []string{
 		"Call",
@@ -304,81 +429,156 @@ func (s *tchanSimpleServiceServer) Handle(ctx thrift.Context, methodName string,
 	}
 }
 
+func (s *tchanSimpleServiceServer) ReadArgs(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "Call":
+		var req SimpleServiceCallArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	case "Simple":
+		var req SimpleServiceSimpleArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	case "SimpleFuture":
+		var req SimpleServiceSimpleFutureArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanSimpleServiceServer) HandleArgs(ctx thrift.Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "Call":
+		return s.handleCallArgs(ctx, args.(*SimpleServiceCallArgs))
+	case "Simple":
+		return s.handleSimpleArgs(ctx, args.(*SimpleServiceSimpleArgs))
+	case "SimpleFuture":
+		return s.handleSimpleFutureArgs(ctx, args.(*SimpleServiceSimpleFutureArgs))
+
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanSimpleServiceServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+
+	case "Simple":
+		var res SimpleServiceSimpleResult
+		return s.handleSimpleError(&res, err)
+
+	case "SimpleFuture":
+		var res SimpleServiceSimpleFutureResult
+		return s.handleSimpleFutureError(&res, err)
+
+	default:
+		return false, nil, err
+	}
+}
+
 func (s *tchanSimpleServiceServer) handleCall(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req SimpleServiceCallArgs
-	var res SimpleServiceCallResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleCallArgs(ctx, &req)
+}
+
+func (s *tchanSimpleServiceServer) handleCallArgs(ctx thrift.Context, req *SimpleServiceCallArgs) (bool, athrift.TStruct, error) {
+	var res SimpleServiceCallResult
+
 	r, err :=
 		s.handler.Call(ctx, req.Arg)
 
 	if err != nil {
 		return false, nil, err
-	} else {
-		res.Success = r
 	}
 
-	return err == nil, &res, nil
+	res.Success = r
+	return true, &res, nil
 }
 
 func (s *tchanSimpleServiceServer) handleSimple(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req SimpleServiceSimpleArgs
-	var res SimpleServiceSimpleResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleSimpleArgs(ctx, &req)
+}
+
+func (s *tchanSimpleServiceServer) handleSimpleArgs(ctx thrift.Context, req *SimpleServiceSimpleArgs) (bool, athrift.TStruct, error) {
+	var res SimpleServiceSimpleResult
+
 	err :=
 		s.handler.Simple(ctx)
 
 	if err != nil {
-		switch v := err.(type) {
-		case *SimpleErr:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for simpleErr returned non-nil error type *SimpleErr but nil value")
-			}
-			res.SimpleErr = v
-		default:
-			return false, nil, err
-		}
-	} else {
+		return s.handleSimpleError(&res, err)
 	}
 
-	return err == nil, &res, nil
+	return true, &res, nil
+}
+
+func (s *tchanSimpleServiceServer) handleSimpleError(res *SimpleServiceSimpleResult, err error) (bool, athrift.TStruct, error) {
+	switch v := err.(type) {
+	case *SimpleErr:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for simpleErr returned non-nil error type *SimpleErr but nil value")
+		}
+		res.SimpleErr = v
+	default:
+		return false, nil, err
+	}
+	return false, res, nil
 }
 
 func (s *tchanSimpleServiceServer) handleSimpleFuture(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req SimpleServiceSimpleFutureArgs
-	var res SimpleServiceSimpleFutureResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleSimpleFutureArgs(ctx, &req)
+}
+
+func (s *tchanSimpleServiceServer) handleSimpleFutureArgs(ctx thrift.Context, req *SimpleServiceSimpleFutureArgs) (bool, athrift.TStruct, error) {
+	var res SimpleServiceSimpleFutureResult
+
 	err :=
 		s.handler.SimpleFuture(ctx)
 
 	if err != nil {
-		switch v := err.(type) {
-		case *SimpleErr:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for simpleErr returned non-nil error type *SimpleErr but nil value")
-			}
-			res.SimpleErr = v
-		case *NewErr_:
-			if v == nil {
-				return false, nil, fmt.Errorf("Handler for newErr returned non-nil error type *NewErr_ but nil value")
-			}
-			res.NewErr_ = v
-		default:
-			return false, nil, err
-		}
-	} else {
+		return s.handleSimpleFutureError(&res, err)
 	}
 
-	return err == nil, &res, nil
+	return true, &res, nil
+}
+
+func (s *tchanSimpleServiceServer) handleSimpleFutureError(res *SimpleServiceSimpleFutureResult, err error) (bool, athrift.TStruct, error) {
+	switch v := err.(type) {
+	case *SimpleErr:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for simpleErr returned non-nil error type *SimpleErr but nil value")
+		}
+		res.SimpleErr = v
+	case *NewErr_:
+		if v == nil {
+			return false, nil, fmt.Errorf("Handler for newErr returned non-nil error type *NewErr_ but nil value")
+		}
+		res.NewErr_ = v
+	default:
+		return false, nil, err
+	}
+	return false, res, nil
 }
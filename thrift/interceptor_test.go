@@ -0,0 +1,213 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/testutils"
+	tcthrift "github.com/temporalio/tchannel-go/thrift"
+	"github.com/temporalio/tchannel-go/thrift/gen-go/meta"
+	gen "github.com/temporalio/tchannel-go/thrift/gen-go/test"
+	genmocks "github.com/temporalio/tchannel-go/thrift/gen-go/test/mocks"
+
+	athrift "github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSimpleClient(ts *testutils.TestServer) gen.TChanSimpleService {
+	return gen.NewTChanSimpleServiceClient(tcthrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &tcthrift.ClientOptions{
+		HostPort: ts.HostPort(),
+	}))
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		var calls []string
+		record := func(name string) tcthrift.Interceptor {
+			return func(ctx tcthrift.Context, method string, args athrift.TStruct, next tcthrift.ArgsHandler) (bool, athrift.TStruct, error) {
+				calls = append(calls, name+":"+method+":"+ctx.Headers()["user"])
+				return next(ctx, method, args)
+			}
+		}
+
+		fake := genmocks.NewFakeTChanSimpleService()
+		fake.CallFunc = func(ctx tcthrift.Context, arg *gen.Data) (*gen.Data, error) {
+			calls = append(calls, "handler")
+			return arg, nil
+		}
+		tcthrift.NewServer(ts.Server()).Register(gen.NewTChanSimpleServiceServer(fake),
+			tcthrift.OptInterceptors(record("first"), record("second")),
+			tcthrift.OptInterceptors(record("third")),
+		)
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		ctx = tcthrift.WithHeaders(ctx, map[string]string{"user": "alice"})
+
+		_, err := newSimpleClient(ts).Call(ctx, &gen.Data{S2: "hello"})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, []string{"first:Call:alice", "second:Call:alice", "third:Call:alice", "handler"}, calls,
+			"Unexpected call order")
+	})
+}
+
+func TestInterceptorArgs(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		validate := func(ctx tcthrift.Context, method string, args athrift.TStruct, next tcthrift.ArgsHandler) (bool, athrift.TStruct, error) {
+			if callArgs, ok := args.(*gen.SimpleServiceCallArgs); ok && callArgs.Arg.S2 == "" {
+				return false, nil, tchannel.NewSystemError(tchannel.ErrCodeBadRequest, "S2 is required")
+			}
+			// Modify the arguments before they're passed to the handler.
+			if callArgs, ok := args.(*gen.SimpleServiceCallArgs); ok {
+				callArgs.Arg.I3++
+			}
+			return next(ctx, method, args)
+		}
+
+		fake := genmocks.NewFakeTChanSimpleService()
+		fake.CallFunc = func(ctx tcthrift.Context, arg *gen.Data) (*gen.Data, error) {
+			return arg, nil
+		}
+		tcthrift.NewServer(ts.Server()).Register(gen.NewTChanSimpleServiceServer(fake), tcthrift.OptInterceptors(validate))
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		client := newSimpleClient(ts)
+		res, err := client.Call(ctx, &gen.Data{S2: "hello", I3: 1})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, &gen.Data{S2: "hello", I3: 2}, res, "Unexpected response")
+
+		_, err = client.Call(ctx, &gen.Data{})
+		assert.Equal(t, tchannel.ErrCodeBadRequest, tchannel.GetSystemErrorCode(err), "Unexpected error code")
+		assert.Contains(t, err.Error(), "S2 is required", "Unexpected error")
+		assert.Len(t, fake.CallCalls(), 1, "Handler should only be called for valid requests")
+	})
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		shortCircuit := func(ctx tcthrift.Context, method string, args athrift.TStruct, next tcthrift.ArgsHandler) (bool, athrift.TStruct, error) {
+			switch ctx.Headers()["mode"] {
+			case "response":
+				return true, &gen.SimpleServiceCallResult{Success: &gen.Data{S2: "cached"}}, nil
+			case "exception":
+				return false, nil, &gen.SimpleErr{Message: "denied"}
+			case "error":
+				return false, nil, errors.New("unexpected failure")
+			}
+			return next(ctx, method, args)
+		}
+
+		fake := genmocks.NewFakeTChanSimpleService()
+		tcthrift.NewServer(ts.Server()).Register(gen.NewTChanSimpleServiceServer(fake), tcthrift.OptInterceptors(shortCircuit))
+		client := newSimpleClient(ts)
+
+		withMode := func(mode string) (tcthrift.Context, func()) {
+			ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+			return tcthrift.WithHeaders(ctx, map[string]string{"mode": mode}), cancel
+		}
+
+		ctx, cancel := withMode("response")
+		defer cancel()
+		res, err := client.Call(ctx, &gen.Data{S2: "hello"})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, "cached", res.S2, "Unexpected response")
+
+		ctx, cancel = withMode("exception")
+		defer cancel()
+		err = client.Simple(ctx)
+		assert.Equal(t, &gen.SimpleErr{Message: "denied"}, err, "Expected declared exception")
+
+		// An exception that is not declared for Call is returned as a SystemError.
+		_, err = client.Call(ctx, &gen.Data{S2: "hello"})
+		assert.Equal(t, tchannel.ErrCodeUnexpected, tchannel.GetSystemErrorCode(err), "Unexpected error code")
+
+		ctx, cancel = withMode("error")
+		defer cancel()
+		err = client.SimpleFuture(ctx)
+		assert.Equal(t, tchannel.ErrCodeUnexpected, tchannel.GetSystemErrorCode(err), "Unexpected error code")
+
+		assert.Empty(t, fake.CallCalls(), "Handler should not be called")
+		assert.Empty(t, fake.SimpleCalls(), "Handler should not be called")
+		assert.Empty(t, fake.SimpleFutureCalls(), "Handler should not be called")
+	})
+}
+
+func TestInterceptorMeta(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		var methods []string
+		server := tcthrift.NewServer(ts.Server())
+		server.RegisterMeta(tcthrift.OptInterceptors(func(ctx tcthrift.Context, method string, args athrift.TStruct, next tcthrift.ArgsHandler) (bool, athrift.TStruct, error) {
+			_, ok := args.(*meta.MetaHealthArgs)
+			assert.True(t, ok, "Unexpected args type %T", args)
+			methods = append(methods, method)
+			return next(ctx, method, args)
+		}))
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		client := gen.NewTChanMetaClient(tcthrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &tcthrift.ClientOptions{
+			HostPort: ts.HostPort(),
+		}))
+		res, err := client.Health(ctx)
+		require.NoError(t, err, "Health failed")
+		assert.True(t, res.Ok, "Health should be ok")
+		assert.Equal(t, []string{"health"}, methods, "Unexpected intercepted methods")
+	})
+}
+
+func TestInterceptorRequiresArgsServer(t *testing.T) {
+	opts := testutils.NewOpts().
+		AddLogFilter("Calls to the service will fail.", 1).
+		AddLogFilter("Thrift server error.", 1)
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		var intercepted, handled bool
+		intercept := func(ctx tcthrift.Context, method string, args athrift.TStruct, next tcthrift.ArgsHandler) (bool, athrift.TStruct, error) {
+			intercepted = true
+			return next(ctx, method, args)
+		}
+
+		fake := genmocks.NewFakeTChanSimpleService()
+		fake.CallFunc = func(ctx tcthrift.Context, arg *gen.Data) (*gen.Data, error) {
+			handled = true
+			return arg, nil
+		}
+
+		// Embedding only TChanServer hides the TChanArgsServer methods.
+		server := struct{ tcthrift.TChanServer }{gen.NewTChanSimpleServiceServer(fake)}
+		tcthrift.NewServer(ts.Server()).Register(server, tcthrift.OptInterceptors(intercept))
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		_, err := newSimpleClient(ts).Call(ctx, &gen.Data{S2: "hello"})
+		assert.Error(t, err, "Calls should fail rather than skip interceptors")
+		assert.False(t, intercepted, "Interceptor should not be called")
+		assert.False(t, handled, "Handler should not be called")
+	})
+}
@@ -29,6 +29,7 @@ import athrift "github.com/apache/thrift/lib/go/thrift"
 // TChanClient abstracts calling a Thrift endpoint, and is used by the generated client code.
 type TChanClient interface {
 	// Call should be passed the method to call and the request/response Thrift structs.
+	// resp is nil for oneway methods, which only wait for the call to be acknowledged.
 	Call(ctx Context, serviceName, methodName string, req, resp athrift.TStruct) (success bool, err error)
 }
 
@@ -44,3 +45,41 @@ type TChanServer interface {
 	// Methods returns the method names handled by this server.
 	Methods() []string
 }
+
+// TChanOnewayServer is implemented by a TChanServer that handles oneway methods.
+// The server acknowledges calls to oneway methods as soon as the request is read,
+// and then calls Handle. Errors from Handle are logged rather than returned to the caller.
+type TChanOnewayServer interface {
+	TChanServer
+
+	// OnewayMethods returns the names of the oneway methods handled by this server.
+	OnewayMethods() []string
+}
+
+// TChanArgsServer is implemented by a TChanServer that can decode the arguments
+// for a method separately from handling them, which allows interceptors to see
+// the decoded arguments. Servers generated by thrift-gen implement this interface.
+type TChanArgsServer interface {
+	TChanServer
+
+	// ReadArgs reads the arguments struct for the given method from the protocol.
+	ReadArgs(ctx Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error)
+
+	// HandleArgs calls the handler for the method with arguments returned by ReadArgs.
+	// The arguments returned are the same as Handle.
+	HandleArgs(ctx Context, methodName string, args athrift.TStruct) (success bool, resp athrift.TStruct, err error)
+
+	// HandleError returns the result struct for an error that is a declared exception
+	// of the method, and returns any other error unchanged.
+	HandleError(methodName string, err error) (bool, athrift.TStruct, error)
+}
+
+// TChanIDLServer is implemented by a TChanServer that knows the Thrift IDL it was
+// generated from. When it's registered, the IDL is returned by the Meta service's
+// thriftIDL method. Servers generated by thrift-gen implement this interface.
+type TChanIDLServer interface {
+	TChanServer
+
+	// ThriftIDL returns the Thrift IDL that defines the service.
+	ThriftIDL() IDL
+}
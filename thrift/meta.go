@@ -24,6 +24,7 @@ import (
 	"errors"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/temporalio/tchannel-go"
 	"github.com/temporalio/tchannel-go/thrift/gen-go/meta"
@@ -57,9 +58,42 @@ type HealthRequest struct {
 // about the health check.
 type HealthRequestFunc func(Context, HealthRequest) (ok bool, message string)
 
+// IDL is a Thrift IDL file and the files that it includes.
+type IDL struct {
+	// EntryPoint is the name of the file that includes the other files.
+	EntryPoint string
+
+	// Files maps file names to their contents. File names are relative to the
+	// directory of the entry point.
+	Files map[string]string
+}
+
+// GetThriftIDL returns the Thrift IDL published by the Meta service of the
+// server that client makes calls to.
+func GetThriftIDL(ctx Context, client TChanClient) (*IDL, error) {
+	res, err := newTChanMetaClient(client).ThriftIDL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idl := &IDL{
+		EntryPoint: string(res.EntryPoint),
+		Files:      make(map[string]string, len(res.Idls)),
+	}
+	for name, contents := range res.Idls {
+		idl.Files[string(name)] = contents
+	}
+	return idl, nil
+}
+
 // healthHandler implements the default health check enpoint.
 type metaHandler struct {
 	healthFn HealthRequestFunc
+
+	// idl contains the files of all registered IDLs, where the entry point is
+	// the IDL of the first registered service.
+	idlMut sync.RWMutex
+	idl    IDL
 }
 
 // newMetaHandler return a new HealthHandler instance.
@@ -77,8 +111,21 @@ func (h *metaHandler) Health(ctx Context, req *meta.HealthRequest) (*meta.Health
 }
 
 func (h *metaHandler) ThriftIDL(ctx Context) (*meta.ThriftIDLs, error) {
-	// TODO(prashant): Add thriftIDL to the generated code.
-	return nil, errors.New("unimplemented")
+	h.idlMut.RLock()
+	defer h.idlMut.RUnlock()
+
+	if h.idl.EntryPoint == "" {
+		return nil, errors.New("no Thrift IDL registered")
+	}
+
+	idls := make(map[meta.Filename]string, len(h.idl.Files))
+	for name, contents := range h.idl.Files {
+		idls[meta.Filename(name)] = contents
+	}
+	return &meta.ThriftIDLs{
+		Idls:       idls,
+		EntryPoint: meta.Filename(h.idl.EntryPoint),
+	}, nil
 }
 
 func (h *metaHandler) VersionInfo(ctx Context) (*meta.VersionInfo, error) {
@@ -97,6 +144,23 @@ func (h *metaHandler) setHandler(f HealthRequestFunc) {
 	h.healthFn = f
 }
 
+// addIDL adds the files of idl to the IDL returned by ThriftIDL. Files that
+// were added by a previously registered IDL are not replaced.
+func (h *metaHandler) addIDL(idl IDL) {
+	h.idlMut.Lock()
+	defer h.idlMut.Unlock()
+
+	if h.idl.EntryPoint == "" {
+		h.idl.EntryPoint = idl.EntryPoint
+		h.idl.Files = make(map[string]string)
+	}
+	for name, contents := range idl.Files {
+		if _, ok := h.idl.Files[name]; !ok {
+			h.idl.Files[name] = contents
+		}
+	}
+}
+
 func metaReqToReq(r *meta.HealthRequest) HealthRequest {
 	if r == nil {
 		return HealthRequest{}
@@ -34,11 +34,51 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+type idlServer struct {
+	TChanServer
+	service string
+	idl     IDL
+}
+
+func (s idlServer) Service() string   { return s.service }
+func (s idlServer) Methods() []string { return nil }
+func (s idlServer) ThriftIDL() IDL    { return s.idl }
+
 func TestThriftIDL(t *testing.T) {
 	withMetaSetup(t, func(ctx Context, c tchanMeta, server *Server) {
 		_, err := c.ThriftIDL(ctx)
-		assert.Error(t, err, "Health endpoint failed")
-		assert.Contains(t, err.Error(), "unimplemented")
+		assert.Error(t, err, "ThriftIDL should fail without a registered IDL")
+		assert.Contains(t, err.Error(), "no Thrift IDL registered")
+	})
+}
+
+func TestThriftIDLRegistered(t *testing.T) {
+	withMetaSetup(t, func(ctx Context, c tchanMeta, server *Server) {
+		server.Register(idlServer{service: "First", idl: IDL{
+			EntryPoint: "first.thrift",
+			Files: map[string]string{
+				"first.thrift":  "include \"shared.thrift\"",
+				"shared.thrift": "typedef string UUID",
+			},
+		}})
+		server.Register(idlServer{service: "Second", idl: IDL{
+			EntryPoint: "second.thrift",
+			Files: map[string]string{
+				"second.thrift": "service Second {}",
+				"shared.thrift": "typedef string Other",
+			},
+		}})
+
+		ret, err := c.ThriftIDL(ctx)
+		require.NoError(t, err, "ThriftIDL failed")
+		assert.Equal(t, &meta.ThriftIDLs{
+			EntryPoint: "first.thrift",
+			Idls: map[meta.Filename]string{
+				"first.thrift":  "include \"shared.thrift\"",
+				"second.thrift": "service Second {}",
+				"shared.thrift": "typedef string UUID",
+			},
+		}, ret, "Unexpected IDLs")
 	})
 }
 
@@ -0,0 +1,96 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go/testutils"
+	tcthrift "github.com/temporalio/tchannel-go/thrift"
+	gen "github.com/temporalio/tchannel-go/thrift/gen-go/test"
+	genmocks "github.com/temporalio/tchannel-go/thrift/gen-go/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedFake(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		fake := genmocks.NewFakeTChanSimpleService()
+		fake.CallFunc = func(ctx tcthrift.Context, arg *gen.Data) (*gen.Data, error) {
+			return &gen.Data{S2: arg.S2 + " reply"}, nil
+		}
+		fake.SimpleFunc = func(ctx tcthrift.Context) error {
+			return &gen.SimpleErr{Message: "simple failed"}
+		}
+		fake.Register(ts.Server())
+
+		client := gen.NewTChanSimpleServiceClient(tcthrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &tcthrift.ClientOptions{
+			HostPort: ts.HostPort(),
+		}))
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		res, err := client.Call(ctx, &gen.Data{B1: true, S2: "hello", I3: 1})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, "hello reply", res.S2, "Unexpected response")
+
+		err = client.Simple(ctx)
+		assert.Equal(t, &gen.SimpleErr{Message: "simple failed"}, err, "Unexpected error from Simple")
+
+		// Methods without a stub return zero values.
+		assert.NoError(t, client.SimpleFuture(ctx), "SimpleFuture failed")
+
+		calls := fake.CallCalls()
+		require.Len(t, calls, 1, "Unexpected number of calls to Call")
+		assert.Equal(t, &gen.Data{B1: true, S2: "hello", I3: 1}, calls[0].Arg, "Unexpected recorded argument")
+		assert.Len(t, fake.SimpleCalls(), 1, "Unexpected number of calls to Simple")
+		assert.Len(t, fake.SimpleFutureCalls(), 1, "Unexpected number of calls to SimpleFuture")
+	})
+}
+
+func TestGeneratedMock(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		handler := new(genmocks.TChanSecondService)
+		handler.On("Echo", mock.Anything, "hello").Return("hello back", nil)
+		handler.On("Echo", mock.Anything, "fail").Return("", errors.New("echo failed"))
+		tcthrift.NewServer(ts.Server()).Register(gen.NewTChanSecondServiceServer(handler))
+
+		client := gen.NewTChanSecondServiceClient(tcthrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &tcthrift.ClientOptions{
+			HostPort: ts.HostPort(),
+		}))
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		res, err := client.Echo(ctx, "hello")
+		require.NoError(t, err, "Echo failed")
+		assert.Equal(t, "hello back", res, "Unexpected response")
+
+		_, err = client.Echo(ctx, "fail")
+		assert.Error(t, err, "Echo should fail")
+		handler.AssertExpectations(t)
+	})
+}
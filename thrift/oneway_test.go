@@ -0,0 +1,109 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go/testutils"
+	tcthrift "github.com/temporalio/tchannel-go/thrift"
+	gen "github.com/temporalio/tchannel-go/thrift/gen-go/test"
+
+	athrift "github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type onewayCall struct {
+	method  string
+	arg     string
+	headers map[string]string
+	ctxErr  error
+}
+
+// onewayServer handles oneway calls with SecondService's Echo arguments,
+// blocking each call until release is closed.
+type onewayServer struct {
+	release chan struct{}
+	calls   chan onewayCall
+}
+
+func (s *onewayServer) Service() string         { return "Oneway" }
+func (s *onewayServer) Methods() []string       { return []string{"fire", "fail"} }
+func (s *onewayServer) OnewayMethods() []string { return s.Methods() }
+
+func (s *onewayServer) Handle(ctx tcthrift.Context, methodName string, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var args gen.SecondServiceEchoArgs
+	if err := args.Read(ctx, protocol); err != nil {
+		return false, nil, err
+	}
+
+	<-s.release
+	s.calls <- onewayCall{
+		method:  methodName,
+		arg:     args.Arg,
+		headers: ctx.Headers(),
+		ctxErr:  ctx.Err(),
+	}
+	if methodName == "fail" {
+		return false, nil, errors.New("oneway handler failed")
+	}
+	return true, nil, nil
+}
+
+func TestOnewayCall(t *testing.T) {
+	opts := testutils.NewOpts().AddLogFilter("Thrift server error.", 1)
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		server := &onewayServer{
+			release: make(chan struct{}),
+			calls:   make(chan onewayCall, 2),
+		}
+		tcthrift.NewServer(ts.Server()).Register(server)
+
+		client := tcthrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &tcthrift.ClientOptions{HostPort: ts.HostPort()})
+		for _, method := range []string{"fire", "fail"} {
+			ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+			ctx = tcthrift.WithHeaders(ctx, map[string]string{"method": method})
+
+			// The call is acknowledged before the handler runs, so it returns
+			// while the handler is blocked, and handler errors are not returned.
+			success, err := client.Call(ctx, "Oneway", method, &gen.SecondServiceEchoArgs{Arg: method + "-arg"}, nil)
+			require.NoError(t, err, "%v failed", method)
+			assert.True(t, success, "%v should succeed", method)
+			cancel()
+		}
+
+		close(server.release)
+		for _, method := range []string{"fire", "fail"} {
+			select {
+			case call := <-server.calls:
+				assert.Equal(t, method, call.method, "Unexpected method")
+				assert.Equal(t, method+"-arg", call.arg, "Unexpected arg")
+				assert.Equal(t, map[string]string{"method": method}, call.headers, "Unexpected headers")
+				assert.NoError(t, call.ctxErr, "Handler context should not be canceled once the call is acknowledged")
+			case <-time.After(testutils.Timeout(time.Second)):
+				t.Fatalf("Timed out waiting for %v to be handled", method)
+			}
+		}
+	})
+}
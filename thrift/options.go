@@ -25,6 +25,29 @@ import (
 	"golang.org/x/net/context"
 )
 
+// ServerOption is the interface for options to NewServer.
+type ServerOption interface {
+	Apply(s *Server)
+}
+
+type optProtocols []Protocol
+
+// OptProtocols restricts the protocols that the server accepts to the given
+// protocols. Calls using any other protocol fail with a BadRequest error.
+// Clients use BinaryProtocol unless configured otherwise.
+func OptProtocols(protocols ...Protocol) ServerOption {
+	return optProtocols(protocols)
+}
+
+func (o optProtocols) Apply(s *Server) {
+	if s.protocols == nil {
+		s.protocols = make(map[Protocol]bool)
+	}
+	for _, p := range o {
+		s.protocols[p] = true
+	}
+}
+
 // RegisterOption is the interface for options to Register.
 type RegisterOption interface {
 	Apply(h *handler)
@@ -45,3 +68,29 @@ func OptPostResponse(cb PostResponseCB) RegisterOption {
 func (o optPostResponse) Apply(h *handler) {
 	h.postResponseCB = PostResponseCB(o)
 }
+
+// ArgsHandler handles a call to method with the decoded arguments struct, and
+// returns the same results as TChanServer's Handle.
+type ArgsHandler func(ctx Context, method string, args thrift.TStruct) (success bool, resp thrift.TStruct, err error)
+
+// Interceptor is called with the decoded arguments struct before the handler for
+// a method. It calls next to continue with the next interceptor and then the handler,
+// or it can short-circuit the call by returning a result struct or an error without
+// calling next. Errors that are declared exceptions for the method are returned as
+// that exception, and any other error is returned as a SystemError.
+// Headers for the call are available using ctx.Headers().
+type Interceptor func(ctx Context, method string, args thrift.TStruct, next ArgsHandler) (success bool, resp thrift.TStruct, err error)
+
+type optInterceptors []Interceptor
+
+// OptInterceptors registers interceptors that are called for every method of the
+// service, in the order they are specified. The registered TChanServer must
+// implement TChanArgsServer, otherwise an error is logged on registration and
+// every call to the service fails.
+func OptInterceptors(interceptors ...Interceptor) RegisterOption {
+	return optInterceptors(interceptors)
+}
+
+func (o optInterceptors) Apply(h *handler) {
+	h.interceptors = append(h.interceptors, o...)
+}
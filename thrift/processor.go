@@ -0,0 +1,226 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sort"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/internal/argreader"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// RegisterProcessor registers an Apache Thrift TProcessor on the registrar, so
+// that calls to service::method are handled by the processor, for every method
+// in its processor map.
+//
+// Handlers receive a context with the call's headers, and can set response
+// headers using Wrap(ctx).SetResponseHeaders. Declared exceptions are returned
+// as application errors, and TApplicationExceptions as system errors.
+func RegisterProcessor(registrar tchannel.Registrar, service string, processor thrift.TProcessor) {
+	h := &processorHandler{
+		registrar: registrar,
+		processor: processor,
+	}
+
+	methods := make([]string, 0, len(processor.ProcessorMap()))
+	for method := range processor.ProcessorMap() {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		registrar.Register(h, service+"::"+method)
+	}
+}
+
+// processorHandler is a tchannel.Handler that handles calls using a TProcessor.
+type processorHandler struct {
+	registrar tchannel.Registrar
+	processor thrift.TProcessor
+}
+
+// Handle handles an incoming TChannel call using the processor.
+func (h *processorHandler) Handle(ctx context.Context, call *tchannel.InboundCall) {
+	if err := h.handle(ctx, call); err != nil {
+		h.registrar.Logger().WithFields(
+			tchannel.ErrField(err),
+			tchannel.LogField{Key: "method", Value: call.MethodString()},
+			tchannel.LogField{Key: "callerName", Value: call.CallerName()},
+		).Error("Thrift processor error.")
+	}
+}
+
+func (h *processorHandler) handle(ctx context.Context, call *tchannel.InboundCall) error {
+	protocol, err := parseProtocol(call.ThriftProtocol())
+	if err != nil {
+		return call.Response().SendSystemError(tchannel.NewSystemError(tchannel.ErrCodeBadRequest, err.Error()))
+	}
+
+	reader, err := call.Arg2Reader()
+	if err != nil {
+		return err
+	}
+	headers, err := ReadHeaders(reader)
+	if err != nil {
+		return err
+	}
+	if err := argreader.EnsureEmpty(reader, "reading request headers"); err != nil {
+		return err
+	}
+	if err := reader.Close(); err != nil {
+		return err
+	}
+
+	reader, err = call.Arg3Reader()
+	if err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if err := reader.Close(); err != nil {
+		return err
+	}
+
+	tracer := tchannel.TracerFromRegistrar(h.registrar)
+	ctx = tchannel.ExtractInboundSpan(ctx, call, headers, tracer)
+	tctx := WithHeaders(ctx, headers)
+
+	// The processor reads and writes Thrift messages, but TChannel calls only
+	// contain the struct, so the message envelope is handled by the protocols.
+	_, method, _ := getServiceMethod(call.MethodString())
+	rp := getReader(protocol, bytes.NewReader(body))
+	defer rp.release()
+	in := &processorInProtocol{TProtocol: rp.protocol, method: method}
+
+	// The response is buffered, as the application error bit must be set
+	// before the response is written.
+	var resBody bytes.Buffer
+	wp := getWriter(protocol, &resBody)
+	defer wp.release()
+	out := &processorOutProtocol{TProtocol: wp.protocol}
+
+	_, processErr := h.processor.Process(tctx, in, out)
+
+	switch {
+	case !out.started && processErr != nil:
+		// Nothing is written for abandoned requests.
+		return call.Response().SendSystemError(processErr)
+	case out.messageType == thrift.EXCEPTION:
+		return call.Response().SendSystemError(readApplicationException(tctx, protocol, resBody.Bytes()))
+	case out.exceptionField:
+		call.Response().SetApplicationError()
+	}
+
+	writer, err := call.Response().Arg2Writer()
+	if err != nil {
+		return err
+	}
+	if err := WriteHeaders(writer, tctx.ResponseHeaders()); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	// Oneway methods do not write a response, so the body is empty.
+	return tchannel.NewArgWriter(call.Response().Arg3Writer()).Write(resBody.Bytes())
+}
+
+// readApplicationException decodes a TApplicationException written by a
+// processor, and returns it as a SystemError.
+func readApplicationException(ctx context.Context, protocol Protocol, body []byte) error {
+	ex := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "")
+	if err := readStruct(ctx, protocol, bytes.NewReader(body), ex); err != nil {
+		return err
+	}
+
+	code := tchannel.ErrCodeUnexpected
+	switch ex.TypeId() {
+	case thrift.UNKNOWN_METHOD, thrift.INVALID_MESSAGE_TYPE_EXCEPTION, thrift.WRONG_METHOD_NAME, thrift.PROTOCOL_ERROR:
+		code = tchannel.ErrCodeBadRequest
+	}
+	return tchannel.NewSystemError(code, ex.Error())
+}
+
+// processorInProtocol reads a TChannel Thrift request as a message for method.
+type processorInProtocol struct {
+	thrift.TProtocol
+
+	method string
+}
+
+func (p *processorInProtocol) ReadMessageBegin(ctx context.Context) (string, thrift.TMessageType, int32, error) {
+	return p.method, thrift.CALL, 0, nil
+}
+
+func (p *processorInProtocol) ReadMessageEnd(ctx context.Context) error {
+	return nil
+}
+
+// processorOutProtocol writes the struct in a response message, and records
+// the message type and whether the result struct contains an exception.
+type processorOutProtocol struct {
+	thrift.TProtocol
+
+	started     bool
+	messageType thrift.TMessageType
+
+	// depth is the depth of the struct being written, where the result is 1.
+	depth          int
+	wroteField     bool
+	exceptionField bool
+}
+
+func (p *processorOutProtocol) WriteMessageBegin(ctx context.Context, name string, typeID thrift.TMessageType, seqID int32) error {
+	p.started = true
+	p.messageType = typeID
+	return nil
+}
+
+func (p *processorOutProtocol) WriteMessageEnd(ctx context.Context) error {
+	return nil
+}
+
+func (p *processorOutProtocol) WriteStructBegin(ctx context.Context, name string) error {
+	p.depth++
+	return p.TProtocol.WriteStructBegin(ctx, name)
+}
+
+func (p *processorOutProtocol) WriteStructEnd(ctx context.Context) error {
+	p.depth--
+	return p.TProtocol.WriteStructEnd(ctx)
+}
+
+func (p *processorOutProtocol) WriteFieldBegin(ctx context.Context, name string, typeID thrift.TType, id int16) error {
+	// A result struct has a single field, which is either the success
+	// field with ID 0, or one of the method's exceptions.
+	if p.depth == 1 && !p.wroteField {
+		p.wroteField = true
+		p.exceptionField = id != 0
+	}
+	return p.TProtocol.WriteFieldBegin(ctx, name, typeID, id)
+}
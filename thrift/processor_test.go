@@ -0,0 +1,130 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/testutils"
+	tcthrift "github.com/temporalio/tchannel-go/thrift"
+	gen "github.com/temporalio/tchannel-go/thrift/gen-go/test"
+	genmocks "github.com/temporalio/tchannel-go/thrift/gen-go/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apacheSimpleService implements the Apache Thrift generated gen.SimpleService.
+type apacheSimpleService struct{}
+
+func (apacheSimpleService) Call(ctx context.Context, arg *gen.Data) (*gen.Data, error) {
+	tctx := tcthrift.Wrap(ctx)
+	tctx.SetResponseHeaders(map[string]string{"echo": tctx.Headers()["user"]})
+	return &gen.Data{S2: arg.S2 + " reply"}, nil
+}
+
+func (apacheSimpleService) Simple(ctx context.Context) error {
+	return &gen.SimpleErr{Message: "simple failed"}
+}
+
+func (apacheSimpleService) SimpleFuture(ctx context.Context) error {
+	return errors.New("unexpected failure")
+}
+
+func TestRegisterProcessor(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		tcthrift.RegisterProcessor(ts.Server(), "SimpleService", gen.NewSimpleServiceProcessor(apacheSimpleService{}))
+		client := newSimpleClient(ts)
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		ctx = tcthrift.WithHeaders(ctx, map[string]string{"user": "alice"})
+
+		res, err := client.Call(ctx, &gen.Data{S2: "hello"})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, "hello reply", res.S2, "Unexpected response")
+		assert.Equal(t, map[string]string{"echo": "alice"}, ctx.ResponseHeaders(), "Unexpected response headers")
+
+		err = client.Simple(ctx)
+		assert.Equal(t, &gen.SimpleErr{Message: "simple failed"}, err, "Expected declared exception")
+
+		err = client.SimpleFuture(ctx)
+		assert.Equal(t, tchannel.ErrCodeUnexpected, tchannel.GetSystemErrorCode(err), "Unexpected error code")
+		assert.Contains(t, err.Error(), "unexpected failure", "Unexpected error")
+	})
+}
+
+func TestRegisterProcessorUnknownMethod(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		// Remove Call from the processor map after it's registered, so the
+		// processor treats it as an unknown method.
+		processor := gen.NewSimpleServiceProcessor(apacheSimpleService{})
+		tcthrift.RegisterProcessor(ts.Server(), "SimpleService", processor)
+		delete(processor.ProcessorMap(), "Call")
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		_, err := newSimpleClient(ts).Call(ctx, &gen.Data{S2: "hello"})
+		assert.Equal(t, tchannel.ErrCodeBadRequest, tchannel.GetSystemErrorCode(err), "Unexpected error code")
+	})
+}
+
+func TestTClient(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		fake := genmocks.NewFakeTChanSimpleService()
+		fake.CallFunc = func(ctx tcthrift.Context, arg *gen.Data) (*gen.Data, error) {
+			ctx.SetResponseHeaders(map[string]string{"echo": ctx.Headers()["user"]})
+			return &gen.Data{S2: arg.S2 + " reply"}, nil
+		}
+		fake.SimpleFunc = func(ctx tcthrift.Context) error {
+			return &gen.SimpleErr{Message: "simple failed"}
+		}
+		fake.SimpleFutureFunc = func(ctx tcthrift.Context) error {
+			return errors.New("unexpected failure")
+		}
+		fake.Register(ts.Server())
+
+		tchanClient := tcthrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &tcthrift.ClientOptions{
+			HostPort: ts.HostPort(),
+		})
+		client := gen.NewSimpleServiceClient(tcthrift.NewTClient(tchanClient, "SimpleService"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutils.Timeout(time.Second))
+		defer cancel()
+
+		res, err := client.Call(tcthrift.WithHeaders(ctx, map[string]string{"user": "alice"}), &gen.Data{S2: "hello"})
+		require.NoError(t, err, "Call failed")
+		assert.Equal(t, "hello reply", res.S2, "Unexpected response")
+		assert.Equal(t, map[string]string{"echo": "alice"}, map[string]string(client.LastResponseMeta_().Headers),
+			"Unexpected response headers")
+
+		err = client.Simple(ctx)
+		assert.Equal(t, &gen.SimpleErr{Message: "simple failed"}, err, "Expected declared exception")
+
+		err = client.SimpleFuture(ctx)
+		assert.Equal(t, tchannel.ErrCodeUnexpected, tchannel.GetSystemErrorCode(err), "Unexpected error code")
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package thrift
+
+import "fmt"
+
+// Protocol is the Thrift protocol used to encode the arguments and results of calls.
+type Protocol string
+
+// The list of Thrift protocols supported by clients and servers.
+const (
+	// BinaryProtocol is used when no protocol is specified.
+	BinaryProtocol Protocol = "binary"
+
+	// CompactProtocol produces smaller payloads than the binary protocol.
+	CompactProtocol Protocol = "compact"
+
+	// JSONProtocol is human-readable, which is useful for debugging.
+	JSONProtocol Protocol = "json"
+)
+
+func (p Protocol) String() string {
+	return string(p)
+}
+
+// parseProtocol returns the protocol for the value of the ThriftProtocol
+// transport header, where an empty value is the binary protocol.
+func parseProtocol(v string) (Protocol, error) {
+	switch p := Protocol(v); p {
+	case "":
+		return BinaryProtocol, nil
+	case BinaryProtocol, CompactProtocol, JSONProtocol:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown Thrift protocol %q", v)
+}
+
+// header returns the value of the ThriftProtocol transport header for p.
+// The header is not set for the binary protocol, so calls can be handled
+// by servers that do not support other protocols.
+func (p Protocol) header() string {
+	if p == BinaryProtocol {
+		return ""
+	}
+	return string(p)
+}
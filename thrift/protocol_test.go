@@ -0,0 +1,163 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package thrift_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/temporalio/tchannel-go"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/testutils"
+	tcthrift "github.com/temporalio/tchannel-go/thrift"
+	gen "github.com/temporalio/tchannel-go/thrift/gen-go/test"
+	genmocks "github.com/temporalio/tchannel-go/thrift/gen-go/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var allProtocols = []tcthrift.Protocol{
+	tcthrift.BinaryProtocol,
+	tcthrift.CompactProtocol,
+	tcthrift.JSONProtocol,
+}
+
+func newProtocolClient(ts *testutils.TestServer, protocol tcthrift.Protocol) tcthrift.TChanClient {
+	return tcthrift.NewClient(ts.NewClient(nil), ts.ServiceName(), &tcthrift.ClientOptions{
+		HostPort: ts.HostPort(),
+		Protocol: protocol,
+	})
+}
+
+func TestProtocols(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		fake := genmocks.NewFakeTChanSimpleService()
+		fake.CallFunc = func(ctx tcthrift.Context, arg *gen.Data) (*gen.Data, error) {
+			ctx.SetResponseHeaders(map[string]string{"echo": ctx.Headers()["user"]})
+			return &gen.Data{B1: !arg.B1, S2: arg.S2 + " reply", I3: arg.I3 + 1}, nil
+		}
+		fake.SimpleFunc = func(ctx tcthrift.Context) error {
+			return &gen.SimpleErr{Message: "simple failed"}
+		}
+		server := tcthrift.NewServer(ts.Server())
+		server.Register(gen.NewTChanSimpleServiceServer(fake))
+
+		oneway := &onewayServer{
+			release: make(chan struct{}),
+			calls:   make(chan onewayCall, len(allProtocols)),
+		}
+		close(oneway.release)
+		server.Register(oneway)
+
+		for _, protocol := range allProtocols {
+			ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+			ctx = tcthrift.WithHeaders(ctx, map[string]string{"user": "alice"})
+
+			tclient := newProtocolClient(ts, protocol)
+			client := gen.NewTChanSimpleServiceClient(tclient)
+			res, err := client.Call(ctx, &gen.Data{S2: "hello", I3: 1})
+			require.NoError(t, err, "%v: Call failed", protocol)
+			assert.Equal(t, &gen.Data{B1: true, S2: "hello reply", I3: 2}, res, "%v: Unexpected response", protocol)
+			assert.Equal(t, map[string]string{"echo": "alice"}, ctx.ResponseHeaders(), "%v: Unexpected response headers", protocol)
+
+			err = client.Simple(ctx)
+			assert.Equal(t, &gen.SimpleErr{Message: "simple failed"}, err, "%v: Expected declared exception", protocol)
+
+			health, err := gen.NewTChanMetaClient(tclient).Health(ctx)
+			require.NoError(t, err, "%v: Health failed", protocol)
+			assert.True(t, health.Ok, "%v: Health should be ok", protocol)
+
+			_, err = tclient.Call(ctx, "Oneway", "fire", &gen.SecondServiceEchoArgs{Arg: protocol.String()}, nil)
+			require.NoError(t, err, "%v: oneway call failed", protocol)
+			select {
+			case call := <-oneway.calls:
+				assert.Equal(t, protocol.String(), call.arg, "%v: Unexpected oneway argument", protocol)
+			case <-ctx.Done():
+				t.Fatalf("%v: oneway handler was not called", protocol)
+			}
+			cancel()
+		}
+	})
+}
+
+func TestProtocolsRestricted(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		fake := genmocks.NewFakeTChanSimpleService()
+		fake.SimpleFunc = func(ctx tcthrift.Context) error {
+			return nil
+		}
+		tcthrift.NewServer(ts.Server(), tcthrift.OptProtocols(tcthrift.CompactProtocol)).
+			Register(gen.NewTChanSimpleServiceServer(fake))
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		err := gen.NewTChanSimpleServiceClient(newProtocolClient(ts, tcthrift.CompactProtocol)).Simple(ctx)
+		require.NoError(t, err, "Simple using compact protocol failed")
+
+		for _, protocol := range []tcthrift.Protocol{"", tcthrift.BinaryProtocol, tcthrift.JSONProtocol} {
+			err := gen.NewTChanSimpleServiceClient(newProtocolClient(ts, protocol)).Simple(ctx)
+			assert.Equal(t, tchannel.ErrCodeBadRequest, tchannel.GetSystemErrorCode(err), "%q: Unexpected error code", protocol)
+		}
+		assert.Len(t, fake.SimpleCalls(), 1, "Handler should only be called for accepted protocols")
+	})
+}
+
+func TestProtocolUnknown(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		tcthrift.NewServer(ts.Server())
+
+		ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+
+		err := gen.NewTChanSimpleServiceClient(newProtocolClient(ts, "xml")).Simple(ctx)
+		require.Error(t, err, "Call with unknown protocol should fail")
+		assert.Contains(t, err.Error(), "unknown Thrift protocol", "Unexpected error")
+
+		// Servers reject calls with an unknown protocol header.
+		call, err := ts.Server().BeginCall(ctx, ts.HostPort(), ts.ServiceName(), "Meta::health", &tchannel.CallOptions{
+			Format:         tchannel.Thrift,
+			ThriftProtocol: "xml",
+		})
+		require.NoError(t, err, "BeginCall failed")
+		_, _, _, err = raw.WriteArgs(call, []byte{0, 0}, []byte{0})
+		assert.Equal(t, tchannel.ErrCodeBadRequest, tchannel.GetSystemErrorCode(err), "Unexpected error code")
+	})
+}
+
+func TestRegisterProcessorProtocols(t *testing.T) {
+	testutils.WithTestServer(t, nil, func(t testing.TB, ts *testutils.TestServer) {
+		tcthrift.RegisterProcessor(ts.Server(), "SimpleService", gen.NewSimpleServiceProcessor(apacheSimpleService{}))
+
+		for _, protocol := range allProtocols {
+			ctx, cancel := tcthrift.NewContext(testutils.Timeout(time.Second))
+			client := gen.NewTChanSimpleServiceClient(newProtocolClient(ts, protocol))
+
+			res, err := client.Call(ctx, &gen.Data{S2: "hello"})
+			require.NoError(t, err, "%v: Call failed", protocol)
+			assert.Equal(t, "hello reply", res.S2, "%v: Unexpected response", protocol)
+
+			err = client.Simple(ctx)
+			assert.Equal(t, &gen.SimpleErr{Message: "simple failed"}, err, "%v: Expected declared exception", protocol)
+			cancel()
+		}
+	})
+}
@@ -21,9 +21,13 @@
 package thrift
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"strings"
 	"sync"
+	"time"
 
 	tchannel "github.com/temporalio/tchannel-go"
 	"github.com/temporalio/tchannel-go/internal/argreader"
@@ -35,6 +39,52 @@ import (
 type handler struct {
 	server         TChanServer
 	postResponseCB PostResponseCB
+
+	// oneway is the set of oneway methods, which are acknowledged before they are handled.
+	oneway map[string]bool
+
+	// interceptors are called with the decoded arguments before the handler.
+	interceptors []Interceptor
+}
+
+// call reads the arguments from the protocol and calls the handler for method.
+// If there are interceptors, the arguments are decoded before calling them.
+func (h handler) call(ctx Context, method string, protocol thrift.TProtocol) (bool, thrift.TStruct, error) {
+	if len(h.interceptors) == 0 {
+		return h.server.Handle(ctx, method, protocol)
+	}
+
+	argsServer, ok := h.server.(TChanArgsServer)
+	if !ok {
+		// Fail the call rather than skipping interceptors, which may enforce
+		// checks such as authorization.
+		return false, nil, errNotArgsServer(h.server.Service())
+	}
+	args, err := argsServer.ReadArgs(ctx, method, protocol)
+	if err != nil {
+		return false, nil, err
+	}
+
+	next := ArgsHandler(argsServer.HandleArgs)
+	for i := len(h.interceptors) - 1; i >= 0; i-- {
+		interceptor, nextHandler := h.interceptors[i], next
+		next = func(ctx Context, method string, args thrift.TStruct) (bool, thrift.TStruct, error) {
+			return interceptor(ctx, method, args, nextHandler)
+		}
+	}
+
+	success, resp, err := next(ctx, method, args)
+	if err != nil {
+		// Errors returned by interceptors may be declared exceptions.
+		return argsServer.HandleError(method, err)
+	}
+	return success, resp, nil
+}
+
+// errNotArgsServer is returned for calls to a service registered with
+// interceptors that does not implement TChanArgsServer.
+func errNotArgsServer(service string) error {
+	return fmt.Errorf("cannot use interceptors for service %v which does not implement TChanArgsServer", service)
 }
 
 // Server handles incoming TChannel calls and forwards them to the matching TChanServer.
@@ -45,10 +95,14 @@ type Server struct {
 	handlers    map[string]handler
 	metaHandler *metaHandler
 	ctxFn       func(ctx context.Context, method string, headers map[string]string) Context
+
+	// protocols is the set of protocols the server accepts, or nil for all protocols.
+	protocols map[Protocol]bool
 }
 
 // NewServer returns a server that can serve thrift services over TChannel.
-func NewServer(registrar tchannel.Registrar) *Server {
+// By default, the server accepts calls using any of the supported protocols.
+func NewServer(registrar tchannel.Registrar, opts ...ServerOption) *Server {
 	metaHandler := newMetaHandler()
 	server := &Server{
 		ch:          registrar,
@@ -57,30 +111,58 @@ func NewServer(registrar tchannel.Registrar) *Server {
 		metaHandler: metaHandler,
 		ctxFn:       defaultContextFn,
 	}
+	for _, opt := range opts {
+		opt.Apply(server)
+	}
 	server.Register(newTChanMetaServer(metaHandler))
 	if ch, ok := registrar.(*tchannel.Channel); ok {
 		// Register the meta endpoints on the "tchannel" service name.
-		NewServer(ch.GetSubChannel("tchannel"))
+		NewServer(ch.GetSubChannel("tchannel"), opts...)
 	}
 	return server
 }
 
 // Register registers the given TChanServer to be called on any incoming call for its' services.
+// If svr implements TChanIDLServer, its IDL is returned by the Meta service's thriftIDL method.
 // TODO(prashant): Replace Register call with this call.
 func (s *Server) Register(svr TChanServer, opts ...RegisterOption) {
+	s.setHandler(svr, opts)
+	if idlSvr, ok := svr.(TChanIDLServer); ok {
+		s.metaHandler.addIDL(idlSvr.ThriftIDL())
+	}
+	for _, m := range svr.Methods() {
+		s.ch.Register(s, svr.Service()+"::"+m)
+	}
+}
+
+// RegisterMeta sets options, such as interceptors, for the Meta service that
+// handles health checks and version information.
+func (s *Server) RegisterMeta(opts ...RegisterOption) {
+	s.setHandler(newTChanMetaServer(s.metaHandler), opts)
+}
+
+func (s *Server) setHandler(svr TChanServer, opts []RegisterOption) {
 	service := svr.Service()
 	handler := &handler{server: svr}
 	for _, opt := range opts {
 		opt.Apply(handler)
 	}
+	if _, ok := svr.(TChanArgsServer); !ok && len(handler.interceptors) > 0 {
+		s.log.WithFields(
+			tchannel.LogField{Key: "service", Value: service},
+			tchannel.ErrField(errNotArgsServer(service)),
+		).Error("Calls to the service will fail.")
+	}
+	if onewaySvr, ok := svr.(TChanOnewayServer); ok {
+		handler.oneway = make(map[string]bool)
+		for _, m := range onewaySvr.OnewayMethods() {
+			handler.oneway[m] = true
+		}
+	}
 
 	s.Lock()
 	s.handlers[service] = *handler
 	s.Unlock()
-
-	for _, m := range svr.Methods() {
-		s.ch.Register(s, service+"::"+m)
-	}
 }
 
 // RegisterHealthHandler uses the user-specified function f for the Health endpoint.
@@ -128,11 +210,30 @@ func (s *Server) onError(call *tchannel.InboundCall, err error) {
 	}
 }
 
+// callProtocol returns the protocol used by call, or a BadRequest error if
+// the server does not accept the protocol.
+func (s *Server) callProtocol(call *tchannel.InboundCall) (Protocol, error) {
+	protocol, err := parseProtocol(call.ThriftProtocol())
+	if err == nil && s.protocols != nil && !s.protocols[protocol] {
+		err = fmt.Errorf("Thrift protocol %v is not supported", protocol)
+	}
+	if err != nil {
+		return "", tchannel.NewSystemError(tchannel.ErrCodeBadRequest, err.Error())
+	}
+	return protocol, nil
+}
+
 func defaultContextFn(ctx context.Context, method string, headers map[string]string) Context {
 	return WithHeaders(ctx, headers)
 }
 
 func (s *Server) handle(origCtx context.Context, handler handler, method string, call *tchannel.InboundCall) error {
+	protocol, err := s.callProtocol(call)
+	if err != nil {
+		call.Response().SendSystemError(err)
+		return nil
+	}
+
 	reader, err := call.Arg2Reader()
 	if err != nil {
 		return err
@@ -157,11 +258,14 @@ func (s *Server) handle(origCtx context.Context, handler handler, method string,
 
 	tracer := tchannel.TracerFromRegistrar(s.ch)
 	origCtx = tchannel.ExtractInboundSpan(origCtx, call, headers, tracer)
+	if handler.oneway[method] {
+		return s.handleOneway(origCtx, handler, method, call, protocol, headers, reader)
+	}
 	ctx := s.ctxFn(origCtx, method, headers)
 
-	wp := getProtocolReader(reader)
-	success, resp, err := handler.server.Handle(ctx, method, wp.protocol)
-	thriftProtocolPool.Put(wp)
+	rp := getReader(protocol, reader)
+	success, resp, err := handler.call(ctx, method, rp.protocol)
+	rp.release()
 
 	if handler.postResponseCB != nil {
 		defer handler.postResponseCB(ctx, method, resp)
@@ -203,10 +307,7 @@ func (s *Server) handle(origCtx context.Context, handler handler, method string,
 
 	writer, err = call.Response().Arg3Writer()
 
-	wp = getProtocolWriter(writer)
-	defer thriftProtocolPool.Put(wp)
-
-	if err := resp.Write(ctx, wp.protocol); err != nil {
+	if err := writeStruct(ctx, protocol, writer, resp); err != nil {
 		call.Response().SendSystemError(err)
 		return err
 	}
@@ -214,6 +315,71 @@ func (s *Server) handle(origCtx context.Context, handler handler, method string,
 	return writer.Close()
 }
 
+// handleOneway acknowledges a call to a oneway method with an empty response
+// once the request has been read, and then calls the handler. The caller does
+// not wait for the handler, so any error is returned to be logged.
+func (s *Server) handleOneway(origCtx context.Context, handler handler, method string, call *tchannel.InboundCall, protocol Protocol, headers map[string]string, reader tchannel.ArgReader) error {
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if err := reader.Close(); err != nil {
+		return err
+	}
+
+	writer, err := call.Response().Arg2Writer()
+	if err != nil {
+		return err
+	}
+	if err := WriteHeaders(writer, nil); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	writer, err = call.Response().Arg3Writer()
+	if err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	// The call's context is canceled once the response is sent, so the handler
+	// gets a context with the same values and deadline that is not canceled.
+	detachedCtx, cancel := detachContext(origCtx)
+	defer cancel()
+	ctx := s.ctxFn(detachedCtx, method, headers)
+
+	rp := getReader(protocol, bytes.NewReader(body))
+	_, resp, err := handler.call(ctx, method, rp.protocol)
+	rp.release()
+
+	if handler.postResponseCB != nil {
+		handler.postResponseCB(ctx, method, resp)
+	}
+	return err
+}
+
+// detachedContext keeps the values of a context, but is never canceled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// detachContext returns a context with the values and deadline of ctx,
+// which is not canceled when ctx is canceled.
+func detachContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := detachedContext{ctx}
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
 func getServiceMethod(method string) (string, string, bool) {
 	s := string(method)
 	sep := strings.Index(s, "::")
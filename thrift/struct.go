@@ -29,16 +29,27 @@ import (
 
 // WriteStruct writes the given Thrift struct to a writer. It pools TProtocols.
 func WriteStruct(ctx context.Context, writer io.Writer, s thrift.TStruct) error {
-	wp := getProtocolWriter(writer)
-	err := s.Write(ctx, wp.protocol)
-	thriftProtocolPool.Put(wp)
-	return err
+	return writeStruct(ctx, BinaryProtocol, writer, s)
 }
 
 // ReadStruct reads the given Thrift struct. It pools TProtocols.
 func ReadStruct(ctx context.Context, reader io.Reader, s thrift.TStruct) error {
-	wp := getProtocolReader(reader)
+	return readStruct(ctx, BinaryProtocol, reader, s)
+}
+
+func writeStruct(ctx context.Context, protocol Protocol, writer io.Writer, s thrift.TStruct) error {
+	wp := getWriter(protocol, writer)
+	defer wp.release()
+	if err := s.Write(ctx, wp.protocol); err != nil {
+		return err
+	}
+	// Protocols such as JSON buffer writes, so they must be flushed.
+	return wp.protocol.Flush(ctx)
+}
+
+func readStruct(ctx context.Context, protocol Protocol, reader io.Reader, s thrift.TStruct) error {
+	wp := getReader(protocol, reader)
 	err := s.Read(ctx, wp.protocol)
-	thriftProtocolPool.Put(wp)
+	wp.release()
 	return err
 }
@@ -118,59 +118,115 @@ func (s *tchanMetaServer) Handle(ctx Context, methodName string, protocol athrif
 	}
 }
 
+func (s *tchanMetaServer) ReadArgs(ctx Context, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+	case "health":
+		var req gen.MetaHealthArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	case "thriftIDL":
+		var req gen.MetaThriftIDLArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	case "versionInfo":
+		var req gen.MetaVersionInfoArgs
+		if err := req.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return &req, nil
+
+	default:
+		return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanMetaServer) HandleArgs(ctx Context, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+	case "health":
+		return s.handleHealthArgs(ctx, args.(*gen.MetaHealthArgs))
+	case "thriftIDL":
+		return s.handleThriftIDLArgs(ctx, args.(*gen.MetaThriftIDLArgs))
+	case "versionInfo":
+		return s.handleVersionInfoArgs(ctx, args.(*gen.MetaVersionInfoArgs))
+
+	default:
+		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *tchanMetaServer) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	return false, nil, err
+}
+
 func (s *tchanMetaServer) handleHealth(ctx Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req gen.MetaHealthArgs
-	var res gen.MetaHealthResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleHealthArgs(ctx, &req)
+}
+
+func (s *tchanMetaServer) handleHealthArgs(ctx Context, req *gen.MetaHealthArgs) (bool, athrift.TStruct, error) {
+	var res gen.MetaHealthResult
+
 	r, err :=
 		s.handler.Health(ctx, req.Hr)
 
 	if err != nil {
 		return false, nil, err
 	}
-	res.Success = r
 
-	return err == nil, &res, nil
+	res.Success = r
+	return true, &res, nil
 }
 
 func (s *tchanMetaServer) handleThriftIDL(ctx Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req gen.MetaThriftIDLArgs
-	var res gen.MetaThriftIDLResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleThriftIDLArgs(ctx, &req)
+}
+
+func (s *tchanMetaServer) handleThriftIDLArgs(ctx Context, req *gen.MetaThriftIDLArgs) (bool, athrift.TStruct, error) {
+	var res gen.MetaThriftIDLResult
+
 	r, err :=
 		s.handler.ThriftIDL(ctx)
 
 	if err != nil {
 		return false, nil, err
 	}
-	res.Success = r
 
-	return err == nil, &res, nil
+	res.Success = r
+	return true, &res, nil
 }
 
 func (s *tchanMetaServer) handleVersionInfo(ctx Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req gen.MetaVersionInfoArgs
-	var res gen.MetaVersionInfoResult
-
 	if err := req.Read(ctx, protocol); err != nil {
 		return false, nil, err
 	}
 
+	return s.handleVersionInfoArgs(ctx, &req)
+}
+
+func (s *tchanMetaServer) handleVersionInfoArgs(ctx Context, req *gen.MetaVersionInfoArgs) (bool, athrift.TStruct, error) {
+	var res gen.MetaVersionInfoResult
+
 	r, err :=
 		s.handler.VersionInfo(ctx)
 
 	if err != nil {
 		return false, nil, err
 	}
-	res.Success = r
 
-	return err == nil, &res, nil
+	res.Success = r
+	return true, &res, nil
 }
@@ -0,0 +1,52 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"context"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// tClient implements Apache Thrift's TClient using a TChanClient.
+type tClient struct {
+	client      TChanClient
+	serviceName string
+}
+
+// NewTClient returns an Apache Thrift TClient that makes calls to the given
+// Thrift service using client, so Apache Thrift generated clients can make calls
+// over TChannel. Request headers are taken from the context (e.g. using WithHeaders),
+// and response headers are returned in the ResponseMeta and set on the context.
+func NewTClient(client TChanClient, serviceName string) thrift.TClient {
+	return &tClient{client: client, serviceName: serviceName}
+}
+
+// Call makes a call to method, and reads the response into result. If the
+// response is an application error, result contains the declared exception.
+// result is nil for oneway methods.
+func (c *tClient) Call(ctx context.Context, method string, args, result thrift.TStruct) (thrift.ResponseMeta, error) {
+	tctx := Wrap(ctx)
+	if _, err := c.client.Call(tctx, c.serviceName, method, args, result); err != nil {
+		return thrift.ResponseMeta{}, err
+	}
+	return thrift.ResponseMeta{Headers: tctx.ResponseHeaders()}, nil
+}
@@ -41,80 +41,68 @@ import (
 // These tests ensure that the code generator generates valid code that can be built
 // in combination with Thrift's autogenerated code.
 
-const _tchannelPackage = "github.com/temporalio/tchannel-go"
+const (
+	_tchannelPackage = "github.com/temporalio/tchannel-go"
+	_testModule      = "example.com/thriftgentest"
+)
 
 var (
-	_testGoPath     string
-	_testGoPathOnce sync.Once
+	_testModuleDir     string
+	_testModuleDirOnce sync.Once
 )
 
 func TestMain(m *testing.M) {
 	exitCode := m.Run()
 
-	// If we created a fake GOPATH, we should clean it up on success.
-	if _testGoPath != "" && exitCode == 0 {
-		os.RemoveAll(_testGoPath)
+	// If we created a test module, we should clean it up on success.
+	if _testModuleDir != "" && exitCode == 0 {
+		os.RemoveAll(_testModuleDir)
 	}
 
 	os.Exit(exitCode)
 }
 
-func getTChannelDir(goPath string) string {
-	return filepath.Join(goPath, "src", _tchannelPackage)
-}
-
 func getCurrentTChannelPath(t *testing.T) string {
 	wd, err := os.Getwd()
 	require.NoError(t, err, "Failed to get working directory")
 
-	// Walk up "wd" till we find "tchannel-go".
-	for filepath.Base(wd) != filepath.Base(_tchannelPackage) {
-		wd = filepath.Dir(wd)
-		if wd == "" {
-			t.Fatalf("Failed to find tchannel-go in parents of current directory")
-		}
-	}
-
-	return wd
+	mod, err := findGoModule(wd)
+	require.NoError(t, err, "Failed to find Go module")
+	require.NotNil(t, mod, "Working directory is not in a Go module")
+	require.Equal(t, _tchannelPackage, mod.path, "Unexpected module")
+	return mod.dir
 }
 
-func createGoPath(t *testing.T) {
-	goPath, err := ioutil.TempDir("", "thrift-gen")
+// createModule creates a module outside of GOPATH that uses the current
+// tchannel-go, to ensure generated code builds using Go modules.
+func createModule(t *testing.T) {
+	moduleDir, err := ioutil.TempDir("", "thrift-gen")
 	require.NoError(t, err, "TempDir failed")
 
-	// Create $GOPATH/src/github.com/uber/tchannel-go and symlink everything.
-	// And then create a dummy directory for all the test output.
-	tchannelDir := getTChannelDir(goPath)
-	require.NoError(t, os.MkdirAll(tchannelDir, 0755), "MkDirAll failed")
-
-	// Symlink the contents of tchannel-go into the temp directory.
+	// Use the same requirements as tchannel-go, so the generated code builds
+	// without fetching any other versions of dependencies.
 	realTChannelDir := getCurrentTChannelPath(t)
-	realDirContents, err := ioutil.ReadDir(realTChannelDir)
-	require.NoError(t, err, "Failed to read real tchannel-go dir")
-
-	for _, f := range realDirContents {
-		realPath := filepath.Join(realTChannelDir, f.Name())
-		err := os.Symlink(realPath, filepath.Join(tchannelDir, filepath.Base(f.Name())))
-		require.NoError(t, err, "Failed to symlink %v", f.Name())
-	}
+	goMod, err := ioutil.ReadFile(filepath.Join(realTChannelDir, "go.mod"))
+	require.NoError(t, err, "Failed to read go.mod")
+	goSum, err := ioutil.ReadFile(filepath.Join(realTChannelDir, "go.sum"))
+	require.NoError(t, err, "Failed to read go.sum")
 
-	_testGoPath = goPath
+	testGoMod := strings.Replace(string(goMod), "module "+_tchannelPackage, "module "+_testModule, 1)
+	testGoMod += fmt.Sprintf("\nrequire %v v0.0.0\n\nreplace %v => %v\n", _tchannelPackage, _tchannelPackage, realTChannelDir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte(testGoMod), 0666), "Failed to write go.mod")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(moduleDir, "go.sum"), goSum, 0666), "Failed to write go.sum")
 
-	// None of the other tests in this package should use GOPATH, so we don't
-	// restore this.
-	os.Setenv("GOPATH", goPath)
+	_testModuleDir = moduleDir
 }
 
-func getOutputDir(t *testing.T) (dir, pkg string) {
-	_testGoPathOnce.Do(func() { createGoPath(t) })
+func getOutputDir(t *testing.T) string {
+	_testModuleDirOnce.Do(func() { createModule(t) })
 
-	// Create a random directory inside of the GOPATH in tmp
-	randStr := testutils.RandString(10)
-	randDir := filepath.Join(getTChannelDir(_testGoPath), randStr)
+	// Create a random directory inside of the test module.
+	randDir := filepath.Join(_testModuleDir, testutils.RandString(10))
 	// In case it's not empty.
 	os.RemoveAll(randDir)
-
-	return randDir, filepath.Join(_tchannelPackage, randStr)
+	return randDir
 }
 
 func TestAllThrift(t *testing.T) {
@@ -156,6 +144,33 @@ func TestMultipleFiles(t *testing.T) {
 	}
 }
 
+func TestMappedIncludeThrift(t *testing.T) {
+	// Generate the included file separately, and then map it using ImportPaths.
+	sharedDir := getOutputDir(t)
+	sharedOpts := processOptions{
+		InputFile:      "test_files/include_test/simple/shared2.thrift",
+		GenerateThrift: true,
+		OutputDir:      sharedDir,
+	}
+	require.NoError(t, processFile(sharedOpts), "processFile(%v) failed", sharedOpts.InputFile)
+	defer os.RemoveAll(sharedDir)
+
+	sharedImport := path.Join(_testModule, filepath.Base(sharedDir), "shared2")
+	opts := processOptions{
+		InputFile:   "test_files/include_test/simple/simple.thrift",
+		ImportPaths: []string{"shared2.thrift=" + sharedImport},
+	}
+	checks := func(dir string) error {
+		if _, err := os.Stat(filepath.Join(dir, "shared2")); !os.IsNotExist(err) {
+			return fmt.Errorf("mapped include should not be generated, got err: %v", err)
+		}
+		return checkDirectoryFiles(filepath.Join(dir, "simple"), 4)
+	}
+	if err := runTest(t, opts, checks); err != nil {
+		t.Errorf("Failed to run test: %v", err)
+	}
+}
+
 func TestExternalTemplate(t *testing.T) {
 	template1 := `package {{ .Package }}
 
@@ -305,15 +320,15 @@ func runBuildTest(t *testing.T, thriftFile string) error {
 		return checkDirectoryFiles(filepath.Join(dir, defaultPackageName(thriftFile)), 4)
 	}
 
-	opts := processOptions{InputFile: thriftFile}
+	opts := processOptions{InputFile: thriftFile, GenerateMocks: true}
 	return runTest(t, opts, extraChecks)
 }
 
 func runTest(t *testing.T, opts processOptions, extraChecks func(string) error) error {
-	tempDir, outputPkg := getOutputDir(t)
+	tempDir := getOutputDir(t)
 
-	// Generate code from the Thrift file.
-	*packagePrefix = outputPkg + "/"
+	// Generate code from the Thrift file. The package prefix is computed from
+	// the test module's go.mod.
 	opts.GenerateThrift = true
 	opts.OutputDir = tempDir
 	if err := processFile(opts); err != nil {
@@ -326,7 +341,7 @@ func runTest(t *testing.T, opts processOptions, extraChecks func(string) error)
 	}
 
 	// Run go build to ensure that the generated code builds.
-	cmd := exec.Command("go", "build", "./...")
+	cmd := exec.Command("go", "build", "-mod=mod", "./...")
 	cmd.Dir = tempDir
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("build in %q failed.\nError: %v Output:\n%v", tempDir, err, string(output))
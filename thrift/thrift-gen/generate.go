@@ -23,16 +23,18 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 var (
 	thriftBinary       = flag.String("thriftBinary", "thrift", "Command to use for the Apache Thrift binary")
 	apacheThriftImport = flag.String("thriftImport", "github.com/apache/thrift/lib/go/thrift", "Go package to use for the Thrift import")
-	packagePrefix      = flag.String("packagePrefix", "", "The package prefix (will be used similar to how Apache Thrift uses it)")
+	packagePrefix      = flag.String("packagePrefix", "", "The package prefix (will be used similar to how Apache Thrift uses it). Defaults to the import path of outputDir in its Go module.")
 )
 
 func execCmd(name string, args ...string) error {
@@ -64,7 +66,41 @@ func deleteRemote(dir string) error {
 	})
 }
 
-func runThrift(inFile string, outDir string) error {
+// rewriteImports rewrites the imports of all Go files in dir using the given
+// mapping from old to new import paths.
+func rewriteImports(dir string, rewrites map[string]string) error {
+	if len(rewrites) == 0 {
+		return nil
+	}
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rewritten := string(contents)
+		for oldPath, newPath := range rewrites {
+			rewritten = strings.Replace(rewritten, strconv.Quote(oldPath), strconv.Quote(newPath), -1)
+		}
+		if rewritten == string(contents) {
+			return nil
+		}
+		return ioutil.WriteFile(path, []byte(rewritten), info.Mode())
+	})
+}
+
+// runThrift runs the Apache Thrift compiler for inFile and its includes.
+// Code for included files with an explicit import path (mapped by package
+// name) is generated separately, so it's removed, and imports of it are
+// rewritten to use the explicit import path.
+func runThrift(inFile, outDir, pkgPrefix string, mapped map[string]string) error {
 	inFile, err := filepath.Abs(inFile)
 	if err != nil {
 		return err
@@ -77,7 +113,7 @@ func runThrift(inFile string, outDir string) error {
 	}
 
 	// Generate the Apache Thrift generated code.
-	goArgs := fmt.Sprintf("go:thrift_import=%s,package_prefix=%s", *apacheThriftImport, *packagePrefix)
+	goArgs := fmt.Sprintf("go:thrift_import=%s,package_prefix=%s", *apacheThriftImport, pkgPrefix)
 	if err := execThrift("-r", "--gen", goArgs, "-out", outDir, inFile); err != nil {
 		return fmt.Errorf("thrift compile failed: %v", err)
 	}
@@ -87,5 +123,21 @@ func runThrift(inFile string, outDir string) error {
 		return fmt.Errorf("failed to delete -remote folders: %v", err)
 	}
 
+	rewrites := make(map[string]string, len(mapped))
+	for pkg, importPath := range mapped {
+		if importPath == pkgPrefix+pkg {
+			// The included file is mapped to where it would be generated anyway.
+			continue
+		}
+		mappedDir := filepath.Join(outDir, pkg)
+		if err := os.RemoveAll(mappedDir); err != nil {
+			return fmt.Errorf("failed to delete directory %s: %v", mappedDir, err)
+		}
+		rewrites[pkgPrefix+pkg] = importPath
+	}
+	if err := rewriteImports(outDir, rewrites); err != nil {
+		return fmt.Errorf("failed to rewrite imports: %v", err)
+	}
+
 	return nil
 }
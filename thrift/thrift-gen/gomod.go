@@ -0,0 +1,190 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// goModule is a Go module, used to compute import paths for generated code
+// without relying on GOPATH.
+type goModule struct {
+	// path is the module path declared in go.mod.
+	path string
+
+	// dir is the absolute path of the directory containing go.mod.
+	dir string
+}
+
+// findGoModule returns the module containing dir by looking for a go.mod in
+// dir and its parents. It returns nil if dir is not inside a module.
+func findGoModule(dir string) (*goModule, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		goMod := filepath.Join(dir, "go.mod")
+		contents, err := ioutil.ReadFile(goMod)
+		if err == nil {
+			path := modulePath(contents)
+			if path == "" {
+				return nil, fmt.Errorf("no module path found in %v", goMod)
+			}
+			return &goModule{path: path, dir: dir}, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// modulePath returns the module path from the contents of a go.mod file.
+func modulePath(goMod []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(goMod))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if path, err := strconv.Unquote(fields[1]); err == nil {
+			return path
+		}
+		return fields[1]
+	}
+	return ""
+}
+
+// importPath returns the import path for the package in dir, and false if
+// dir is not inside the module.
+func (m *goModule) importPath(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(m.dir, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return m.path, true
+	}
+	return m.path + "/" + filepath.ToSlash(rel), true
+}
+
+// resolve returns the directory for a path that starts with the module path,
+// such as "github.com/my/module/gen-go", and false for any other path.
+func (m *goModule) resolve(path string) (string, bool) {
+	path = filepath.ToSlash(path)
+	if path == m.path {
+		return m.dir, true
+	}
+	if !strings.HasPrefix(path, m.path+"/") {
+		return "", false
+	}
+	return filepath.Join(m.dir, filepath.FromSlash(strings.TrimPrefix(path, m.path+"/"))), true
+}
+
+// resolveModulePath resolves a module-relative path, which starts with the
+// path of the module containing the current directory, to a directory. Other
+// paths are returned unchanged.
+func resolveModulePath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+
+	mod, err := findGoModule(".")
+	if err != nil || mod == nil {
+		return path, err
+	}
+	if dir, ok := mod.resolve(path); ok {
+		return dir, nil
+	}
+	return path, nil
+}
+
+// modulePackagePrefix returns the package prefix for packages generated in
+// outputDir, based on the module containing outputDir. It returns an empty
+// prefix if outputDir is not inside a module.
+func modulePackagePrefix(outputDir string) (string, error) {
+	mod, err := findGoModule(outputDir)
+	if err != nil || mod == nil {
+		return "", err
+	}
+	path, ok := mod.importPath(outputDir)
+	if !ok {
+		return "", nil
+	}
+	return path + "/", nil
+}
+
+// importPaths maps Thrift files to the Go import paths of their generated code.
+type importPaths map[string]string
+
+// parseImportPaths parses mappings of the form "file.thrift=import/path".
+func parseImportPaths(mappings []string) (importPaths, error) {
+	paths := make(importPaths)
+	for _, mapping := range mappings {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid import path mapping %q, expected file.thrift=import/path", mapping)
+		}
+		paths[filepath.Clean(parts[0])] = parts[1]
+	}
+	return paths, nil
+}
+
+// lookup returns the import path for the given Thrift file, which is either
+// mapped using its absolute path, or using a path relative to any directory.
+func (p importPaths) lookup(file string) (string, bool) {
+	if path, ok := p[file]; ok {
+		return path, true
+	}
+	for mapped, path := range p {
+		if abs, err := filepath.Abs(mapped); err == nil && abs == file {
+			return path, true
+		}
+		if strings.HasSuffix(file, string(filepath.Separator)+mapped) {
+			return path, true
+		}
+	}
+	return "", false
+}
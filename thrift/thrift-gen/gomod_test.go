@@ -0,0 +1,241 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestModule(t *testing.T, goMod string) string {
+	dir, err := ioutil.TempDir("", "thrift-gen-mod")
+	require.NoError(t, err, "TempDir failed")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub", "gen-go"), 0770), "MkdirAll failed")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0666), "WriteFile failed")
+
+	// Resolve any symlinks in the temp directory so paths can be compared.
+	dir, err = filepath.EvalSymlinks(dir)
+	require.NoError(t, err, "EvalSymlinks failed")
+	return dir
+}
+
+func TestModulePath(t *testing.T) {
+	tests := []struct {
+		goMod string
+		want  string
+	}{
+		{"module github.com/my/module\n\ngo 1.17\n", "github.com/my/module"},
+		{"// comment\nmodule \"example.com/quoted\" // trailing\n", "example.com/quoted"},
+		{"go 1.17\n", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, modulePath([]byte(tt.goMod)), "modulePath(%q)", tt.goMod)
+	}
+}
+
+func TestFindGoModule(t *testing.T) {
+	dir := createTestModule(t, "module example.com/mod\n")
+	defer os.RemoveAll(dir)
+
+	mod, err := findGoModule(filepath.Join(dir, "sub", "gen-go"))
+	require.NoError(t, err, "findGoModule failed")
+	require.NotNil(t, mod, "Expected to find module")
+	assert.Equal(t, &goModule{path: "example.com/mod", dir: dir}, mod)
+
+	// Directories that don't exist yet resolve to the closest module.
+	mod, err = findGoModule(filepath.Join(dir, "new", "dir"))
+	require.NoError(t, err, "findGoModule failed")
+	assert.Equal(t, dir, mod.dir, "Unexpected module directory")
+
+	mod, err = findGoModule(filepath.Dir(dir))
+	require.NoError(t, err, "findGoModule failed")
+	if mod != nil {
+		assert.NotEqual(t, dir, mod.dir, "Parent directory should not be in the test module")
+	}
+}
+
+func TestGoModuleImportPath(t *testing.T) {
+	mod := &goModule{path: "example.com/mod", dir: filepath.FromSlash("/src/mod")}
+	tests := []struct {
+		dir    string
+		want   string
+		wantOK bool
+	}{
+		{"/src/mod", "example.com/mod", true},
+		{"/src/mod/gen-go", "example.com/mod/gen-go", true},
+		{"/src/mod/a/b", "example.com/mod/a/b", true},
+		{"/src/other", "", false},
+		{"/src/module", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := mod.importPath(filepath.FromSlash(tt.dir))
+		assert.Equal(t, tt.wantOK, ok, "importPath(%v) ok", tt.dir)
+		assert.Equal(t, tt.want, got, "importPath(%v)", tt.dir)
+	}
+}
+
+func TestGoModuleResolve(t *testing.T) {
+	mod := &goModule{path: "example.com/mod", dir: filepath.FromSlash("/src/mod")}
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"example.com/mod", "/src/mod", true},
+		{"example.com/mod/gen-go", "/src/mod/gen-go", true},
+		{"example.com/module/gen-go", "", false},
+		{"gen-go", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := mod.resolve(tt.path)
+		assert.Equal(t, tt.wantOK, ok, "resolve(%v) ok", tt.path)
+		assert.Equal(t, filepath.FromSlash(tt.want), got, "resolve(%v)", tt.path)
+	}
+}
+
+func TestModulePackagePrefix(t *testing.T) {
+	dir := createTestModule(t, "module example.com/mod\n")
+	defer os.RemoveAll(dir)
+
+	prefix, err := modulePackagePrefix(filepath.Join(dir, "sub", "gen-go"))
+	require.NoError(t, err, "modulePackagePrefix failed")
+	assert.Equal(t, "example.com/mod/sub/gen-go/", prefix)
+
+	// Module-relative paths are resolved relative to the current module.
+	wd, err := os.Getwd()
+	require.NoError(t, err, "Getwd failed")
+	require.NoError(t, os.Chdir(filepath.Join(dir, "sub")), "Chdir failed")
+	defer os.Chdir(wd)
+
+	resolved, err := resolveModulePath("example.com/mod/sub/gen-go")
+	require.NoError(t, err, "resolveModulePath failed")
+	assert.Equal(t, filepath.Join(dir, "sub", "gen-go"), resolved)
+
+	resolved, err = resolveModulePath("gen-go")
+	require.NoError(t, err, "resolveModulePath failed")
+	assert.Equal(t, "gen-go", resolved, "Relative paths should not be changed")
+}
+
+func TestImportPaths(t *testing.T) {
+	_, err := parseImportPaths([]string{"shared.thrift"})
+	assert.Error(t, err, "Expected error for mapping without import path")
+
+	paths, err := parseImportPaths([]string{
+		"shared.thrift=example.com/shared",
+		"idl/common/types.thrift=example.com/types",
+	})
+	require.NoError(t, err, "parseImportPaths failed")
+
+	tests := []struct {
+		file   string
+		want   string
+		wantOK bool
+	}{
+		{"/src/idl/shared.thrift", "example.com/shared", true},
+		{"/src/idl/common/types.thrift", "example.com/types", true},
+		{"/src/idl/other/types.thrift", "", false},
+		{"/src/idl/notshared.thrift", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := paths.lookup(filepath.FromSlash(tt.file))
+		assert.Equal(t, tt.wantOK, ok, "lookup(%v) ok", tt.file)
+		assert.Equal(t, tt.want, got, "lookup(%v)", tt.file)
+	}
+}
+
+func TestIncludeImportPaths(t *testing.T) {
+	allParsed, err := parseFile("test_files/include_test/simple/simple.thrift")
+	require.NoError(t, err, "parseFile failed")
+
+	mapped, err := parseImportPaths([]string{"simple/shared2.thrift=example.com/other/shared2"})
+	require.NoError(t, err, "parseImportPaths failed")
+	setImportPaths(allParsed, "example.com/mod/gen-go/", mapped)
+
+	imports := make(map[string]string)
+	for _, v := range allParsed {
+		for key, include := range v.global.includes {
+			imports[key] = include.Import()
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"shared":  "example.com/mod/gen-go/shared",
+		"shared2": "example.com/other/shared2",
+	}, imports)
+}
+
+func TestMappedPackages(t *testing.T) {
+	inputFile, err := filepath.Abs("test_files/include_test/simple/simple.thrift")
+	require.NoError(t, err, "Abs failed")
+	allParsed, err := parseFile(inputFile)
+	require.NoError(t, err, "parseFile failed")
+
+	mapped, err := parseImportPaths([]string{
+		"simple/shared2.thrift=example.com/other/shared2",
+		"simple/simple.thrift=example.com/other/simple",
+	})
+	require.NoError(t, err, "parseImportPaths failed")
+	assert.Equal(t, map[string]string{
+		"shared2": "example.com/other/shared2",
+	}, mappedPackages(allParsed, inputFile, mapped), "the input file should not be mapped")
+}
+
+func TestRewriteImports(t *testing.T) {
+	dir := createTestModule(t, "module example.com/mod\n")
+	defer os.RemoveAll(dir)
+
+	const original = `package simple
+
+import (
+	"example.com/mod/gen-go/shared"
+	"example.com/mod/gen-go/shared2"
+)
+`
+	goFile := filepath.Join(dir, "sub", "gen-go", "simple.go")
+	otherFile := filepath.Join(dir, "sub", "gen-go", "simple.thrift")
+	require.NoError(t, ioutil.WriteFile(goFile, []byte(original), 0666), "WriteFile failed")
+	require.NoError(t, ioutil.WriteFile(otherFile, []byte(original), 0666), "WriteFile failed")
+
+	require.NoError(t, rewriteImports(dir, map[string]string{
+		"example.com/mod/gen-go/shared2": "example.com/other/shared2",
+	}), "rewriteImports failed")
+
+	contents, err := ioutil.ReadFile(goFile)
+	require.NoError(t, err, "ReadFile failed")
+	assert.Equal(t, `package simple
+
+import (
+	"example.com/mod/gen-go/shared"
+	"example.com/other/shared2"
+)
+`, string(contents))
+
+	contents, err = ioutil.ReadFile(otherFile)
+	require.NoError(t, err, "ReadFile failed")
+	assert.Equal(t, original, string(contents), "only Go files should be rewritten")
+}
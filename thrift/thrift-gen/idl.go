@@ -0,0 +1,99 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IDL is the Thrift IDL embedded in generated code, which is the file the code
+// is generated for, and the files it includes.
+type IDL struct {
+	// EntryPoint is the name of the file the code is generated for.
+	EntryPoint string
+	Files      []*IDLFile
+}
+
+// IDLFile is a single embedded Thrift file.
+type IDLFile struct {
+	// Name is the path of the file relative to the directory of the entry point.
+	Name     string
+	Contents string
+}
+
+// readIDL reads the given file and any files that it includes, directly or indirectly.
+func readIDL(filename string, all map[string]parseState) (*IDL, error) {
+	baseDir := filepath.Dir(filename)
+	idl := &IDL{EntryPoint: filepath.Base(filename)}
+
+	seen := make(map[string]bool)
+	pending := []string{filename}
+	for len(pending) > 0 {
+		file := pending[0]
+		pending = pending[1:]
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read IDL %q: %v", file, err)
+		}
+		name, err := filepath.Rel(baseDir, file)
+		if err != nil {
+			return nil, err
+		}
+		idl.Files = append(idl.Files, &IDLFile{Name: filepath.ToSlash(name), Contents: string(contents)})
+
+		if parsed, ok := all[file]; ok {
+			for _, include := range parsed.ast.Includes {
+				pending = append(pending, include)
+			}
+		}
+	}
+
+	sort.Slice(idl.Files, func(i, j int) bool {
+		return idl.Files[i].Name < idl.Files[j].Name
+	})
+	return idl, nil
+}
+
+// quoteLines returns a Go expression for the string s, with one string literal
+// per line of s, so the embedded file is readable in generated code.
+func quoteLines(s string) string {
+	if s == "" {
+		return `""`
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		lines[i] = strconv.Quote(line)
+	}
+	return strings.Join(lines, " +\n")
+}
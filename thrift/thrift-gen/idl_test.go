@@ -0,0 +1,78 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadIDL(t *testing.T) {
+	allParsed, err := parseFile("test_files/include_test/simple/simple.thrift")
+	require.NoError(t, err, "parseFile failed")
+
+	tests := []struct {
+		file      string
+		wantFiles []string
+	}{
+		{"simple.thrift", []string{"shared.thrift", "shared2.thrift", "simple.thrift"}},
+		{"shared.thrift", []string{"shared.thrift", "shared2.thrift"}},
+		{"shared2.thrift", []string{"shared2.thrift"}},
+	}
+
+	for _, tt := range tests {
+		filename, err := filepath.Abs(filepath.Join("test_files/include_test/simple", tt.file))
+		require.NoError(t, err, "Abs failed")
+
+		idl, err := readIDL(filename, allParsed)
+		require.NoError(t, err, "readIDL(%v) failed", tt.file)
+		assert.Equal(t, tt.file, idl.EntryPoint, "Unexpected entry point")
+
+		var names []string
+		for _, f := range idl.Files {
+			names = append(names, f.Name)
+
+			contents, err := ioutil.ReadFile(filepath.Join(filepath.Dir(filename), f.Name))
+			require.NoError(t, err, "ReadFile failed")
+			assert.Equal(t, string(contents), f.Contents, "Unexpected contents for %v", f.Name)
+		}
+		assert.Equal(t, tt.wantFiles, names, "Unexpected files for %v", tt.file)
+	}
+}
+
+func TestQuoteLines(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", `""`},
+		{"a", `"a"`},
+		{"a\n", `"a\n"`},
+		{"a\n\n\"b\"`", "\"a\\n\" +\n\"\\n\" +\n\"\\\"b\\\"`\""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, quoteLines(tt.s), "quoteLines(%q)", tt.s)
+	}
+}
@@ -24,9 +24,10 @@ import "github.com/samuel/go-thrift/parser"
 
 // Include represents a single include statement in the Thrift file.
 type Include struct {
-	key  string
-	file string
-	pkg  string
+	key        string
+	file       string
+	pkg        string
+	importPath string
 }
 
 // Import returns the go import to use for this package.
@@ -34,7 +35,7 @@ func (i *Include) Import() string {
 	// TODO(prashant): Rename imports so they don't clash with standard imports.
 	// This is not high priority since Apache thrift clashes already with "bytes" and "fmt".
 	// which are the same imports we would clash with.
-	return *packagePrefix + i.Package()
+	return i.importPath
 }
 
 // Package returns the package selector for this package.
@@ -54,3 +55,17 @@ func createIncludes(parsed *parser.Thrift, all map[string]parseState) map[string
 	}
 	return includes
 }
+
+// setImportPaths sets the import path for all includes, either from an explicit
+// mapping for the included file, or using the package prefix.
+func setImportPaths(all map[string]parseState, pkgPrefix string, mapped importPaths) {
+	for _, v := range all {
+		for _, include := range v.global.includes {
+			if path, ok := mapped.lookup(include.file); ok {
+				include.importPath = path
+			} else {
+				include.importPath = pkgPrefix + include.pkg
+			}
+		}
+	}
+}
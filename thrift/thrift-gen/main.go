@@ -40,22 +40,26 @@ import (
 const tchannelThriftImport = "github.com/temporalio/tchannel-go/thrift"
 
 var (
-	generateThrift = flag.Bool("generateThrift", false, "Whether to generate all Thrift go code")
-	inputFile      = flag.String("inputFile", "", "The .thrift file to generate a client for")
-	outputDir      = flag.String("outputDir", "gen-go", "The output directory to generate go code to.")
-	skipTChannel   = flag.Bool("skipTChannel", false, "Whether to skip the TChannel template")
-	templateFiles  = NewStringSliceFlag("template", "Template file to compile code from")
+	generateThrift  = flag.Bool("generateThrift", false, "Whether to generate all Thrift go code")
+	inputFile       = flag.String("inputFile", "", "The .thrift file to generate a client for")
+	outputDir       = flag.String("outputDir", "gen-go", "The output directory to generate go code to. This may be a module-relative path starting with the module path in go.mod.")
+	skipTChannel    = flag.Bool("skipTChannel", false, "Whether to skip the TChannel template")
+	generateMocks   = flag.Bool("generateMocks", false, "Whether to generate mocks and fakes for services in a mocks subpackage")
+	templateFiles   = NewStringSliceFlag("template", "Template file to compile code from")
+	importPathFlags = NewStringSliceFlag("importPath", "Go import path for the code generated from a Thrift file, as file.thrift=import/path")
 
 	nlSpaceNL = regexp.MustCompile(`\n[ \t]+\n`)
 )
 
 // TemplateData is the data passed to the template that generates code.
 type TemplateData struct {
-	Package  string
-	AST      *parser.Thrift
-	Services []*Service
-	Includes map[string]*Include
-	Imports  imports
+	Package    string
+	ImportPath string
+	AST        *parser.Thrift
+	Services   []*Service
+	Includes   map[string]*Include
+	Imports    imports
+	IDL        *IDL
 
 	// global should not be directly exported to the template, but functions on
 	// global can be exposed to templates.
@@ -78,7 +82,10 @@ func main() {
 		GenerateThrift: *generateThrift,
 		OutputDir:      *outputDir,
 		SkipTChannel:   *skipTChannel,
+		GenerateMocks:  *generateMocks,
 		TemplateFiles:  *templateFiles,
+		PackagePrefix:  *packagePrefix,
+		ImportPaths:    *importPathFlags,
 	}
 	if err := processFile(opts); err != nil {
 		log.Fatal(err)
@@ -91,35 +98,81 @@ type processOptions struct {
 	OutputDir      string
 	SkipTChannel   bool
 	TemplateFiles  []string
+
+	// GenerateMocks generates a mocks subpackage for each generated package, with
+	// a mockery-compatible mock and an in-memory fake for each service.
+	GenerateMocks bool
+
+	// PackagePrefix is the prefix for the import paths of generated packages.
+	// If it's empty and OutputDir is inside a Go module, it's computed from go.mod.
+	PackagePrefix string
+
+	// ImportPaths maps Thrift files to the Go import paths of their generated
+	// code, as file.thrift=import/path. Code is not generated for these files,
+	// other than the input file.
+	ImportPaths []string
 }
 
 func processFile(opts processOptions) error {
-	if err := os.MkdirAll(opts.OutputDir, 0770); err != nil {
-		return fmt.Errorf("failed to create output directory %q: %v", opts.OutputDir, err)
+	outputDir, err := resolveModulePath(opts.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output directory %q: %v", opts.OutputDir, err)
+	}
+	if err := os.MkdirAll(outputDir, 0770); err != nil {
+		return fmt.Errorf("failed to create output directory %q: %v", outputDir, err)
 	}
 
-	if opts.GenerateThrift {
-		if err := runThrift(opts.InputFile, opts.OutputDir); err != nil {
-			return fmt.Errorf("failed to run thrift for file %q: %v", opts.InputFile, err)
+	pkgPrefix := opts.PackagePrefix
+	if pkgPrefix == "" {
+		if pkgPrefix, err = modulePackagePrefix(outputDir); err != nil {
+			return fmt.Errorf("failed to get package prefix for %q: %v", outputDir, err)
 		}
 	}
+	mappedPaths, err := parseImportPaths(opts.ImportPaths)
+	if err != nil {
+		return err
+	}
 
 	allParsed, err := parseFile(opts.InputFile)
 	if err != nil {
 		return fmt.Errorf("failed to parse file %q: %v", opts.InputFile, err)
 	}
+	setImportPaths(allParsed, pkgPrefix, mappedPaths)
 
-	allTemplates, err := parseTemplates(opts.SkipTChannel, opts.TemplateFiles)
+	inputFile, err := filepath.Abs(opts.InputFile)
+	if err != nil {
+		return err
+	}
+
+	if opts.GenerateThrift {
+		mapped := mappedPackages(allParsed, inputFile, mappedPaths)
+		if err := runThrift(opts.InputFile, outputDir, pkgPrefix, mapped); err != nil {
+			return fmt.Errorf("failed to run thrift for file %q: %v", opts.InputFile, err)
+		}
+	}
+
+	allTemplates, err := parseTemplates(opts.SkipTChannel, opts.GenerateMocks, opts.TemplateFiles)
 	if err != nil {
 		return fmt.Errorf("failed to parse templates: %v", err)
 	}
 
 	for filename, v := range allParsed {
+		if _, ok := mappedPaths.lookup(filename); ok && filename != inputFile {
+			// Files with an explicit import path are generated separately.
+			continue
+		}
 		pkg := getNamespace(filename, v.ast)
+		importPath, ok := mappedPaths.lookup(filename)
+		if !ok {
+			importPath = pkgPrefix + pkg
+		}
+		if v.idl, err = readIDL(filename, allParsed); err != nil {
+			return err
+		}
 
 		for _, template := range allTemplates {
-			outputFile := filepath.Join(opts.OutputDir, pkg, template.outputFile(pkg))
-			if err := generateCode(outputFile, template, pkg, v); err != nil {
+			outputFile := filepath.Join(outputDir, pkg, template.outputFile(pkg))
+			if err := generateCode(outputFile, template, pkg, importPath, v); err != nil {
 				return err
 			}
 		}
@@ -128,15 +181,33 @@ func processFile(opts processOptions) error {
 	return nil
 }
 
+// mappedPackages returns the import paths of included files with an explicit
+// import path, keyed by the package name of the included file.
+func mappedPackages(allParsed map[string]parseState, inputFile string, mappedPaths importPaths) map[string]string {
+	mapped := make(map[string]string)
+	for filename, v := range allParsed {
+		if filename == inputFile {
+			continue
+		}
+		if path, ok := mappedPaths.lookup(filename); ok {
+			mapped[v.namespace] = path
+		}
+	}
+	return mapped
+}
+
 type parseState struct {
 	ast       *parser.Thrift
 	namespace string
 	global    *State
 	services  []*Service
+
+	// idl is the IDL embedded in the generated code for the file.
+	idl *IDL
 }
 
 // parseTemplates returns a list of Templates that must be rendered given the template files.
-func parseTemplates(skipTChannel bool, templateFiles []string) ([]*Template, error) {
+func parseTemplates(skipTChannel, generateMocks bool, templateFiles []string) ([]*Template, error) {
 	var templates []*Template
 
 	if !skipTChannel {
@@ -146,6 +217,18 @@ func parseTemplates(skipTChannel bool, templateFiles []string) ([]*Template, err
 		})
 	}
 
+	if generateMocks {
+		templates = append(templates, &Template{
+			name:     "mock",
+			dir:      "mocks",
+			template: template.Must(parseTemplate(mockTmpl)),
+		}, &Template{
+			name:     "fake",
+			dir:      "mocks",
+			template: template.Must(parseTemplate(fakeTmpl)),
+		})
+	}
+
 	for _, f := range templateFiles {
 		t, err := parseTemplateFile(f)
 		if err != nil {
@@ -174,7 +257,7 @@ func parseFile(inputFile string) (map[string]parseState, error) {
 		}
 
 		namespace := getNamespace(filename, v)
-		allParsed[filename] = parseState{v, namespace, state, services}
+		allParsed[filename] = parseState{ast: v, namespace: namespace, global: state, services: services}
 	}
 	setIncludes(allParsed)
 	return allParsed, setExtends(allParsed)
@@ -195,7 +278,7 @@ func getNamespace(filename string, v *parser.Thrift) string {
 	return defaultPackageName(filename)
 }
 
-func generateCode(outputFile string, template *Template, pkg string, state parseState) error {
+func generateCode(outputFile string, template *Template, pkg, importPath string, state parseState) error {
 	if outputFile == "" {
 		return fmt.Errorf("must speciy an output file")
 	}
@@ -204,11 +287,13 @@ func generateCode(outputFile string, template *Template, pkg string, state parse
 	}
 
 	td := TemplateData{
-		Package:  pkg,
-		AST:      state.ast,
-		Includes: state.global.includes,
-		Services: state.services,
-		global:   state.global,
+		Package:    pkg,
+		ImportPath: importPath,
+		AST:        state.ast,
+		Includes:   state.global.includes,
+		Services:   state.services,
+		IDL:        state.idl,
+		global:     state.global,
 		Imports: imports{
 			Thrift:   *apacheThriftImport,
 			TChannel: tchannelThriftImport,
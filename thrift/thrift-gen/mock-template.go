@@ -0,0 +1,163 @@
+package main
+
+var mockTmpl = `
+// @generated Code generated by thrift-gen. Do not modify.
+
+// Package mocks contains mocks and fakes for the services in package {{ .Package }}.
+package mocks
+
+import (
+"github.com/stretchr/testify/mock"
+"{{ .Imports.TChannel }}"
+"{{ .ImportPath }}"
+
+{{ range .MockImports }}
+	"{{ .Import }}"
+{{ end }}
+)
+
+{{ range .MockImports }}
+	var _ = {{ .Package }}.GoUnusedProtection__
+{{ end }}
+
+{{ $pkg := .Package }}
+{{ range $svc := .MockServices }}
+// {{ .MockStruct }} is a mock for {{ $pkg }}.{{ .Interface }} that can be used with testify's mock package.
+type {{ .MockStruct }} struct {
+	mock.Mock
+}
+
+var _ {{ $pkg }}.{{ .Interface }} = (*{{ .MockStruct }})(nil)
+
+{{ range .AllMethods }}
+// {{ .Name }} provides a mock function for {{ $svc.ThriftName }}::{{ .ThriftName }}.
+func (_m *{{ $svc.MockStruct }}) {{ .Name }}({{ .ArgList true }}) {{ .RetType }} {
+	ret := _m.Called({{ .CallList true }})
+
+	{{ if .HasReturn }}
+	var r0 {{ .ReturnType }}
+	if rf, ok := ret.Get(0).({{ .FuncType .ReturnType }}); ok {
+		r0 = rf({{ .CallList true }})
+	} else {
+		{{ if .ReturnNillable }}
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).({{ .ReturnType }})
+		}
+		{{ else }}
+		r0 = ret.Get(0).({{ .ReturnType }})
+		{{ end }}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).({{ .FuncType "error" }}); ok {
+		r1 = rf({{ .CallList true }})
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+	{{ else }}
+	var r0 error
+	if rf, ok := ret.Get(0).({{ .FuncType "error" }}); ok {
+		r0 = rf({{ .CallList true }})
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+	{{ end }}
+}
+{{ end }}
+{{ end }}
+`
+
+var fakeTmpl = `
+// @generated Code generated by thrift-gen. Do not modify.
+
+package mocks
+
+import (
+"sync"
+
+"github.com/temporalio/tchannel-go"
+"{{ .Imports.TChannel }}"
+"{{ .ImportPath }}"
+
+{{ range .MockImports }}
+	"{{ .Import }}"
+{{ end }}
+)
+
+{{ range .MockImports }}
+	var _ = {{ .Package }}.GoUnusedProtection__
+{{ end }}
+
+{{ $pkg := .Package }}
+{{ range $svc := .MockServices }}
+// {{ .FakeStruct }} is an in-memory implementation of {{ $pkg }}.{{ .Interface }} for tests.
+// Calls are handled by the stub function for the method if it's set, and return
+// zero values otherwise. All calls are recorded.
+type {{ .FakeStruct }} struct {
+	{{ range .AllMethods }}
+		{{ .StubField }} {{ .FuncType .RetType }}
+	{{ end }}
+
+	mu sync.Mutex
+	{{ range .AllMethods }}
+		{{ .CallsField }} []{{ .CallStruct }}
+	{{ end }}
+}
+
+var _ {{ $pkg }}.{{ .Interface }} = (*{{ .FakeStruct }})(nil)
+
+// {{ .FakeConstructor }} creates a fake with no stub functions set.
+func {{ .FakeConstructor }}() *{{ .FakeStruct }} {
+	return &{{ .FakeStruct }}{}
+}
+
+// Register registers the fake as the handler for {{ .ThriftName }} on the given
+// registrar, such as the server channel of a testutils.TestServer.
+func (f *{{ .FakeStruct }}) Register(registrar tchannel.Registrar) *thrift.Server {
+	server := thrift.NewServer(registrar)
+	server.Register({{ $pkg }}.{{ .ServerConstructor }}(f))
+	return server
+}
+
+{{ range .AllMethods }}
+// {{ .CallStruct }} records the arguments of a call to {{ .Name }}.
+type {{ .CallStruct }} struct {
+	{{ range .Args }}
+		{{ .FieldName }} {{ .Type }}
+	{{ end }}
+}
+
+// {{ .Name }} records the call and calls {{ .StubField }} if it's set.
+func (f *{{ $svc.FakeStruct }}) {{ .Name }}({{ .ArgList false }}) {{ .RetType }} {
+	f.mu.Lock()
+	f.{{ .CallsField }} = append(f.{{ .CallsField }}, {{ .CallStruct }}{
+		{{ range .Args }}
+			{{ .FieldName }}: {{ .Name }},
+		{{ end }}
+	})
+	f.mu.Unlock()
+
+	if f.{{ .StubField }} != nil {
+		return f.{{ .StubField }}({{ .CallList false }})
+	}
+	{{ if .HasReturn }}
+	var r0 {{ .ReturnType }}
+	return r0, nil
+	{{ else }}
+	return nil
+	{{ end }}
+}
+
+// {{ .CallsMethod }} returns the recorded calls to {{ .Name }}.
+func (f *{{ $svc.FakeStruct }}) {{ .CallsMethod }}() []{{ .CallStruct }} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]{{ .CallStruct }}(nil), f.{{ .CallsField }}...)
+}
+{{ end }}
+{{ end }}
+`
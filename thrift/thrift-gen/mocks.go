@@ -0,0 +1,231 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samuel/go-thrift/parser"
+)
+
+// MockService is a wrapper for a service used by the mock and fake templates,
+// which are generated in a separate package to the service.
+type MockService struct {
+	*Service
+
+	// AllMethods contains methods on this service, including inherited methods.
+	AllMethods []*MockMethod
+}
+
+// MockStruct returns the name of the mockery-compatible mock for the service.
+func (s *MockService) MockStruct() string {
+	return s.Interface()
+}
+
+// FakeStruct returns the name of the fake for the service.
+func (s *MockService) FakeStruct() string {
+	return "Fake" + s.Interface()
+}
+
+// FakeConstructor returns the name of the constructor used to create a fake.
+func (s *MockService) FakeConstructor() string {
+	return "New" + s.FakeStruct()
+}
+
+// MockMethod is a wrapper for a method used by the mock and fake templates.
+// All types are qualified with the package they are defined in.
+type MockMethod struct {
+	*Method
+
+	service   *MockService
+	qualifier string
+}
+
+// MockArg is a single argument for a MockMethod.
+type MockArg struct {
+	name   string
+	goType string
+}
+
+// Name returns the argument name.
+func (a *MockArg) Name() string {
+	return a.name
+}
+
+// MockName returns the argument name used in generated mocks.
+func (a *MockArg) MockName() string {
+	return "_" + a.name
+}
+
+// FieldName returns the name of the field that records this argument in fakes.
+func (a *MockArg) FieldName() string {
+	return goPublicName(a.name)
+}
+
+// Type returns the Go type of the argument.
+func (a *MockArg) Type() string {
+	return a.goType
+}
+
+// Args returns the arguments to this method, including the context.
+func (m *MockMethod) Args() []*MockArg {
+	args := []*MockArg{{"ctx", contextType()}}
+	for _, f := range m.Method.Arguments() {
+		args = append(args, &MockArg{f.Name(), m.goType(f.Type)})
+	}
+	return args
+}
+
+// ArgList returns the argument list for the method using the given name for each argument.
+func (m *MockMethod) ArgList(mock bool) string {
+	var args []string
+	for _, arg := range m.Args() {
+		name := arg.Name()
+		if mock {
+			name = arg.MockName()
+		}
+		args = append(args, name+" "+arg.Type())
+	}
+	return strings.Join(args, ", ")
+}
+
+// CallList returns the list of arguments to pass through to another function.
+func (m *MockMethod) CallList(mock bool) string {
+	var args []string
+	for _, arg := range m.Args() {
+		name := arg.Name()
+		if mock {
+			name = arg.MockName()
+		}
+		args = append(args, name)
+	}
+	return strings.Join(args, ", ")
+}
+
+// ReturnType returns the Go type of the method's result, which is empty for void methods.
+func (m *MockMethod) ReturnType() string {
+	if !m.HasReturn() {
+		return ""
+	}
+	return m.goType(m.Method.Method.ReturnType)
+}
+
+// ReturnNillable returns whether the method's result may be nil.
+func (m *MockMethod) ReturnNillable() bool {
+	if !m.HasReturn() {
+		return false
+	}
+	if m.state.isResultPointer(m.Method.Method.ReturnType) {
+		return true
+	}
+	retType := m.ReturnType()
+	return strings.HasPrefix(retType, "[]") || strings.HasPrefix(retType, "map[")
+}
+
+// RetType returns the go return type of the method.
+func (m *MockMethod) RetType() string {
+	if !m.HasReturn() {
+		return "error"
+	}
+	return fmt.Sprintf("(%v, %v)", m.ReturnType(), "error")
+}
+
+// FuncType returns the Go function type for the method, with the given results.
+func (m *MockMethod) FuncType(results string) string {
+	var args []string
+	for _, arg := range m.Args() {
+		args = append(args, arg.Type())
+	}
+	return fmt.Sprintf("func(%v) %v", strings.Join(args, ", "), results)
+}
+
+// StubField returns the name of the fake's field for the method's stub function.
+func (m *MockMethod) StubField() string {
+	return m.Name() + "Func"
+}
+
+// CallsField returns the name of the fake's field that records calls to the method.
+func (m *MockMethod) CallsField() string {
+	return "calls" + m.Name()
+}
+
+// CallsMethod returns the name of the fake's method that returns recorded calls.
+func (m *MockMethod) CallsMethod() string {
+	return m.Name() + "Calls"
+}
+
+// CallStruct returns the name of the struct that records a call to the method.
+func (m *MockMethod) CallStruct() string {
+	return m.service.FakeStruct() + m.Name() + "Call"
+}
+
+func (m *MockMethod) goType(thriftType *parser.Type) string {
+	return m.state.goTypePrefix(m.qualifier, thriftType)
+}
+
+// MockServices returns the services wrapped for the mock and fake templates.
+func (td TemplateData) MockServices() []*MockService {
+	var services []*MockService
+	for _, s := range td.Services {
+		ms := &MockService{Service: s}
+
+		// Types for inherited methods are qualified with the package
+		// of the service that defines the method.
+		qualifier := td.Package + "."
+		for svc := s; svc != nil; svc = svc.ExtendsService {
+			for _, m := range svc.Methods() {
+				ms.AllMethods = append(ms.AllMethods, &MockMethod{m, ms, qualifier})
+			}
+			if svc.ExtendsServicePrefix() != "" {
+				qualifier = svc.ExtendsPrefix
+			}
+		}
+		sort.Slice(ms.AllMethods, func(i, j int) bool {
+			return ms.AllMethods[i].Method.Method.Name < ms.AllMethods[j].Method.Method.Name
+		})
+		services = append(services, ms)
+	}
+	return services
+}
+
+// MockImports returns the includes that must be imported by the mock and fake
+// templates, including includes of any inherited services.
+func (td TemplateData) MockImports() []*Include {
+	byImport := make(map[string]*Include)
+	for _, s := range td.Services {
+		for svc := s; svc != nil; svc = svc.ExtendsService {
+			for _, include := range svc.state.includes {
+				byImport[include.Import()] = include
+			}
+		}
+	}
+
+	var includes []*Include
+	for _, include := range byImport {
+		includes = append(includes, include)
+	}
+	sort.Slice(includes, func(i, j int) bool {
+		return includes[i].Import() < includes[j].Import()
+	})
+	return includes
+}
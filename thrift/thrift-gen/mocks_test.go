@@ -0,0 +1,90 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockServices(t *testing.T) {
+	thriftFile := "test_files/include_test/svc_extend/svc_extend.thrift"
+	allParsed, err := parseFile(thriftFile)
+	require.NoError(t, err, "parseFile failed")
+	setImportPaths(allParsed, "example.com/gen/", nil)
+
+	absFile, err := filepath.Abs(thriftFile)
+	require.NoError(t, err, "Abs failed")
+	state := allParsed[absFile]
+	td := TemplateData{
+		Package:  "svc_extend",
+		Services: state.services,
+		global:   state.global,
+	}
+
+	services := td.MockServices()
+	require.Len(t, services, 1, "Unexpected services")
+	assert.Equal(t, "TChanFoo", services[0].MockStruct(), "Unexpected mock name")
+	assert.Equal(t, "FakeTChanFoo", services[0].FakeStruct(), "Unexpected fake name")
+
+	var signatures []string
+	for _, m := range services[0].AllMethods {
+		signatures = append(signatures, m.Name()+"("+m.ArgList(false)+") "+m.RetType())
+	}
+	assert.Equal(t, []string{
+		"GetMyUUID(ctx thrift.Context, uuid shared.UUID, health *shared.Health) (shared.UUID, error)",
+		"GetUUID(ctx thrift.Context) (shared.UUID, error)",
+		"Health(ctx thrift.Context, uuid shared.UUID, health *shared.Health) (*shared.Health, error)",
+	}, signatures, "Unexpected method signatures")
+
+	imports := td.MockImports()
+	require.Len(t, imports, 1, "Unexpected imports")
+	assert.Equal(t, "example.com/gen/shared", imports[0].Import(), "Unexpected import")
+}
+
+func TestMockMethodTypes(t *testing.T) {
+	allParsed, err := parseFile("test_files/typedefs.thrift")
+	require.NoError(t, err, "parseFile failed")
+	require.Len(t, allParsed, 1, "Unexpected parsed files")
+
+	var td TemplateData
+	for _, v := range allParsed {
+		td = TemplateData{Package: "typedefs", Services: v.services, global: v.global}
+	}
+
+	type returnType struct {
+		goType   string
+		nillable bool
+	}
+	got := make(map[string]returnType)
+	for _, m := range td.MockServices()[0].AllMethods {
+		got[m.Name()] = returnType{m.ReturnType(), m.ReturnNillable()}
+	}
+	assert.Equal(t, map[string]returnType{
+		"M1": {"typedefs.Y", false},
+		"M2": {"typedefs.X", false},
+		"M3": {"typedefs.Z", false},
+		"M4": {"*typedefs.S", true},
+	}, got, "Unexpected return types")
+}
@@ -22,6 +22,16 @@ athrift "{{ .Imports.Thrift }}"
 	var _ = {{ .Package }}.GoUnusedProtection__
 {{ end }}
 
+// thriftIDL is the Thrift IDL that this package was generated from.
+var thriftIDL = thrift.IDL{
+	EntryPoint: {{ printf "%q" .IDL.EntryPoint }},
+	Files: map[string]string{
+		{{ range .IDL.Files }}
+			{{ printf "%q" .Name }}: {{ quoteLines .Contents }},
+		{{ end }}
+	},
+}
+
 
 // Interfaces for the service and client for the services defined in the IDL.
 
@@ -69,6 +79,15 @@ func {{ .ClientConstructor }}(client thrift.TChanClient) {{ .Interface }} {
 
 {{ range .Methods }}
 	func (c *{{ $svc.ClientStruct }}) {{ .Name }}({{ .ArgList }}) {{ .RetType }} {
+		{{ if .Oneway }}
+		args := {{ .ArgsType }}{
+			{{ range .Arguments }}
+				{{ .ArgStructName }}: {{ .Name }},
+			{{ end }}
+		}
+		_, err := c.client.Call(ctx, c.thriftService, "{{ .ThriftName }}", &args, nil)
+		return err
+		{{ else }}
 		var resp {{ .ResultType }}
 		args := {{ .ArgsType }}{
 			{{ range .Arguments }}
@@ -91,7 +110,7 @@ func {{ .ClientConstructor }}(client thrift.TChanClient) {{ .Interface }} {
 			return resp.GetSuccess(), err
 		{{ else }}
 			return err
-		{{ end }}
+		{{ end }}{{ end }}
 	}
 {{ end }}
 
@@ -118,6 +137,11 @@ func (s *{{ .ServerStruct }}) Service() string {
 	return "{{ .ThriftName }}"
 }
 
+// ThriftIDL returns the Thrift IDL that the service was generated from.
+func (s *{{ .ServerStruct }}) ThriftIDL() thrift.IDL {
+	return thriftIDL
+}
+
 func (s *{{ .ServerStruct }}) Methods() []string {
 	return []string{
 		{{ range .Methods }}
@@ -129,6 +153,16 @@ func (s *{{ .ServerStruct }}) Methods() []string {
 	}
 }
 
+{{ if .OnewayMethods }}
+func (s *{{ .ServerStruct }}) OnewayMethods() []string {
+	return []string{
+		{{ range .OnewayMethods }}
+			"{{ . }}",
+		{{ end }}
+	}
+}
+{{ end }}
+
 func (s *{{ .ServerStruct }}) Handle(ctx {{ contextType }}, methodName string, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	switch methodName {
 		{{ range .Methods }}
@@ -144,15 +178,85 @@ func (s *{{ .ServerStruct }}) Handle(ctx {{ contextType }}, methodName string, p
 	}
 }
 
+func (s *{{ .ServerStruct }}) ReadArgs(ctx {{ contextType }}, methodName string, protocol athrift.TProtocol) (athrift.TStruct, error) {
+	switch methodName {
+		{{ range .Methods }}
+			case "{{ .ThriftName }}":
+				var req {{ .ArgsType }}
+				if err := req.Read(ctx, protocol); err != nil {
+					return nil, err
+				}
+				return &req, nil
+		{{ end }}
+		{{ range .InheritedMethods }}
+			case "{{ . }}":
+				if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+					return argsServer.ReadArgs(ctx, methodName, protocol)
+				}
+				return nil, fmt.Errorf("inherited method %v of service %v is not implemented by a TChanArgsServer", methodName, s.Service())
+		{{ end }}
+		default:
+			return nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *{{ .ServerStruct }}) HandleArgs(ctx {{ contextType }}, methodName string, args athrift.TStruct) (bool, athrift.TStruct, error) {
+	switch methodName {
+		{{ range .Methods }}
+			case "{{ .ThriftName }}":
+				return s.{{ .HandleArgsFunc }}(ctx, args.(*{{ .ArgsType }}))
+		{{ end }}
+		{{ range .InheritedMethods }}
+			case "{{ . }}":
+				if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+					return argsServer.HandleArgs(ctx, methodName, args)
+				}
+				return false, nil, fmt.Errorf("inherited method %v of service %v is not implemented by a TChanArgsServer", methodName, s.Service())
+		{{ end }}
+		default:
+			return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
+	}
+}
+
+func (s *{{ .ServerStruct }}) HandleError(methodName string, err error) (bool, athrift.TStruct, error) {
+	switch methodName {
+		{{ range .Methods }}
+		{{ if .HasExceptions }}
+			case "{{ .ThriftName }}":
+				var res {{ .ResultType }}
+				return s.{{ .HandleErrorFunc }}(&res, err)
+		{{ end }}
+		{{ end }}
+		{{ range .InheritedMethods }}
+			case "{{ . }}":
+				if argsServer, ok := s.TChanServer.(thrift.TChanArgsServer); ok {
+					return argsServer.HandleError(methodName, err)
+				}
+				return false, nil, err
+		{{ end }}
+		default:
+			return false, nil, err
+	}
+}
+
 {{ range .Methods }}
 	func (s *{{ $svc.ServerStruct }}) {{ .HandleFunc }}(ctx {{ contextType }}, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 		var req {{ .ArgsType }}
-		var res {{ .ResultType }}
-
 		if err := req.Read(ctx, protocol); err != nil {
 			return false, nil, err
 		}
 
+		return s.{{ .HandleArgsFunc }}(ctx, &req)
+	}
+
+	func (s *{{ $svc.ServerStruct }}) {{ .HandleArgsFunc }}(ctx {{ contextType }}, req *{{ .ArgsType }}) (bool, athrift.TStruct, error) {
+		{{ if .Oneway }}
+		err := s.handler.{{ .Name }}({{ .CallList "req" }})
+		return err == nil, nil, err
+	}
+		{{ else }}
+		var res {{ .ResultType }}
+
 		{{ if .HasReturn }}
 			r, err :=
 		{{ else }}
@@ -162,28 +266,35 @@ func (s *{{ .ServerStruct }}) Handle(ctx {{ contextType }}, methodName string, p
 
 		if err != nil {
 			{{ if .HasExceptions }}
-			switch v := err.(type) {
-				{{ range .Exceptions }}
-					case {{ .ArgType }}:
-						if v == nil {
-							return false, nil, fmt.Errorf("Handler for {{ .Name }} returned non-nil error type {{ .ArgType }} but nil value")
-						}
-						res.{{ .ArgStructName }} = v
-				{{ end }}
-					default:
-						return false, nil, err
-			}
+				return s.{{ .HandleErrorFunc }}(&res, err)
 			{{ else }}
 				return false, nil, err
 			{{ end }}
-		} else {
-    {{ if .HasReturn }}
-		  res.Success = {{ .WrapResult "r" }}
+		}
+
+		{{ if .HasReturn }}
+			res.Success = {{ .WrapResult "r" }}
 		{{ end }}
-    }
+		return true, &res, nil
+	}
 
-		return err == nil, &res, nil
+	{{ if .HasExceptions }}
+	func (s *{{ $svc.ServerStruct }}) {{ .HandleErrorFunc }}(res *{{ .ResultType }}, err error) (bool, athrift.TStruct, error) {
+		switch v := err.(type) {
+			{{ range .Exceptions }}
+				case {{ .ArgType }}:
+					if v == nil {
+						return false, nil, fmt.Errorf("Handler for {{ .Name }} returned non-nil error type {{ .ArgType }} but nil value")
+					}
+					res.{{ .ArgStructName }} = v
+			{{ end }}
+				default:
+					return false, nil, err
+		}
+		return false, res, nil
 	}
+	{{ end }}
+		{{ end }}
 
 {{ end }}
 
@@ -28,13 +28,17 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"text/template"
 )
 
 // Template represents a single thrift-gen template that will be used to generate code.
 type Template struct {
-	name     string
+	name string
+	// dir is the subdirectory of the package directory to generate code in.
+	dir      string
 	template *template.Template
 }
 
@@ -51,12 +55,17 @@ func parseTemplate(contents string) (*template.Template, error) {
 		"goPrivateName": goName,
 		"goPublicName":  goPublicName,
 		"goType":        dummyGoType,
+		"quoteLines":    quoteLines,
 	}
 	return template.New("thrift-gen").Funcs(funcs).Parse(contents)
 }
 
 func parseTemplateFile(file string) (*Template, error) {
-	file, err := ResolveWithGoPath(file)
+	file, err := resolveModulePath(file)
+	if err != nil {
+		return nil, err
+	}
+	file, err = ResolveWithGoPath(file)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +80,7 @@ func parseTemplateFile(file string) (*Template, error) {
 		return nil, fmt.Errorf("failed to parse template in file %q: %v", file, err)
 	}
 
-	return &Template{defaultPackageName(file), t}, nil
+	return &Template{name: defaultPackageName(file), template: t}, nil
 }
 
 func contextType() string {
@@ -97,6 +106,9 @@ func (t *Template) execute(outputFile string, td TemplateData) error {
 	}
 
 	generated := cleanGeneratedCode(buf.Bytes())
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0770); err != nil {
+		return fmt.Errorf("failed to create output directory for %q: %v", outputFile, err)
+	}
 	if err := ioutil.WriteFile(outputFile, generated, 0660); err != nil {
 		return fmt.Errorf("cannot write output file %q: %v", outputFile, err)
 	}
@@ -107,5 +119,5 @@ func (t *Template) execute(outputFile string, td TemplateData) error {
 }
 
 func (t *Template) outputFile(pkg string) string {
-	return fmt.Sprintf("%v-%v.go", t.name, pkg)
+	return filepath.Join(t.dir, fmt.Sprintf("%v-%v.go", t.name, pkg))
 }
@@ -0,0 +1,13 @@
+struct Event {
+  1: string name
+}
+
+service Base {
+  oneway void notify(1: Event event)
+}
+
+service Events extends Base {
+  oneway void publish(1: string topic, 2: Event event)
+  oneway void ping()
+  string get(1: string topic)
+}
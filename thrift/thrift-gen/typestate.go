@@ -110,11 +110,11 @@ func (s *State) goTypePrefix(prefix string, thriftType *parser.Type) string {
 	case "binary":
 		return "[]byte"
 	case "list":
-		return "[]" + s.goType(thriftType.ValueType)
+		return "[]" + s.goTypePrefix(prefix, thriftType.ValueType)
 	case "set":
-		return "[]" + s.goType(thriftType.ValueType)
+		return "[]" + s.goTypePrefix(prefix, thriftType.ValueType)
 	case "map":
-		return "map[" + s.goType(thriftType.KeyType) + "]" + s.goType(thriftType.ValueType)
+		return "map[" + s.goTypePrefix(prefix, thriftType.KeyType) + "]" + s.goTypePrefix(prefix, thriftType.ValueType)
 	}
 
 	// If the type is imported, then ignore the package.
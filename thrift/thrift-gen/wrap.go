@@ -132,6 +132,21 @@ func (s *Service) InheritedMethods() []string {
 	return s.inheritedMethods
 }
 
+// OnewayMethods returns names for oneway methods on this service, including
+// inherited methods.
+func (s *Service) OnewayMethods() []string {
+	var oneway []string
+	for svc := s; svc != nil; svc = svc.ExtendsService {
+		for name, m := range svc.Service.Methods {
+			if m.Oneway {
+				oneway = append(oneway, name)
+			}
+		}
+	}
+	sort.Strings(oneway)
+	return oneway
+}
+
 // Method is a wrapper for parser.Method.
 type Method struct {
 	*parser.Method
@@ -173,6 +188,16 @@ func (m *Method) Exceptions() []*Field {
 	return args
 }
 
+// HandleArgsFunc is the go method name for the handle function which calls the handler with decoded arguments.
+func (m *Method) HandleArgsFunc() string {
+	return "handle" + goPublicName(m.Method.Name) + "Args"
+}
+
+// HandleErrorFunc is the go method name for the function which returns the result for an exception.
+func (m *Method) HandleErrorFunc() string {
+	return "handle" + goPublicName(m.Method.Name) + "Error"
+}
+
 // HasReturn returns false if this method is declared as void in the Thrift file.
 func (m *Method) HasReturn() bool {
 	return m.Method.ReturnType != nil
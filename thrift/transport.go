@@ -113,27 +113,56 @@ var _ thrift.TRichTransport = &readWriterTransport{}
 
 type thriftProtocol struct {
 	transport *readWriterTransport
-	protocol  *thrift.TBinaryProtocol
+	protocol  thrift.TProtocol
+	pooled    bool
 }
 
 var thriftProtocolPool = sync.Pool{
 	New: func() interface{} {
 		transport := &readWriterTransport{}
 		protocol := thrift.NewTBinaryProtocolTransport(transport)
-		return &thriftProtocol{transport, protocol}
+		return &thriftProtocol{transport, protocol, true}
 	},
 }
 
 func getProtocolWriter(writer io.Writer) *thriftProtocol {
-	wp := thriftProtocolPool.Get().(*thriftProtocol)
-	wp.transport.Reader = nil
-	wp.transport.Writer = writer
-	return wp
+	return getWriter(BinaryProtocol, writer)
 }
 
 func getProtocolReader(reader io.Reader) *thriftProtocol {
-	wp := thriftProtocolPool.Get().(*thriftProtocol)
-	wp.transport.Reader = reader
-	wp.transport.Writer = nil
-	return wp
+	return getReader(BinaryProtocol, reader)
+}
+
+// newProtocol returns a TProtocol for p. Only binary protocols are pooled, as
+// the other protocols keep state, such as buffers, between calls.
+func newProtocol(p Protocol, reader io.Reader, writer io.Writer) *thriftProtocol {
+	var tp *thriftProtocol
+	switch p {
+	case CompactProtocol:
+		transport := &readWriterTransport{}
+		tp = &thriftProtocol{transport: transport, protocol: thrift.NewTCompactProtocol(transport)}
+	case JSONProtocol:
+		transport := &readWriterTransport{}
+		tp = &thriftProtocol{transport: transport, protocol: thrift.NewTJSONProtocol(transport)}
+	default:
+		tp = thriftProtocolPool.Get().(*thriftProtocol)
+	}
+	tp.transport.Reader = reader
+	tp.transport.Writer = writer
+	return tp
+}
+
+func getReader(p Protocol, reader io.Reader) *thriftProtocol {
+	return newProtocol(p, reader, nil)
+}
+
+func getWriter(p Protocol, writer io.Writer) *thriftProtocol {
+	return newProtocol(p, nil, writer)
+}
+
+// release returns pooled protocols to the pool.
+func (tp *thriftProtocol) release() {
+	if tp.pooled {
+		thriftProtocolPool.Put(tp)
+	}
 }
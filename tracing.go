@@ -29,6 +29,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
 )
 
@@ -117,7 +118,8 @@ func (s *injectableSpan) initFromOpenTracing(span opentracing.Span) error {
 
 // CurrentSpan extracts OpenTracing Span from the Context, and if found tries to
 // extract zipkin-style trace/span IDs from it using ZipkinSpanFormat carrier.
-// If there is no OpenTracing Span in the Context, an empty span is returned.
+// If there is no OpenTracing Span in the Context, the IDs are taken from the
+// OpenTelemetry span in the Context. If there is neither, an empty span is returned.
 func CurrentSpan(ctx context.Context) *Span {
 	if sp := opentracing.SpanFromContext(ctx); sp != nil {
 		var injectable injectableSpan
@@ -126,6 +128,11 @@ func CurrentSpan(ctx context.Context) *Span {
 			return &span
 		}
 		// return empty span on error, instead of possibly a partially filled one
+		return &emptySpan
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		span := spanFromOtel(sc, trace.SpanContext{})
+		return &span
 	}
 	return &emptySpan
 }
@@ -169,17 +176,22 @@ func (c *Connection) startOutboundSpan(ctx context.Context, serviceName, methodN
 //
 // Sometimes caller pass a shared instance of the `headers` map, so instead of modifying
 // it we clone it into the new map (assuming that Tracer actually injects some tracing keys).
+//
+// If OpenTelemetry tracing is enabled, the trace context is injected using the
+// channel's propagator instead.
 func InjectOutboundSpan(response *OutboundCallResponse, headers map[string]string) map[string]string {
-	span := response.span
-	if span == nil {
-		return headers
-	}
 	newHeaders := make(map[string]string)
 	carrier := tracingHeadersCarrier(newHeaders)
-	if err := span.Tracer().Inject(span.Context(), opentracing.TextMap, carrier); err != nil {
-		// Something had to go seriously wrong for Inject to fail, usually a setup problem.
-		// A good Tracer implementation may also emit a metric.
-		response.log.WithFields(ErrField(err)).Error("Failed to inject tracing span.")
+	if otelCtx := response.otelCtx; otelCtx != nil {
+		response.call.conn.otel.propagator.Inject(otelCtx, carrier)
+	} else if span := response.span; span != nil {
+		if err := span.Tracer().Inject(span.Context(), opentracing.TextMap, carrier); err != nil {
+			// Something had to go seriously wrong for Inject to fail, usually a setup problem.
+			// A good Tracer implementation may also emit a metric.
+			response.log.WithFields(ErrField(err)).Error("Failed to inject tracing span.")
+		}
+	} else {
+		return headers
 	}
 	if len(newHeaders) == 0 {
 		return headers // Tracer did not add any tracing headers, so return the original map
@@ -200,6 +212,11 @@ func InjectOutboundSpan(response *OutboundCallResponse, headers map[string]strin
 // will be made from the higher level function ExtractInboundSpan() once the
 // application headers are read from the wire.
 func (c *Connection) extractInboundSpan(callReq *callReq) opentracing.Span {
+	if c.otel != nil {
+		// OpenTelemetry spans are started once the method is known.
+		return nil
+	}
+
 	spanCtx, err := c.Tracer().Extract(zipkinSpanFormat, &callReq.Tracing)
 	if err != nil {
 		if err != opentracing.ErrUnsupportedFormat && err != opentracing.ErrSpanContextNotFound {
@@ -226,7 +243,15 @@ func (c *Connection) extractInboundSpan(callReq *callReq) opentracing.Span {
 // by all tracers is used to deserialize the tracing context from the
 // application headers and start a new server-side span.
 // Once the span is started, it is wrapped in a new Context, which is returned.
+//
+// If OpenTelemetry tracing is enabled, the trace context is extracted from the
+// headers using the channel's propagator, falling back to the frame's tracing
+// field for peers that don't propagate it, and tracer is not used.
 func ExtractInboundSpan(ctx context.Context, call *InboundCall, headers map[string]string, tracer opentracing.Tracer) context.Context {
+	if call.conn != nil && call.conn.otel != nil {
+		return extractInboundOtelSpan(ctx, call, headers)
+	}
+
 	var span = call.Response().span
 	if span != nil {
 		if headers != nil {
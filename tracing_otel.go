@@ -0,0 +1,231 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/context"
+)
+
+// otelInstrumentationName is the name of the OpenTelemetry tracer used by TChannel.
+const otelInstrumentationName = "github.com/temporalio/tchannel-go"
+
+// otelAttrArgScheme is the attribute for the arg scheme of a call.
+const otelAttrArgScheme = attribute.Key("tchannel.as")
+
+// otelTracing holds the OpenTelemetry tracer and propagator for a channel,
+// if OpenTelemetry tracing is enabled.
+type otelTracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func newOtelTracing(tp trace.TracerProvider, propagator propagation.TextMapPropagator) *otelTracing {
+	if tp == nil {
+		return nil
+	}
+	if propagator == nil {
+		propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	return &otelTracing{
+		tracer: tp.Tracer(
+			otelInstrumentationName,
+			trace.WithInstrumentationVersion(VersionInfo),
+			trace.WithSchemaURL(semconv.SchemaURL),
+		),
+		propagator: propagator,
+	}
+}
+
+// Get implements propagation.TextMapCarrier.
+func (c tracingHeadersCarrier) Get(key string) string {
+	return c[tracingKeyEncoding.mapAndCache(key)]
+}
+
+// Keys implements propagation.TextMapCarrier.
+func (c tracingHeadersCarrier) Keys() []string {
+	var keys []string
+	c.ForeachKey(func(key, _ string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	return keys
+}
+
+// spanFromOtel returns the TChannel tracing fields for an OpenTelemetry span
+// context, so peers that only understand the binary tracing fields see the
+// same span IDs. The trace ID is truncated to its lower 64 bits.
+func spanFromOtel(sc trace.SpanContext, parent trace.SpanContext) Span {
+	traceID := sc.TraceID()
+	spanID := sc.SpanID()
+	span := Span{
+		traceID: binary.BigEndian.Uint64(traceID[8:]),
+		spanID:  binary.BigEndian.Uint64(spanID[:]),
+	}
+	if parent.IsValid() {
+		parentID := parent.SpanID()
+		span.parentID = binary.BigEndian.Uint64(parentID[:])
+	}
+	if sc.IsSampled() {
+		span.flags = 1
+	}
+	return span
+}
+
+// otelSpanContext returns a remote OpenTelemetry span context for the binary
+// tracing fields of a call from a peer that does not propagate W3C trace context.
+func otelSpanContext(s Span) trace.SpanContext {
+	if s.traceID == 0 || s.spanID == 0 {
+		return trace.SpanContext{}
+	}
+
+	var (
+		traceID trace.TraceID
+		spanID  trace.SpanID
+		flags   trace.TraceFlags
+	)
+	binary.BigEndian.PutUint64(traceID[8:], s.traceID)
+	binary.BigEndian.PutUint64(spanID[:], s.spanID)
+	if s.flags&1 == 1 {
+		flags = trace.FlagsSampled
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	})
+}
+
+// otelPeerAttributes returns the network attributes for the remote peer.
+func (c *Connection) otelPeerAttributes() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if c.remotePeerAddress.ipv4 != 0 {
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, c.remotePeerAddress.ipv4)
+		attrs = append(attrs, semconv.NetPeerIPKey.String(ip.String()))
+	}
+	if c.remotePeerAddress.ipv6 != "" {
+		attrs = append(attrs, semconv.NetPeerIPKey.String(c.remotePeerAddress.ipv6))
+	}
+	if c.remotePeerAddress.hostname != "" {
+		attrs = append(attrs, semconv.NetPeerNameKey.String(c.remotePeerAddress.hostname))
+	}
+	if c.remotePeerAddress.port != 0 {
+		attrs = append(attrs, semconv.NetPeerPortKey.Int(int(c.remotePeerAddress.port)))
+	}
+	return attrs
+}
+
+// startOutboundOtelSpan creates an OpenTelemetry client span for the outbound
+// call, and sets the call's binary tracing fields to match it. It returns the
+// span, and a context containing the span used to inject application headers.
+func (c *Connection) startOutboundOtelSpan(ctx context.Context, serviceName, methodName string, call *OutboundCall, startTime time.Time) (context.Context, trace.Span) {
+	attrs := append([]attribute.KeyValue{
+		semconv.RPCSystemKey.String("tchannel"),
+		semconv.RPCServiceKey.String(serviceName),
+		semconv.RPCMethodKey.String(methodName),
+		semconv.PeerServiceKey.String(serviceName),
+		otelAttrArgScheme.String(call.callReq.Headers[ArgScheme]),
+	}, c.otelPeerAttributes()...)
+
+	spanCtx, span := c.otel.tracer.Start(ctx, methodName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(startTime),
+		trace.WithAttributes(attrs...),
+	)
+	call.callReq.Tracing = spanFromOtel(span.SpanContext(), trace.SpanContextFromContext(ctx))
+	return spanCtx, span
+}
+
+// startInboundOtelSpan creates an OpenTelemetry server span for the inbound
+// call. The parent is taken from ctx if it has a remote span context, and from
+// the call's binary tracing fields otherwise.
+func (c *Connection) startInboundOtelSpan(ctx context.Context, call *InboundCall, methodName string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		if parent := otelSpanContext(call.tracing); parent.IsValid() {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+		}
+	}
+
+	attrs := append([]attribute.KeyValue{
+		semconv.RPCSystemKey.String("tchannel"),
+		semconv.RPCServiceKey.String(call.ServiceName()),
+		semconv.PeerServiceKey.String(call.CallerName()),
+		otelAttrArgScheme.String(string(call.Format())),
+	}, c.otelPeerAttributes()...)
+	if methodName != "" {
+		attrs = append(attrs, semconv.RPCMethodKey.String(methodName))
+	}
+
+	return c.otel.tracer.Start(ctx, methodName,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithTimestamp(call.response.calledAt),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// extractInboundOtelSpan implements ExtractInboundSpan for OpenTelemetry.
+func extractInboundOtelSpan(ctx context.Context, call *InboundCall, headers map[string]string) context.Context {
+	response := call.Response()
+	if headers != nil {
+		carrier := tracingHeadersCarrier(headers)
+		if response.otelSpan == nil {
+			ctx = call.conn.otel.propagator.Extract(ctx, carrier)
+		}
+		carrier.RemoveTracingKeys()
+	}
+
+	if response.otelSpan == nil {
+		ctx, response.otelSpan = call.conn.startInboundOtelSpan(ctx, call, call.MethodString())
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, response.otelSpan)
+}
+
+// usesApplicationHeaders returns whether the format carries application
+// headers that are read by ExtractInboundSpan.
+func usesApplicationHeaders(format Format) bool {
+	return format == Thrift || format == JSON
+}
+
+// endOtelSpan ends the span, marking it as failed if err is set.
+func endOtelSpan(span trace.Span, failed bool, err error, now time.Time) {
+	if err != nil {
+		span.RecordError(err, trace.WithTimestamp(now))
+	}
+	if failed {
+		desc := ""
+		if err != nil {
+			desc = err.Error()
+		}
+		span.SetStatus(codes.Error, desc)
+	}
+	span.End(trace.WithTimestamp(now))
+}
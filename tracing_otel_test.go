@@ -0,0 +1,230 @@
+// Copyright (c) 2015 Uber Technologies, Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tchannel_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/temporalio/tchannel-go"
+
+	"github.com/temporalio/tchannel-go/json"
+	"github.com/temporalio/tchannel-go/raw"
+	"github.com/temporalio/tchannel-go/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/context"
+)
+
+func newOtelTracerProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+// waitForOtelSpans waits for n spans to end, as server spans are ended
+// asynchronously with the response.
+func waitForOtelSpans(t testing.TB, exporter *tracetest.InMemoryExporter, n int) tracetest.SpanStubs {
+	var spans tracetest.SpanStubs
+	require.True(t, testutils.WaitFor(time.Second, func() bool {
+		spans = exporter.GetSpans()
+		return len(spans) >= n
+	}), "expected %v spans, got %v", n, len(spans))
+	return spans
+}
+
+func findOtelSpan(spans tracetest.SpanStubs, kind trace.SpanKind) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].SpanKind == kind {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func otelAttributes(s *tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range s.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestOtelRawCall(t *testing.T) {
+	tp, exporter := newOtelTracerProvider()
+	opts := testutils.NewOpts().NoRelay()
+	opts.TracerProvider = tp
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		var handlerSpan Span
+		testutils.RegisterFunc(ts.Server(), "echo", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			handlerSpan = *CurrentSpan(ctx)
+			return &raw.Res{Arg2: args.Arg2, Arg3: args.Arg3}, nil
+		})
+		testutils.RegisterFunc(ts.Server(), "app-error", func(ctx context.Context, args *raw.Args) (*raw.Res, error) {
+			return &raw.Res{IsErr: true}, nil
+		})
+
+		clientOpts := testutils.NewOpts()
+		clientOpts.TracerProvider = tp
+		client := ts.NewClient(clientOpts)
+
+		ctx, cancel := NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+		require.NoError(t, err, "echo failed")
+
+		spans := waitForOtelSpans(t, exporter, 2)
+		clientSpan := findOtelSpan(spans, trace.SpanKindClient)
+		serverSpan := findOtelSpan(spans, trace.SpanKindServer)
+		require.NotNil(t, clientSpan, "missing client span")
+		require.NotNil(t, serverSpan, "missing server span")
+
+		// Raw calls only carry the binary tracing fields, which hold the low 64 bits of the trace ID.
+		assert.Equal(t, traceIDToUint64(clientSpan.SpanContext.TraceID()), traceIDToUint64(serverSpan.SpanContext.TraceID()), "trace ID mismatch")
+		assert.Equal(t, clientSpan.SpanContext.SpanID(), serverSpan.Parent.SpanID(), "server span should be a child of the client span")
+		assert.Equal(t, "echo", serverSpan.Name, "unexpected server span name")
+
+		clientAttrs := otelAttributes(clientSpan)
+		assert.Equal(t, "tchannel", clientAttrs["rpc.system"].AsString(), "unexpected rpc.system")
+		assert.Equal(t, ts.ServiceName(), clientAttrs["rpc.service"].AsString(), "unexpected rpc.service")
+		assert.Equal(t, "echo", clientAttrs["rpc.method"].AsString(), "unexpected rpc.method")
+		serverAttrs := otelAttributes(serverSpan)
+		assert.Equal(t, client.ServiceName(), serverAttrs["peer.service"].AsString(), "unexpected peer.service")
+		assert.Equal(t, "echo", serverAttrs["rpc.method"].AsString(), "unexpected rpc.method")
+
+		// The handler sees the server span's IDs in the binary tracing format.
+		serverSC := serverSpan.SpanContext
+		assert.Equal(t, handlerSpan.SpanID(), spanIDToUint64(serverSC.SpanID()), "unexpected handler span ID")
+		assert.Equal(t, handlerSpan.TraceID(), traceIDToUint64(serverSC.TraceID()), "unexpected handler trace ID")
+
+		exporter.Reset()
+		_, _, resp, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "app-error", nil, nil)
+		require.NoError(t, err, "app-error failed")
+		require.True(t, resp.ApplicationError(), "expected application error")
+
+		spans = waitForOtelSpans(t, exporter, 2)
+		for _, s := range spans {
+			assert.Equal(t, codes.Error, s.Status.Code, "%v span should have an error status", s.SpanKind)
+		}
+	})
+}
+
+func TestOtelJSONPropagation(t *testing.T) {
+	tp, exporter := newOtelTracerProvider()
+	opts := testutils.NewOpts()
+	opts.TracerProvider = tp
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		exporter.Reset()
+
+		var gotHeaders map[string]string
+		var gotSpanContext trace.SpanContext
+		require.NoError(t, json.Register(ts.Server(), json.Handlers{
+			"echo": func(ctx json.Context, arg map[string]string) (map[string]string, error) {
+				gotHeaders = ctx.Headers()
+				gotSpanContext = trace.SpanContextFromContext(ctx)
+				return arg, nil
+			},
+		}, nil), "Register failed")
+
+		clientOpts := testutils.NewOpts()
+		clientOpts.TracerProvider = tp
+		client := ts.NewClient(clientOpts)
+
+		// Start a parent span, so the full 128-bit trace ID is propagated.
+		parentCtx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+		defer parent.End()
+
+		ctx, cancel := context.WithTimeout(parentCtx, testutils.Timeout(time.Second))
+		defer cancel()
+
+		var resp map[string]string
+		jctx := json.WithHeaders(ctx, map[string]string{"app": "header"})
+		require.NoError(t, json.NewClient(client, ts.ServiceName(), &json.ClientOptions{
+			HostPort: ts.HostPort(),
+		}).Call(jctx, "echo", map[string]string{}, &resp), "json call failed")
+
+		spans := waitForOtelSpans(t, exporter, 2)
+		clientSpan := findOtelSpan(spans, trace.SpanKindClient)
+		serverSpan := findOtelSpan(spans, trace.SpanKindServer)
+		require.NotNil(t, clientSpan, "missing client span")
+		require.NotNil(t, serverSpan, "missing server span")
+
+		assert.Equal(t, parent.SpanContext().TraceID(), serverSpan.SpanContext.TraceID(), "full trace ID should be propagated")
+		assert.Equal(t, clientSpan.SpanContext.SpanID(), serverSpan.Parent.SpanID(), "server span should be a child of the client span")
+		assert.Equal(t, serverSpan.SpanContext, gotSpanContext, "handler context should contain the server span")
+		assert.Equal(t, map[string]string{"app": "header"}, gotHeaders, "tracing headers should not be visible to handlers")
+	})
+}
+
+func TestOtelLegacyPeer(t *testing.T) {
+	tp, exporter := newOtelTracerProvider()
+	opts := testutils.NewOpts()
+	opts.TracerProvider = tp
+	testutils.WithTestServer(t, opts, func(t testing.TB, ts *testutils.TestServer) {
+		exporter.Reset()
+		testutils.RegisterEcho(ts.Server(), nil)
+
+		// The client uses OpenTracing, and only sets the binary tracing fields.
+		reporter := jaeger.NewInMemoryReporter()
+		tracer, closer := jaeger.NewTracer("client", jaeger.NewConstSampler(true), reporter)
+		defer closer.Close()
+		clientOpts := testutils.NewOpts()
+		clientOpts.Tracer = tracer
+		client := ts.NewClient(clientOpts)
+
+		ctx, cancel := NewContext(testutils.Timeout(time.Second))
+		defer cancel()
+		_, _, _, err := raw.Call(ctx, client, ts.HostPort(), ts.ServiceName(), "echo", nil, nil)
+		require.NoError(t, err, "echo failed")
+
+		serverSpan := findOtelSpan(waitForOtelSpans(t, exporter, 1), trace.SpanKindServer)
+		require.NotNil(t, serverSpan, "missing server span")
+		require.Len(t, reporter.GetSpans(), 1, "missing client span")
+		clientSpan := reporter.GetSpans()[0].(*jaeger.Span)
+
+		assert.Equal(t, clientSpan.SpanContext().TraceID().Low, traceIDToUint64(serverSpan.SpanContext.TraceID()),
+			"trace ID should be taken from the binary tracing fields")
+		assert.Equal(t, uint64(clientSpan.SpanContext().SpanID()), spanIDToUint64(serverSpan.Parent.SpanID()),
+			"server span should be a child of the client span")
+	})
+}
+
+func traceIDToUint64(id trace.TraceID) uint64 {
+	var v uint64
+	for _, b := range id[8:] {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+func spanIDToUint64(id trace.SpanID) uint64 {
+	var v uint64
+	for _, b := range id {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}